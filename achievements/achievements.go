@@ -0,0 +1,205 @@
+// Package achievements unlocks achievements defined in local files. Their
+// conditions, in the rcheevos format, are tested against the memory of the
+// core every frame. No online service is involved.
+package achievements
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+	"unsafe"
+
+	"github.com/libretro/ludo/libretro"
+	ntf "github.com/libretro/ludo/notifications"
+	"github.com/libretro/ludo/settings"
+	"github.com/libretro/ludo/state"
+
+	"github.com/libretro/ludo/l10n"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// Achievement is an achievement of the definition file of a game
+type Achievement struct {
+	ID          int
+	Title       string
+	Description string
+	Points      int
+	MemAddr     string // conditions in the rcheevos format
+
+	Unlocked time.Time `json:"-"` // zero while locked
+	trigger  *Trigger
+}
+
+// definitions is the content of a definition file. It follows the patch data
+// format of RetroAchievements, optionally wrapped in a PatchData object.
+type definitions struct {
+	Title        string
+	Achievements []*Achievement
+	PatchData    *definitions
+}
+
+var (
+	hash  string // MD5 of the content, names the files of the game
+	title string
+	list  []*Achievement
+)
+
+// List returns the achievements of the current game
+func List() []*Achievement {
+	return list
+}
+
+// Title returns the name of the game as found in the definition file
+func Title() string {
+	return title
+}
+
+// Hash returns the MD5 of a content file, used to find its definitions
+func Hash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func definitionsPath() string {
+	return filepath.Join(settings.Current.AchievementsDirectory, hash+".json")
+}
+
+func unlocksPath() string {
+	return filepath.Join(settings.Current.AchievementsDirectory, hash+".unlocks.json")
+}
+
+// Load reads the achievements of the current game and their unlock dates
+func Load() error {
+	Unload()
+	if state.GamePath == "" {
+		return nil
+	}
+
+	var err error
+	if hash, err = Hash(state.GamePath); err != nil {
+		return err
+	}
+
+	b, err := os.ReadFile(definitionsPath())
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var defs definitions
+	if err := json.Unmarshal(b, &defs); err != nil {
+		return err
+	}
+	if defs.PatchData != nil {
+		defs = *defs.PatchData
+	}
+
+	unlocks := map[int]time.Time{}
+	if b, err := os.ReadFile(unlocksPath()); err == nil {
+		if err := json.Unmarshal(b, &unlocks); err != nil {
+			return err
+		}
+	}
+
+	for _, a := range defs.Achievements {
+		a.Unlocked = unlocks[a.ID]
+		if a.trigger, err = ParseTrigger(a.MemAddr); err != nil {
+			log.Printf("[Achievements]: Skipping %q: %v\n", a.Title, err)
+		}
+	}
+	title = defs.Title
+	list = defs.Achievements
+
+	if !state.Core.SupportsAchievements && len(state.Core.MemoryMap) == 0 &&
+		state.Core.GetMemorySize(libretro.MemorySystemRAM) == 0 {
+		log.Println("[Achievements]: The core doesn't expose its memory")
+	}
+
+	log.Printf("[Achievements]: Loaded %d achievements for %s\n", len(list), hash)
+	return nil
+}
+
+// Unload forgets the achievements of the current game
+func Unload() {
+	hash = ""
+	title = ""
+	list = nil
+}
+
+// save writes the unlock dates of the current game
+func save() error {
+	unlocks := map[int]time.Time{}
+	for _, a := range list {
+		if !a.Unlocked.IsZero() {
+			unlocks[a.ID] = a.Unlocked
+		}
+	}
+	b, err := json.MarshalIndent(unlocks, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(settings.Current.AchievementsDirectory, os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(unlocksPath(), b, 0644)
+}
+
+// memory returns a reader over the system RAM of the core, completed by the
+// memory map for the addresses past the end of the RAM
+func memory() Memory {
+	var ram []byte
+	if ptr := state.Core.GetMemoryData(libretro.MemorySystemRAM); ptr != nil {
+		ram = unsafe.Slice((*byte)(ptr), state.Core.GetMemorySize(libretro.MemorySystemRAM))
+	}
+	mapped := len(state.Core.MemoryMap) > 0
+	return func(addr uint32) byte {
+		if int(addr) < len(ram) {
+			return ram[addr]
+		}
+		if mapped {
+			if ptr := state.Core.MemoryPointer(uintptr(addr)); ptr != nil {
+				return *(*byte)(ptr)
+			}
+		}
+		return 0
+	}
+}
+
+// Frame tests the locked achievements against the memory of the current
+// frame. It should be called once per emulated frame.
+func Frame() {
+	if len(list) == 0 || state.Core == nil {
+		return
+	}
+	mem := memory()
+	for _, a := range list {
+		if a.trigger == nil || !a.Unlocked.IsZero() {
+			continue
+		}
+		if a.trigger.Test(mem) {
+			unlock(a)
+		}
+	}
+}
+
+func unlock(a *Achievement) {
+	a.Unlocked = time.Now()
+	txtI18n := l10n.T9(&i18n.Message{ID: "AchievementUnlocked", Other: "Achievement unlocked: %s"})
+	ntf.DisplayAndLog(ntf.Success, "Achievements", txtI18n, a.Title)
+	if err := save(); err != nil {
+		ntf.DisplayAndLog(ntf.Error, "Achievements", err.Error())
+	}
+}
@@ -0,0 +1,79 @@
+package achievements
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"unsafe"
+
+	"github.com/libretro/ludo/libretro"
+	"github.com/libretro/ludo/settings"
+	"github.com/libretro/ludo/state"
+	"github.com/libretro/ludo/utils"
+)
+
+func TestLoad(t *testing.T) {
+	settings.Current.AchievementsDirectory = t.TempDir()
+
+	game := "../core/testdata/Polar Rescue (USA).vec"
+	h, err := Hash(game)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defs := `{"PatchData": {"Title": "Polar Rescue", "Achievements": [
+		{"ID": 1, "Title": "Second frame", "Points": 5, "MemAddr": "1=1.2."},
+		{"ID": 2, "Title": "Never", "Points": 10, "MemAddr": "1=2"},
+		{"ID": 3, "Title": "Broken", "Points": 10, "MemAddr": "I:0xH0000=1"}
+	]}}`
+	os.WriteFile(filepath.Join(settings.Current.AchievementsDirectory, h+".json"), []byte(defs), 0644)
+
+	c, err := libretro.Load("../core/testdata/vecx_libretro" + utils.CoreExt())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetEnvironment(func(uint32, unsafe.Pointer) bool { return false })
+	c.Init()
+	state.Core = c
+	state.GamePath = game
+	utils.CaptureOutput(func() { Load() })
+
+	t.Run("Loads the definitions of the game", func(t *testing.T) {
+		if Title() != "Polar Rescue" || len(List()) != 3 {
+			t.Errorf("got = %v %v, want %v %v", Title(), len(List()), "Polar Rescue", 3)
+		}
+	})
+
+	utils.CaptureOutput(func() {
+		for i := 0; i < 3; i++ {
+			Frame()
+		}
+	})
+
+	t.Run("Unlocks achievements", func(t *testing.T) {
+		if List()[0].Unlocked.IsZero() {
+			t.Errorf("got = %v, want unlocked", List()[0].Unlocked)
+		}
+		if !List()[1].Unlocked.IsZero() {
+			t.Errorf("got = %v, want locked", List()[1].Unlocked)
+		}
+	})
+
+	t.Run("Persists the unlocks", func(t *testing.T) {
+		unlocked := List()[0].Unlocked
+		utils.CaptureOutput(func() { Load() })
+		if !List()[0].Unlocked.Equal(unlocked) {
+			t.Errorf("got = %v, want %v", List()[0].Unlocked, unlocked)
+		}
+	})
+
+	Unload()
+	c.Deinit()
+	state.Core = nil
+	state.GamePath = ""
+
+	t.Run("Forgets the achievements", func(t *testing.T) {
+		if len(List()) != 0 {
+			t.Errorf("got = %v, want %v", len(List()), 0)
+		}
+	})
+}
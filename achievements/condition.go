@@ -0,0 +1,535 @@
+package achievements
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// Memory reads a byte of the emulated memory, unmapped addresses read as 0
+type Memory func(addr uint32) byte
+
+// Operand sizes, named after the rcheevos size prefixes
+const (
+	size8 = iota
+	size16
+	size24
+	size32
+	size16BE
+	size24BE
+	size32BE
+	sizeBit0 // sizeBit0 to sizeBit7 read a single bit
+	sizeBit1
+	sizeBit2
+	sizeBit3
+	sizeBit4
+	sizeBit5
+	sizeBit6
+	sizeBit7
+	sizeLower4
+	sizeUpper4
+	sizeBitCount
+)
+
+var sizes = map[byte]int{
+	'H': size8, 'W': size24, 'X': size32, 'I': size16BE, 'J': size24BE, 'G': size32BE,
+	'M': sizeBit0, 'N': sizeBit1, 'O': sizeBit2, 'P': sizeBit3,
+	'Q': sizeBit4, 'R': sizeBit5, 'S': sizeBit6, 'T': sizeBit7,
+	'L': sizeLower4, 'U': sizeUpper4, 'K': sizeBitCount,
+}
+
+// Operand kinds
+const (
+	kindConst = iota
+	kindValue // value of the current frame
+	kindDelta // value of the previous frame
+	kindPrior // value before the last change
+	kindBCD   // value of the current frame decoded from BCD
+	kindInvert
+)
+
+type operand struct {
+	kind  int
+	size  int
+	addr  uint32
+	value uint32 // constant, or value of the current frame
+
+	delta   uint32
+	prior   uint32
+	started bool
+}
+
+func (o *operand) read(mem Memory) uint32 {
+	a := o.addr
+	switch o.size {
+	case size8:
+		return uint32(mem(a))
+	case size16:
+		return uint32(mem(a)) | uint32(mem(a+1))<<8
+	case size24:
+		return uint32(mem(a)) | uint32(mem(a+1))<<8 | uint32(mem(a+2))<<16
+	case size32:
+		return uint32(mem(a)) | uint32(mem(a+1))<<8 | uint32(mem(a+2))<<16 | uint32(mem(a+3))<<24
+	case size16BE:
+		return uint32(mem(a))<<8 | uint32(mem(a+1))
+	case size24BE:
+		return uint32(mem(a))<<16 | uint32(mem(a+1))<<8 | uint32(mem(a+2))
+	case size32BE:
+		return uint32(mem(a))<<24 | uint32(mem(a+1))<<16 | uint32(mem(a+2))<<8 | uint32(mem(a+3))
+	case sizeLower4:
+		return uint32(mem(a) & 0xf)
+	case sizeUpper4:
+		return uint32(mem(a) >> 4)
+	case sizeBitCount:
+		return uint32(bits.OnesCount8(mem(a)))
+	}
+	return uint32(mem(a)>>(o.size-sizeBit0)) & 1
+}
+
+// update reads the memory once per frame, and keeps the delta and prior
+// values up to date
+func (o *operand) update(mem Memory) {
+	if o.kind == kindConst {
+		return
+	}
+	v := o.read(mem)
+	if !o.started {
+		o.value, o.delta, o.prior = v, v, v
+		o.started = true
+		return
+	}
+	o.delta = o.value
+	if v != o.value {
+		o.prior = o.value
+	}
+	o.value = v
+}
+
+// get returns the value of the operand for the current frame
+func (o *operand) get() uint32 {
+	switch o.kind {
+	case kindDelta:
+		return o.delta
+	case kindPrior:
+		return o.prior
+	case kindBCD:
+		v, r, m := o.value, uint32(0), uint32(1)
+		for v > 0 {
+			r += (v & 0xf) * m
+			v >>= 4
+			m *= 10
+		}
+		return r
+	case kindInvert:
+		switch o.size {
+		case size8:
+			return ^o.value & 0xff
+		case size16, size16BE:
+			return ^o.value & 0xffff
+		case size24, size24BE:
+			return ^o.value & 0xffffff
+		case size32, size32BE:
+			return ^o.value
+		case sizeLower4, sizeUpper4:
+			return ^o.value & 0xf
+		}
+		return ^o.value & 1
+	}
+	return o.value
+}
+
+// Condition flags
+const (
+	flagNone = iota
+	flagResetIf
+	flagPauseIf
+	flagAddSource
+	flagSubSource
+	flagAddHits
+	flagAndNext
+	flagOrNext
+)
+
+// Measured, MeasuredIf and Trigger conditions only matter for the progress
+// display of rcheevos, they behave as regular conditions here
+var flags = map[byte]int{
+	'R': flagResetIf, 'P': flagPauseIf, 'A': flagAddSource, 'B': flagSubSource,
+	'C': flagAddHits, 'N': flagAndNext, 'O': flagOrNext,
+	'M': flagNone, 'Q': flagNone, 'T': flagNone,
+}
+
+type condition struct {
+	flag   int
+	left   *operand
+	op     string
+	right  *operand // nil for the conditions without an operator
+	target uint32   // required hits, 0 if none
+	hits   uint32
+}
+
+// modifier returns the value of an AddSource or SubSource condition
+func (c *condition) modifier() uint32 {
+	l := c.left.get()
+	if c.right == nil {
+		return l
+	}
+	r := c.right.get()
+	switch c.op {
+	case "*":
+		return l * r
+	case "/":
+		if r == 0 {
+			return 0
+		}
+		return l / r
+	case "&":
+		return l & r
+	case "^":
+		return l ^ r
+	}
+	return l
+}
+
+func compare(l uint32, op string, r uint32) bool {
+	switch op {
+	case "=":
+		return l == r
+	case "!=":
+		return l != r
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	case ">=":
+		return l >= r
+	}
+	return false
+}
+
+// group is a list of conditions, the core group or one of the alternatives
+type group struct {
+	conditions []*condition
+}
+
+// eval tests the conditions of a group for one frame. It returns whether the
+// group is true, and whether a ResetIf condition was hit.
+func (g *group) eval() (bool, bool) {
+	// PauseIf conditions are tested first, a paused group doesn't count hits
+	if g.paused() {
+		return false, false
+	}
+
+	result := true
+	reset := false
+	var add uint32
+	var addHits uint32
+	chain, orChain := true, false
+	for _, c := range g.conditions {
+		switch c.flag {
+		case flagPauseIf:
+			add, addHits, chain, orChain = 0, 0, true, false
+			continue
+		case flagAddSource:
+			add += c.modifier()
+			continue
+		case flagSubSource:
+			add -= c.modifier()
+			continue
+		}
+
+		ok := c.right == nil || compare(c.left.get()+add, c.op, c.right.get())
+		add = 0
+		ok = (ok || orChain) && chain
+		chain, orChain = true, false
+		if ok && (c.target == 0 || c.hits < c.target) {
+			c.hits++
+		}
+
+		switch c.flag {
+		case flagAndNext:
+			chain = ok
+			continue
+		case flagOrNext:
+			orChain = ok
+			continue
+		case flagAddHits:
+			addHits += c.hits
+			continue
+		}
+
+		if c.target > 0 {
+			ok = c.hits+addHits >= c.target
+		}
+		addHits = 0
+
+		if c.flag == flagResetIf {
+			reset = reset || ok
+		} else {
+			result = result && ok
+		}
+	}
+	return result, reset
+}
+
+// paused tells whether one of the PauseIf conditions of the group is true
+func (g *group) paused() bool {
+	paused := false
+	var add uint32
+	chain, orChain := true, false
+	for _, c := range g.conditions {
+		switch c.flag {
+		case flagAddSource:
+			add += c.modifier()
+			continue
+		case flagSubSource:
+			add -= c.modifier()
+			continue
+		}
+		ok := c.right == nil || compare(c.left.get()+add, c.op, c.right.get())
+		add = 0
+		ok = (ok || orChain) && chain
+		chain, orChain = true, false
+		switch c.flag {
+		case flagAndNext:
+			chain = ok
+		case flagOrNext:
+			orChain = ok
+		case flagPauseIf:
+			if c.target > 0 {
+				if ok && c.hits < c.target {
+					c.hits++
+				}
+				ok = c.hits >= c.target
+			}
+			paused = paused || ok
+		}
+	}
+	return paused
+}
+
+func (g *group) resetHits() {
+	for _, c := range g.conditions {
+		c.hits = 0
+	}
+}
+
+// Trigger is the parsed condition of an achievement, written in the rcheevos
+// format, like 0xH0010=5_d0xH0011<0xH0011.1.S0xH0012=1S0xH0013=1
+type Trigger struct {
+	core     group
+	alts     []group
+	operands []*operand
+
+	primed bool // the trigger has been false at least once
+}
+
+// Test updates the trigger with the memory of the current frame and returns
+// true if the achievement should be unlocked. A trigger has to be false once
+// before it can fire, so achievements aren't unlocked by loading a state where
+// they are already true.
+func (t *Trigger) Test(mem Memory) bool {
+	for _, o := range t.operands {
+		o.update(mem)
+	}
+
+	ok, reset := t.core.eval()
+	alts := len(t.alts) == 0
+	for i := range t.alts {
+		a, r := t.alts[i].eval()
+		alts = alts || a
+		reset = reset || r
+	}
+	if reset {
+		t.Reset()
+		ok = false
+	}
+	ok = ok && alts
+
+	if !t.primed {
+		t.primed = !ok
+		return false
+	}
+	return ok
+}
+
+// Reset clears the hit counts
+func (t *Trigger) Reset() {
+	t.core.resetHits()
+	for i := range t.alts {
+		t.alts[i].resetHits()
+	}
+}
+
+// parser reads a trigger string
+type parser struct {
+	s string
+	i int
+	t *Trigger
+}
+
+func (p *parser) peek() byte {
+	if p.i < len(p.s) {
+		return p.s[p.i]
+	}
+	return 0
+}
+
+// ParseTrigger parses a trigger in the rcheevos format
+func ParseTrigger(s string) (*Trigger, error) {
+	p := &parser{s: s, t: &Trigger{}}
+	core, err := p.group()
+	if err != nil {
+		return nil, err
+	}
+	p.t.core = core
+	for p.peek() == 'S' {
+		p.i++
+		alt, err := p.group()
+		if err != nil {
+			return nil, err
+		}
+		p.t.alts = append(p.t.alts, alt)
+	}
+	if p.i != len(p.s) {
+		return nil, fmt.Errorf("unexpected %q at %d", p.s[p.i:], p.i)
+	}
+	return p.t, nil
+}
+
+func (p *parser) group() (group, error) {
+	var g group
+	// An empty core group is allowed when there are alternatives
+	if p.peek() == 'S' || p.peek() == 0 {
+		return g, nil
+	}
+	for {
+		c, err := p.condition()
+		if err != nil {
+			return g, err
+		}
+		g.conditions = append(g.conditions, c)
+		if p.peek() != '_' {
+			return g, nil
+		}
+		p.i++
+	}
+}
+
+func (p *parser) condition() (*condition, error) {
+	c := &condition{}
+	if p.i+1 < len(p.s) && p.s[p.i+1] == ':' {
+		f, ok := flags[p.s[p.i]]
+		if !ok {
+			return nil, fmt.Errorf("unsupported flag %q", p.s[p.i])
+		}
+		c.flag = f
+		p.i += 2
+	}
+
+	var err error
+	if c.left, err = p.operand(); err != nil {
+		return nil, err
+	}
+
+	for _, op := range []string{"!=", "<=", ">=", "=", "<", ">", "*", "/", "&", "^"} {
+		if strings.HasPrefix(p.s[p.i:], op) {
+			c.op = op
+			p.i += len(op)
+			if c.right, err = p.operand(); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	modifier := c.flag == flagAddSource || c.flag == flagSubSource
+	if c.right == nil && !modifier {
+		return nil, errors.New("missing comparison")
+	}
+	if c.right != nil && modifier != strings.Contains("*/&^", c.op) {
+		return nil, fmt.Errorf("unexpected operator %q", c.op)
+	}
+
+	// Hit target, written .N. or (N)
+	if end := map[byte]byte{'.': '.', '(': ')'}[p.peek()]; end != 0 {
+		j := strings.IndexByte(p.s[p.i+1:], end)
+		if j < 0 {
+			return nil, errors.New("unterminated hit target")
+		}
+		n, err := strconv.ParseUint(p.s[p.i+1:p.i+1+j], 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		c.target = uint32(n)
+		p.i += j + 2
+	}
+	return c, nil
+}
+
+func (p *parser) operand() (*operand, error) {
+	o := &operand{kind: kindValue}
+	switch p.peek() {
+	case 'd':
+		o.kind = kindDelta
+		p.i++
+	case 'p':
+		o.kind = kindPrior
+		p.i++
+	case 'b':
+		o.kind = kindBCD
+		p.i++
+	case '~':
+		o.kind = kindInvert
+		p.i++
+	}
+
+	rest := p.s[p.i:]
+	if strings.HasPrefix(rest, "0x") || strings.HasPrefix(rest, "0X") {
+		p.i += 2
+		o.size = size16
+		if c := p.peek(); c == ' ' {
+			p.i++
+		} else if s, ok := sizes[c&^0x20]; ok && !isHex(c) {
+			o.size = s
+			p.i++
+		}
+		start := p.i
+		for isHex(p.peek()) {
+			p.i++
+		}
+		addr, err := strconv.ParseUint(p.s[start:p.i], 16, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address at %d", start)
+		}
+		o.addr = uint32(addr)
+		p.t.operands = append(p.t.operands, o)
+		return o, nil
+	}
+
+	if o.kind != kindValue {
+		return nil, fmt.Errorf("invalid operand at %d", p.i)
+	}
+	o.kind = kindConst
+	base := 10
+	if c := p.peek(); c == 'h' || c == 'H' {
+		base = 16
+		p.i++
+	}
+	start := p.i
+	for isHex(p.peek()) && (base == 16 || p.peek() <= '9') {
+		p.i++
+	}
+	v, err := strconv.ParseUint(p.s[start:p.i], base, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value at %d", start)
+	}
+	o.value = uint32(v)
+	return o, nil
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
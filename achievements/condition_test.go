@@ -0,0 +1,137 @@
+package achievements
+
+import (
+	"testing"
+)
+
+// run tests a trigger against successive memory states, and returns the
+// result of each frame
+func run(t *testing.T, s string, frames ...[]byte) []bool {
+	t.Helper()
+	trigger, err := ParseTrigger(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []bool
+	for _, ram := range frames {
+		ram := ram
+		results = append(results, trigger.Test(func(addr uint32) byte {
+			if int(addr) < len(ram) {
+				return ram[addr]
+			}
+			return 0
+		}))
+	}
+	return results
+}
+
+func equal(a, b []bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTrigger(t *testing.T) {
+	tests := []struct {
+		name    string
+		trigger string
+		frames  [][]byte
+		want    []bool
+	}{
+		{
+			name:    "Fires after being false once",
+			trigger: "0xH0000=5",
+			frames:  [][]byte{{5}, {4}, {5}},
+			want:    []bool{false, false, true},
+		},
+		{
+			name:    "Reads 16-bit values",
+			trigger: "0x0000=h0102_0x 0000=258",
+			frames:  [][]byte{{0, 0}, {2, 1}},
+			want:    []bool{false, true},
+		},
+		{
+			name:    "Reads big endian values and bits",
+			trigger: "0xI0000=h0102_0xM0002=1_0xS0002=0",
+			frames:  [][]byte{{0, 0, 0}, {1, 2, 1}},
+			want:    []bool{false, true},
+		},
+		{
+			name:    "Compares with the previous frame",
+			trigger: "0xH0000>d0xH0000",
+			frames:  [][]byte{{1}, {2}, {2}, {3}},
+			want:    []bool{false, true, false, true},
+		},
+		{
+			name:    "Counts hits",
+			trigger: "0xH0000=1.2.",
+			frames:  [][]byte{{1}, {0}, {1}, {0}, {0}},
+			want:    []bool{false, false, true, true, true},
+		},
+		{
+			name:    "Resets hits",
+			trigger: "0xH0000=1(2)_R:0xH0001=1",
+			frames:  [][]byte{{0, 0}, {1, 0}, {0, 1}, {1, 0}, {1, 0}},
+			want:    []bool{false, false, false, false, true},
+		},
+		{
+			name:    "Pauses",
+			trigger: "0xH0000=1.2._P:0xH0001=1",
+			frames:  [][]byte{{0, 0}, {1, 1}, {1, 0}, {0, 0}, {1, 0}},
+			want:    []bool{false, false, false, false, true},
+		},
+		{
+			name:    "Adds sources",
+			trigger: "A:0xH0000_A:0xH0001*2_0xH0002=10",
+			frames:  [][]byte{{0, 0, 0}, {2, 3, 2}},
+			want:    []bool{false, true},
+		},
+		{
+			name:    "Needs one alternative",
+			trigger: "0xH0000=1S0xH0001=1S0xH0002=1",
+			frames:  [][]byte{{1, 0, 0}, {1, 0, 1}, {0, 1, 0}},
+			want:    []bool{false, true, false},
+		},
+		{
+			name:    "Decodes BCD",
+			trigger: "b0xH0000=42",
+			frames:  [][]byte{{0}, {0x42}},
+			want:    []bool{false, true},
+		},
+		{
+			name:    "Chains with AndNext",
+			trigger: "N:0xH0000=1_0xH0001=1.2.",
+			frames:  [][]byte{{0, 0}, {0, 1}, {1, 1}, {1, 1}},
+			want:    []bool{false, false, false, true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := run(t, tt.trigger, tt.frames...); !equal(got, tt.want) {
+				t.Errorf("got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrigger(t *testing.T) {
+	invalid := []string{
+		"0xH0000",
+		"0xH0000=",
+		"I:0xH0000_0xH0001=1",
+		"0xH0000=1.2",
+		"0xH0000=1_",
+		"A:0xH0000=1_0xH0001=1",
+	}
+	for _, s := range invalid {
+		if _, err := ParseTrigger(s); err == nil {
+			t.Errorf("%q got = %v, want an error", s, err)
+		}
+	}
+}
@@ -112,7 +112,7 @@ func fillInternalBuf(buf []byte) int32 {
 func write(buf []byte, size int32) int32 {
 	written := int32(0)
 
-	if state.FastForward {
+	if state.FastForward || state.Rewinding {
 		return size
 	}
 
@@ -0,0 +1,253 @@
+// Package cheats loads RetroArch .cht files and applies the enabled cheats,
+// either through the core or by writing to the emulated memory directly for
+// the cores that ignore retro_cheat_set.
+package cheats
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/libretro/ludo/coreinfo"
+	"github.com/libretro/ludo/libretro"
+	"github.com/libretro/ludo/settings"
+	"github.com/libretro/ludo/state"
+	"github.com/libretro/ludo/utils"
+)
+
+var (
+	list    []Cheat
+	patches []Patch // patches of the enabled memory cheats, written every frame
+)
+
+// List returns the cheats of the current game
+func List() []Cheat {
+	return list
+}
+
+// path returns the path of the .cht file of the current game. The cheats are
+// saved there with their state, to be restored the next time the game is
+// loaded.
+func path() string {
+	return filepath.Join(settings.Current.CheatsDirectory, utils.FileName(state.GamePath)+".cht")
+}
+
+// lookup finds the .cht file of the game. Files saved by Ludo are preferred
+// over the ones of the cheat database, which are sorted by system.
+func lookup() string {
+	p := path()
+	if _, err := os.Stat(p); err == nil {
+		return p
+	}
+	name := utils.FileName(state.GamePath) + ".cht"
+	matches, _ := filepath.Glob(filepath.Join(settings.Current.CheatsDirectory, "*", escape(name)))
+	if len(matches) > 0 {
+		return matches[0]
+	}
+	return ""
+}
+
+// escape escapes the glob special characters often found in game names
+func escape(name string) string {
+	r := []rune{}
+	for _, c := range name {
+		if c == '[' || c == ']' || c == '*' || c == '?' {
+			r = append(r, '\\')
+		}
+		r = append(r, c)
+	}
+	return string(r)
+}
+
+// Load loads the cheats of the current game, if any, and applies the enabled
+// ones.
+func Load() error {
+	list = nil
+	patches = nil
+	p := lookup()
+	if p == "" {
+		return nil
+	}
+	return LoadFile(p)
+}
+
+// LoadFile replaces the cheats of the current game with the ones of a .cht
+// file.
+func LoadFile(p string) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cheats, err := Parse(f)
+	if err != nil {
+		return err
+	}
+	list = cheats
+	return Apply()
+}
+
+// Unload forgets the cheats of the current game
+func Unload() {
+	list = nil
+	patches = nil
+}
+
+// Save writes the cheats of the current game and their state
+func Save() error {
+	if err := os.MkdirAll(settings.Current.CheatsDirectory, os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.Create(path())
+	if err != nil {
+		return err
+	}
+	if err := Write(f, list); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Add appends a new enabled cheat code
+func Add(code string) error {
+	list = append(list, Cheat{Desc: code, Code: code, Enabled: true, Size: 1})
+	if err := Apply(); err != nil {
+		list = list[:len(list)-1]
+		Apply()
+		return err
+	}
+	return Save()
+}
+
+// Toggle enables or disables a cheat
+func Toggle(i int) error {
+	list[i].Enabled = !list[i].Enabled
+	if err := Apply(); err != nil {
+		list[i].Enabled = !list[i].Enabled
+		Apply()
+		return err
+	}
+	return Save()
+}
+
+// ToggleHandler switches a cheat between being handled by the core and being
+// written to memory by the frontend, overriding the handler picked
+// automatically
+func ToggleHandler(i int) error {
+	old, oldSet := list[i].Handler, list[i].HandlerSet
+	if list[i].ActiveHandler() == HandlerMemory {
+		list[i].Handler = HandlerCore
+	} else {
+		list[i].Handler = HandlerMemory
+	}
+	list[i].HandlerSet = true
+	if err := Apply(); err != nil {
+		list[i].Handler, list[i].HandlerSet = old, oldSet
+		Apply()
+		return err
+	}
+	return Save()
+}
+
+// memoryFallback tells if the cheats should be written to memory, because the
+// core ignores retro_cheat_set but exposes its memory map
+func memoryFallback() bool {
+	if state.Core == nil || len(state.Core.MemoryMap) == 0 {
+		return false
+	}
+	info, ok := coreinfo.Get(state.CorePath)
+	return ok && !info.Cheats
+}
+
+// ActiveHandler returns the handler of a cheat. Unless one was chosen, cheats
+// are written to memory when the core ignores retro_cheat_set and the code
+// can be decoded to memory patches.
+func (c *Cheat) ActiveHandler() int {
+	if c.HandlerSet {
+		return c.Handler
+	}
+	if memoryFallback() {
+		if _, err := c.patches(); err == nil {
+			return HandlerMemory
+		}
+	}
+	return HandlerCore
+}
+
+// Apply sends the enabled cheats to the core, and prepares the memory patches
+// of the memory cheats
+func Apply() error {
+	var ps []Patch
+	for _, c := range list {
+		if !c.Enabled || c.ActiveHandler() != HandlerMemory {
+			continue
+		}
+		cps, err := c.patches()
+		if err != nil {
+			return err
+		}
+		ps = append(ps, cps...)
+	}
+	patches = ps
+
+	if state.Core == nil {
+		return nil
+	}
+	state.Core.CheatReset()
+	for i, c := range list {
+		if c.Enabled && c.ActiveHandler() == HandlerCore {
+			state.Core.CheatSet(uint(i), true, c.Code)
+		}
+	}
+	return nil
+}
+
+// patches returns the memory patches of a memory cheat
+func (c *Cheat) patches() ([]Patch, error) {
+	if c.Address == 0 {
+		return Decode(c.Code)
+	}
+	return []Patch{{
+		Address:   c.Address,
+		Value:     c.Value,
+		Size:      c.Size,
+		BigEndian: c.BigEndian,
+		Compare:   -1,
+	}}, nil
+}
+
+// Poke writes the memory cheats to the emulated memory. It is called before
+// running each frame.
+func Poke() {
+	if len(patches) == 0 || state.Core == nil {
+		return
+	}
+	for _, p := range patches {
+		p.apply(state.Core)
+	}
+}
+
+func (p *Patch) apply(core *libretro.Core) {
+	var ptrs [4]*byte
+	if p.Size < 1 || p.Size > len(ptrs) {
+		return
+	}
+	for i := 0; i < p.Size; i++ {
+		ptr := core.MemoryPointer(uintptr(p.Address) + uintptr(i))
+		if ptr == nil {
+			return
+		}
+		ptrs[i] = (*byte)(ptr)
+	}
+	if p.Compare >= 0 && *ptrs[0] != byte(p.Compare) {
+		return
+	}
+	for i := 0; i < p.Size; i++ {
+		shift := i
+		if p.BigEndian {
+			shift = p.Size - 1 - i
+		}
+		*ptrs[i] = byte(p.Value >> (8 * shift))
+	}
+}
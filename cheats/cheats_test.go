@@ -0,0 +1,230 @@
+package cheats
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/libretro/ludo/coreinfo"
+	"github.com/libretro/ludo/libretro"
+	"github.com/libretro/ludo/settings"
+	"github.com/libretro/ludo/state"
+)
+
+const cht = `cheats = 2
+
+cheat0_desc = "Infinite Lives"
+cheat0_code = "SXIOPO"
+cheat0_enable = true
+
+cheat1_desc = "Max Money"
+cheat1_code = ""
+cheat1_enable = false
+cheat1_handler = 1
+cheat1_address = 1234
+cheat1_value = 9999
+cheat1_memory_search_size = 4
+cheat1_big_endian = true
+`
+
+func TestParse(t *testing.T) {
+	got, err := Parse(strings.NewReader(cht))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Cheat{
+		{Desc: "Infinite Lives", Code: "SXIOPO", Enabled: true, Size: 1},
+		{Desc: "Max Money", Handler: HandlerMemory, HandlerSet: true, Address: 1234, Value: 9999, Size: 2, BigEndian: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got = %+v, want %+v", got, want)
+	}
+
+	t.Run("Round trips through Write", func(t *testing.T) {
+		var b strings.Builder
+		if err := Write(&b, got); err != nil {
+			t.Fatal(err)
+		}
+		again, err := Parse(strings.NewReader(b.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(again, want) {
+			t.Errorf("got = %+v, want %+v", again, want)
+		}
+	})
+
+	t.Run("Rejects files without a cheat count", func(t *testing.T) {
+		if _, err := Parse(strings.NewReader("foo = bar")); err == nil {
+			t.Errorf("got = %v, want an error", err)
+		}
+	})
+
+	t.Run("Rejects out of range cheat counts", func(t *testing.T) {
+		for _, count := range []string{"-1", "999999999"} {
+			if _, err := Parse(strings.NewReader("cheats = " + count)); err == nil {
+				t.Errorf("cheats = %s got = %v, want an error", count, err)
+			}
+		}
+	})
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		code    string
+		want    []Patch
+		wantErr bool
+	}{
+		{"SXIOPO", nil, true},
+		{"zexpygla", nil, true},
+		{"7e0019:09+7E001A:0102", []Patch{
+			{Address: 0x7e0019, Value: 0x09, Size: 1, Compare: -1},
+			{Address: 0x7e001a, Value: 0x0102, Size: 2, Compare: -1},
+		}, false},
+		{"C000:FF:00", []Patch{{Address: 0xc000, Value: 0xff, Size: 1, Compare: 0}}, false},
+		{"SXIOP", nil, true},
+		{"C000:QQ", nil, true},
+		{"", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			got, err := Decode(tt.code)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatch_apply(t *testing.T) {
+	ram := make([]byte, 16)
+	core := &libretro.Core{MemoryMap: []libretro.MemoryDescriptor{
+		{Ptr: unsafe.Pointer(&ram[0]), Start: 0x7e0000, Len: uintptr(len(ram))},
+	}}
+
+	t.Run("Writes little endian values", func(t *testing.T) {
+		p := Patch{Address: 0x7e0002, Value: 0x0102, Size: 2, Compare: -1}
+		p.apply(core)
+		if ram[2] != 0x02 || ram[3] != 0x01 {
+			t.Errorf("got = %x, want %x", ram[2:4], []byte{0x02, 0x01})
+		}
+	})
+
+	t.Run("Writes big endian values", func(t *testing.T) {
+		p := Patch{Address: 0x7e0004, Value: 0x0102, Size: 2, BigEndian: true, Compare: -1}
+		p.apply(core)
+		if ram[4] != 0x01 || ram[5] != 0x02 {
+			t.Errorf("got = %x, want %x", ram[4:6], []byte{0x01, 0x02})
+		}
+	})
+
+	t.Run("Only writes over the compare value", func(t *testing.T) {
+		p := Patch{Address: 0x7e0008, Value: 0xff, Size: 1, Compare: 0x42}
+		p.apply(core)
+		if ram[8] != 0 {
+			t.Errorf("got = %x, want %x", ram[8], 0)
+		}
+		ram[8] = 0x42
+		p.apply(core)
+		if ram[8] != 0xff {
+			t.Errorf("got = %x, want %x", ram[8], 0xff)
+		}
+	})
+
+	t.Run("Ignores unmapped addresses", func(t *testing.T) {
+		p := Patch{Address: 0x7e000f, Value: 0xffff, Size: 2, Compare: -1}
+		p.apply(core)
+		if ram[15] != 0 {
+			t.Errorf("got = %x, want %x", ram[15], 0)
+		}
+	})
+}
+
+func TestCheat_ActiveHandler(t *testing.T) {
+	ram := make([]byte, 16)
+	state.Core = &libretro.Core{MemoryMap: []libretro.MemoryDescriptor{
+		{Ptr: unsafe.Pointer(&ram[0]), Start: 0x7e0000, Len: uintptr(len(ram))},
+	}}
+	state.CorePath = "/cores/foo_libretro.so"
+	coreinfo.Infos = map[string]coreinfo.Info{"foo_libretro": {}}
+	defer func() {
+		state.Core = nil
+		state.CorePath = ""
+		coreinfo.Infos = map[string]coreinfo.Info{}
+	}()
+
+	tests := []struct {
+		name  string
+		cheat Cheat
+		want  int
+	}{
+		{"Writes raw codes to memory", Cheat{Code: "7E0019:09"}, HandlerMemory},
+		{"Leaves game genie codes to the core", Cheat{Code: "SXIOPO"}, HandlerCore},
+		{"Keeps the handler chosen by the user", Cheat{Code: "7E0019:09", HandlerSet: true}, HandlerCore},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cheat.ActiveHandler(); got != tt.want {
+				t.Errorf("got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("Leaves the codes to the cores that support cheats", func(t *testing.T) {
+		coreinfo.Infos["foo_libretro"] = coreinfo.Info{Cheats: true}
+		c := Cheat{Code: "7E0019:09"}
+		if got := c.ActiveHandler(); got != HandlerCore {
+			t.Errorf("got = %v, want %v", got, HandlerCore)
+		}
+	})
+}
+
+func TestPersistence(t *testing.T) {
+	settings.Current.CheatsDirectory = t.TempDir()
+	state.GamePath = "/roms/Super Game (USA).nes"
+	defer func() { state.GamePath = "" }()
+
+	// A file of the cheat database, sorted by system
+	db := filepath.Join(settings.Current.CheatsDirectory, "Nintendo - NES")
+	os.MkdirAll(db, os.ModePerm)
+	os.WriteFile(filepath.Join(db, "Super Game (USA).cht"), []byte(cht), 0644)
+
+	if err := Load(); err != nil {
+		t.Fatal(err)
+	}
+	if len(List()) != 2 {
+		t.Fatalf("got = %v, want %v", len(List()), 2)
+	}
+
+	if err := Toggle(1); err != nil {
+		t.Fatal(err)
+	}
+	if len(patches) != 1 {
+		t.Errorf("got = %v, want %v", len(patches), 1)
+	}
+
+	Unload()
+	if err := Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Restores the state of the cheats", func(t *testing.T) {
+		if !List()[1].Enabled {
+			t.Errorf("got = %v, want %v", List()[1].Enabled, true)
+		}
+	})
+
+	t.Run("Saves next to the cheat database", func(t *testing.T) {
+		if _, err := os.Stat(filepath.Join(settings.Current.CheatsDirectory, "Super Game (USA).cht")); err != nil {
+			t.Error(err)
+		}
+	})
+
+	Unload()
+}
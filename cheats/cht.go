@@ -0,0 +1,132 @@
+package cheats
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Cheat handlers, as found in RetroArch .cht files
+const (
+	// HandlerCore passes the code to the core through retro_cheat_set
+	HandlerCore = 0
+	// HandlerMemory writes the value to the memory of the emulated system
+	// every frame
+	HandlerMemory = 1
+)
+
+// maxCheats bounds the cheat count of a .cht file, the biggest files of the
+// RetroArch database have a few thousand cheats
+const maxCheats = 65536
+
+// Cheat is a cheat code of a RetroArch .cht file
+type Cheat struct {
+	Desc    string
+	Code    string
+	Enabled bool
+	Handler int
+	// HandlerSet is true when the handler was chosen, in the file or by the
+	// user. The handler is picked automatically otherwise.
+	HandlerSet bool
+
+	// Used by memory cheats. If Address is 0 the code is decoded instead.
+	Address   uint32
+	Value     uint32
+	Size      int // in bytes, 1, 2 or 4
+	BigEndian bool
+}
+
+// Parse reads the cheats of a .cht file. Cheats are numbered entries like
+//
+//	cheats = 1
+//	cheat0_desc = "Infinite Lives"
+//	cheat0_code = "SXIOPO"
+//	cheat0_enable = false
+func Parse(r io.Reader) ([]Cheat, error) {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(kv[0])
+		val := strings.TrimSpace(kv[1])
+		values[key] = strings.Trim(val, `"`)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	n, err := strconv.Atoi(values["cheats"])
+	if err != nil || n < 0 || n > maxCheats {
+		return nil, fmt.Errorf("invalid cheat count: %q", values["cheats"])
+	}
+
+	cheats := make([]Cheat, n)
+	for i := range cheats {
+		get := func(field string) string {
+			return values[fmt.Sprintf("cheat%d_%s", i, field)]
+		}
+		c := &cheats[i]
+		c.Desc = get("desc")
+		c.Code = get("code")
+		c.Enabled = get("enable") == "true"
+		c.Handler, _ = strconv.Atoi(get("handler"))
+		_, c.HandlerSet = values[fmt.Sprintf("cheat%d_handler", i)]
+		address, _ := strconv.ParseUint(get("address"), 10, 32)
+		c.Address = uint32(address)
+		value, _ := strconv.ParseUint(get("value"), 10, 32)
+		c.Value = uint32(value)
+		c.BigEndian = get("big_endian") == "true"
+		c.Size = 1
+		// RetroArch stores the size as a power of two in bits
+		switch get("memory_search_size") {
+		case "4":
+			c.Size = 2
+		case "5":
+			c.Size = 4
+		}
+		if c.Desc == "" {
+			c.Desc = c.Code
+		}
+	}
+	return cheats, nil
+}
+
+// Write writes cheats in the .cht format
+func Write(w io.Writer, cheats []Cheat) error {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "cheats = %d\n", len(cheats))
+	for i, c := range cheats {
+		fmt.Fprintf(b, "\n")
+		fmt.Fprintf(b, "cheat%d_desc = \"%s\"\n", i, strings.ReplaceAll(c.Desc, `"`, `'`))
+		fmt.Fprintf(b, "cheat%d_code = \"%s\"\n", i, strings.ReplaceAll(c.Code, `"`, ``))
+		fmt.Fprintf(b, "cheat%d_enable = %t\n", i, c.Enabled)
+		if c.HandlerSet && c.Handler != HandlerMemory {
+			fmt.Fprintf(b, "cheat%d_handler = %d\n", i, c.Handler)
+		}
+		if c.Handler == HandlerMemory {
+			size := 3
+			switch c.Size {
+			case 2:
+				size = 4
+			case 4:
+				size = 5
+			}
+			fmt.Fprintf(b, "cheat%d_handler = %d\n", i, c.Handler)
+			fmt.Fprintf(b, "cheat%d_address = %d\n", i, c.Address)
+			fmt.Fprintf(b, "cheat%d_value = %d\n", i, c.Value)
+			fmt.Fprintf(b, "cheat%d_memory_search_size = %d\n", i, size)
+			fmt.Fprintf(b, "cheat%d_big_endian = %t\n", i, c.BigEndian)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,81 @@
+package cheats
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Patch is a value to write to the memory of the emulated system
+type Patch struct {
+	Address   uint32
+	Value     uint32
+	Size      int  // in bytes
+	BigEndian bool // byte order of multi byte values
+	Compare   int  // the value is only written over this byte, -1 to always write
+}
+
+// ggLetters are the letters of NES Game Genie codes
+const ggLetters = "APZLGITYEOXUKSVN"
+
+// Decode turns a cheat code into memory patches. It understands raw codes
+// written as address:value or address:value:compare in hexadecimal, pointing
+// to the RAM. Several codes can be joined with + or spaces.
+func Decode(code string) ([]Patch, error) {
+	var patches []Patch
+	for _, c := range strings.FieldsFunc(code, func(r rune) bool { return r == '+' || r == ' ' }) {
+		p, err := decodeOne(strings.ToUpper(c))
+		if err != nil {
+			return nil, err
+		}
+		patches = append(patches, p)
+	}
+	if len(patches) == 0 {
+		return nil, errors.New("empty cheat code")
+	}
+	return patches, nil
+}
+
+func decodeOne(code string) (Patch, error) {
+	if strings.Contains(code, ":") {
+		return decodeRaw(code)
+	}
+	// Game Genie codes patch the ROM, which is banked: writing them to memory
+	// every frame would corrupt whichever bank is mapped
+	if (len(code) == 6 || len(code) == 8) && strings.Trim(code, ggLetters) == "" {
+		return Patch{}, errors.New("game genie codes can only be applied by the core: " + code)
+	}
+	return Patch{}, errors.New("unsupported cheat code: " + code)
+}
+
+func decodeRaw(code string) (Patch, error) {
+	parts := strings.Split(code, ":")
+	if len(parts) > 3 {
+		return Patch{}, errors.New("invalid raw cheat code: " + code)
+	}
+	address, err := strconv.ParseUint(parts[0], 16, 32)
+	if err != nil {
+		return Patch{}, err
+	}
+	value, err := strconv.ParseUint(parts[1], 16, 32)
+	if err != nil {
+		return Patch{}, err
+	}
+	p := Patch{
+		Address: uint32(address),
+		Value:   uint32(value),
+		Size:    (len(parts[1]) + 1) / 2,
+		Compare: -1,
+	}
+	if p.Size > 4 || p.Size == 3 {
+		return Patch{}, errors.New("invalid raw cheat value: " + parts[1])
+	}
+	if len(parts) == 3 {
+		compare, err := strconv.ParseUint(parts[2], 16, 8)
+		if err != nil {
+			return Patch{}, err
+		}
+		p.Compare = int(compare)
+	}
+	return p, nil
+}
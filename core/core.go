@@ -5,23 +5,33 @@ package core
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/libretro/ludo/achievements"
 	"github.com/libretro/ludo/audio"
+	"github.com/libretro/ludo/cheats"
+	"github.com/libretro/ludo/firmware"
+	"github.com/libretro/ludo/history"
 	"github.com/libretro/ludo/input"
 	"github.com/libretro/ludo/libretro"
+	"github.com/libretro/ludo/memsearch"
+	ntf "github.com/libretro/ludo/notifications"
 	"github.com/libretro/ludo/options"
 	"github.com/libretro/ludo/patch"
+	"github.com/libretro/ludo/rewind"
+	"github.com/libretro/ludo/rumble"
 	"github.com/libretro/ludo/savefiles"
+	"github.com/libretro/ludo/savestates"
+	"github.com/libretro/ludo/settings"
 	"github.com/libretro/ludo/state"
+	"github.com/libretro/ludo/vfs"
 	"github.com/libretro/ludo/video"
 
-	"github.com/mholt/archiver/v3"
-
 	"github.com/libretro/ludo/l10n"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 )
@@ -49,17 +59,18 @@ func Load(sofile string) error {
 	state.SystemName = ""
 
 	var err error
+	shutdownRequested = false
+	runAheadDisabled = false
+
 	state.Core, err = libretro.Load(sofile)
 	if err != nil {
 		return err
 	}
 	state.Core.SetEnvironment(environment)
 	state.Core.Init()
-	state.Core.SetVideoRefresh(vid.Refresh)
 	state.Core.SetInputPoll(func() {})
 	state.Core.SetInputState(input.State)
-	state.Core.SetAudioSample(audio.Sample)
-	state.Core.SetAudioSampleBatch(audio.SampleBatch)
+	setOutputs(true, true)
 
 	// Append the library name to the window title.
 	si := state.Core.GetSystemInfo()
@@ -77,67 +88,82 @@ func Load(sofile string) error {
 	return nil
 }
 
-// unarchiveGame unarchives a rom to tmpdir and returns the path and size of the extracted ROM.
-// In case the archive contains more than one file, they are all extracted and the
-// first one or a better match (cue for CDrom) is passed to the libretro core.
-func unarchiveGame(filename string) (string, int64, error) {
-	path := ""
-	size := int64(0)
-	dst := os.TempDir()
+// extracted are the temporary directories holding the archives extracted for
+// the current game, removed when the game is unloaded
+var extracted []string
 
-	var err error
-	// What a wonderful API, all this boilerplate to set an option
-	switch filepath.Ext(filename) {
-	case ".zip":
-		un := archiver.Zip{
-			OverwriteExisting: true,
-		}
-		err = un.Unarchive(filename, dst)
-	case ".tar":
-		un := archiver.Tar{
-			OverwriteExisting: true,
-		}
-		err = un.Unarchive(filename, dst)
-	case ".rar":
-		un := archiver.Rar{
-			OverwriteExisting: true,
-		}
-		err = un.Unarchive(filename, dst)
-	default:
-		// Unarchive with default option
-		err = archiver.Unarchive(filename, dst)
-	}
+// gameInArchive returns the name and size of the ROM to load in an archive.
+// In case the archive contains more than one file, the first one or a better
+// match (cue for CDrom) is picked.
+func gameInArchive(filename string) (string, int64, error) {
+	members, err := vfs.Members(filename)
 	if err != nil {
-		return path, size, err
+		return "", 0, err
+	}
+	if len(members) == 0 {
+		return "", 0, fmt.Errorf("%s is empty", filename)
+	}
+
+	// Give priority of some extensions (use lower case to be case insensitive)
+	extPrefered := map[string]int{
+		".cue": 1,
+		".m3u": 2,
+		".pbp": 3,
 	}
 
+	// By default select the first file of the archive
+	game := members[0]
 	extPriority := 0 // current priority
-	// Give priority of some extensions (use upper case to be case insensitive)
-	extPrefered := make(map[string]int)
-	extPrefered[".cue"] = 1
-	extPrefered[".m3u"] = 2
-	extPrefered[".pbp"] = 3
-
-	err = archiver.Walk(filename, func(f archiver.File) error {
-		fname := f.Name()
-		ext := filepath.Ext(fname)
-		if size == 0 {
-			// By default select the first file of the archive
-			path = filepath.Join(dst, fname)
-			size = f.Size()
-			log.Println("first file in archive:", path, size)
-		}
+	for _, m := range members {
 		// Check if a file (based on extension) has a higher priority
-		priority, ok := extPrefered[strings.ToLower(ext)]
+		priority, ok := extPrefered[strings.ToLower(filepath.Ext(m.Name))]
 		if ok && priority > extPriority {
 			extPriority = priority
-			path = filepath.Join(dst, fname)
-			size = f.Size()
-			log.Println("find a better file in archive:", path, size)
+			game = m
 		}
-		return nil
-	})
-	return path, size, err
+	}
+	log.Println("[Core]: Game in archive:", game.Name, game.Size)
+	return game.Name, game.Size, nil
+}
+
+// unarchiveGame unarchives a rom to a temporary directory of its own and
+// returns the path and size of the extracted ROM. This is only needed for the
+// cores that want a path on the disk and don't open their files through VFS.
+func unarchiveGame(filename string) (string, int64, error) {
+	dst, err := ioutil.TempDir("", "ludo-")
+	if err != nil {
+		return "", 0, err
+	}
+	extracted = append(extracted, dst)
+
+	if err := vfs.Unarchive(filename, dst); err != nil {
+		return "", 0, err
+	}
+
+	if !vfs.IsArchive(filename) {
+		// Compressed single files like .zst
+		files, err := ioutil.ReadDir(dst)
+		if err != nil || len(files) == 0 {
+			return "", 0, err
+		}
+		return filepath.Join(dst, files[0].Name()), files[0].Size(), nil
+	}
+
+	name, size, err := gameInArchive(filename)
+	if err != nil {
+		return "", 0, err
+	}
+	return filepath.Join(dst, filepath.FromSlash(name)), size, nil
+}
+
+// removeExtracted deletes the archives extracted for the current game, and the
+// archive members extracted by VFS
+func removeExtracted() {
+	for _, dir := range extracted {
+		os.RemoveAll(dir)
+	}
+	extracted = nil
+	vfs.Cleanup()
 }
 
 // LoadGame loads a game. A core has to be loaded first.
@@ -154,13 +180,13 @@ func LoadGame(gamePath string) error {
 
 	si := state.Core.GetSystemInfo()
 
-	gi, err := getGameInfo(gamePath, si.BlockExtract)
+	gi, err := getGameInfo(gamePath, si.BlockExtract, si.NeedFullpath)
 	if err != nil {
 		return err
 	}
 
 	if !si.NeedFullpath {
-		bytes, err := ioutil.ReadFile(gi.Path)
+		bytes, err := vfs.ReadFile(gi.Path)
 		if err != nil {
 			return err
 		}
@@ -173,6 +199,10 @@ func LoadGame(gamePath string) error {
 		}
 	}
 
+	setOptionsGame(gamePath)
+	warnMissingFirmware()
+	setInitialDisk(gamePath)
+
 	ok := state.Core.LoadGame(*gi)
 	if !ok {
 		state.CoreRunning = false
@@ -180,6 +210,104 @@ func LoadGame(gamePath string) error {
 		return errors.New(txtI18n)
 	}
 
+	gameLoaded(gamePath, si)
+
+	return nil
+}
+
+// LoadGameSpecial loads a game made of several ROMs through one of the
+// subsystems declared by the core. paths follows the order of the subsystem
+// ROMs, optional ROMs can be skipped with an empty path.
+func LoadGameSpecial(sub libretro.SubsystemInfo, paths []string) error {
+	if len(paths) != len(sub.ROMs) {
+		txtI18n := l10n.T9(&i18n.Message{ID: "SubsystemMissingROMs", Other: "the subsystem needs %d ROMs"})
+		return fmt.Errorf(txtI18n, len(sub.ROMs))
+	}
+
+	// The first ROM given names the game in the history and in the savestates
+	gamePath := ""
+	for _, path := range paths {
+		if path != "" {
+			gamePath = path
+			break
+		}
+	}
+	if gamePath == "" {
+		txtI18n := l10n.T9(&i18n.Message{ID: "SubsystemNoROM", Other: "no ROM selected"})
+		return errors.New(txtI18n)
+	}
+
+	UnloadGame()
+
+	gis := make([]libretro.GameInfo, len(paths))
+	for i, rom := range sub.ROMs {
+		if paths[i] == "" {
+			if rom.Required {
+				txtI18n := l10n.T9(&i18n.Message{ID: "SubsystemROMRequired", Other: "%s is required"})
+				return fmt.Errorf(txtI18n, rom.Desc)
+			}
+			continue
+		}
+
+		gi, err := getGameInfo(paths[i], rom.BlockExtract, rom.NeedFullpath)
+		if err != nil {
+			return err
+		}
+
+		if !rom.NeedFullpath {
+			bytes, err := vfs.ReadFile(gi.Path)
+			if err != nil {
+				return err
+			}
+			gi.SetData(bytes)
+		}
+
+		gis[i] = *gi
+	}
+
+	setOptionsGame(gamePath)
+	warnMissingFirmware()
+
+	ok := state.Core.LoadGameSpecial(sub.ID, gis)
+	if !ok {
+		state.CoreRunning = false
+		txtI18n := l10n.T9(&i18n.Message{ID: "FailedLoadGame", Other: "failed to load the game"})
+		return errors.New(txtI18n)
+	}
+
+	// The save files of each ROM follow the memory descriptors of the subsystem
+	savefiles.SetSubsystem(sub.ROMs, paths)
+
+	gameLoaded(gamePath, state.Core.GetSystemInfo())
+
+	return nil
+}
+
+// warnMissingFirmware tells the user about the required firmware files of the
+// core that are missing or bad, as the core may fail without saying why
+func warnMissingFirmware() {
+	missing := firmware.MissingFor(state.CorePath)
+	if len(missing) == 0 {
+		return
+	}
+	txtI18n := l10n.T9(&i18n.Message{ID: "FirmwareMissingWarning", Other: "Missing or bad firmware in the system directory: %s"})
+	ntf.DisplayAndLog(ntf.Warning, "Core", txtI18n, strings.Join(missing, ", "))
+}
+
+// setOptionsGame merges the core option overrides of a game, before the core
+// reads them while loading the game
+func setOptionsGame(gamePath string) {
+	options.SetGame(gamePath)
+	if Options == nil {
+		return
+	}
+	if err := Options.Reload(); err != nil {
+		log.Println("[Options]:", err)
+	}
+}
+
+// gameLoaded configures the frontend for a game that the core just loaded
+func gameLoaded(gamePath string, si libretro.SystemInfo) {
 	avi := state.Core.GetSystemAVInfo()
 
 	vid.Geom = avi.Geometry
@@ -189,8 +317,13 @@ func LoadGame(gamePath string) error {
 		vid.SetTitle("Ludo - " + si.LibraryName)
 	}
 
-	input.Init(vid)
-	audio.Reconfigure(int32(avi.Timing.SampleRate))
+	if !state.Headless {
+		input.Init(vid)
+		audio.Reconfigure(int32(avi.Timing.SampleRate))
+		if err := rumble.Open(input.GamepadNames()); err != nil {
+			log.Println("[Rumble]:", err)
+		}
+	}
 	if state.Core.AudioCallback != nil {
 		state.Core.AudioCallback.SetState(true)
 	}
@@ -198,17 +331,53 @@ func LoadGame(gamePath string) error {
 	state.CoreRunning = true
 	state.FastForward = false
 	state.GamePath = gamePath
+	state.PlayTime = 0
+
+	loadPorts()
+	loadRemaps()
 
-	state.Core.SetControllerPortDevice(0, libretro.DeviceJoypad)
-	state.Core.SetControllerPortDevice(1, libretro.DeviceJoypad)
-	state.Core.SetControllerPortDevice(2, libretro.DeviceJoypad)
-	state.Core.SetControllerPortDevice(3, libretro.DeviceJoypad)
-	state.Core.SetControllerPortDevice(4, libretro.DeviceJoypad)
+	rewind.Reset()
 
 	log.Println("[Core]: Game loaded: " + gamePath)
 	savefiles.LoadSRAM()
+	if err := cheats.Load(); err != nil {
+		log.Println("[Cheats]:", err)
+	}
+	if err := achievements.Load(); err != nil {
+		log.Println("[Achievements]:", err)
+	}
+}
 
-	return nil
+// RunFrame runs the core for one frame, running ahead if enabled in the settings.
+func RunFrame() {
+	if runAheadEnabled() {
+		runAhead(settings.Current.RunAheadFrames)
+		return
+	}
+	runOnce()
+}
+
+// Step runs the core for exactly one frame, without run-ahead. The video and
+// audio output can be discarded, netplay uses this to replay frames.
+func Step(output bool) {
+	if !output {
+		setOutputs(false, false)
+		defer setOutputs(true, true)
+	}
+	runOnce()
+}
+
+// runOnce writes the memory cheats, runs the core for one frame and triggers
+// the frame time and audio callbacks if the core registered them.
+func runOnce() {
+	cheats.Poke()
+	state.Core.Run()
+	if state.Core.FrameTimeCallback != nil {
+		state.Core.FrameTimeCallback.Callback(state.Core.FrameTimeCallback.Reference)
+	}
+	if state.Core.AudioCallback != nil {
+		state.Core.AudioCallback.Callback()
+	}
 }
 
 // Unload unloads a libretro core
@@ -226,20 +395,64 @@ func Unload() {
 func UnloadGame() {
 	if state.CoreRunning {
 		savefiles.SaveSRAM()
+		savefiles.SetSubsystem(nil, nil)
+		// Headless runs are for testing, they leave the user's state alone
+		if !state.Headless {
+			if err := SaveDisk(); err != nil {
+				log.Println("[Core]:", err)
+			}
+			saveAutoSavestate()
+		}
 		state.Core.UnloadGame()
 		state.GamePath = ""
 		state.CoreRunning = false
+		rewind.Reset()
+		cheats.Unload()
+		achievements.Unload()
+		memsearch.Reset()
+		input.ResetDevices()
+		input.ResetRemaps()
+		rumble.Close()
+		removeExtracted()
+		setOptionsGame("")
 		vid.ResetPitch()
 		vid.ResetRot()
 	}
 }
 
-// getGameInfo opens a rom and return the libretro.GameInfo needed to launch it
-func getGameInfo(filename string, blockExtract bool) (*libretro.GameInfo, error) {
+// saveAutoSavestate saves the auto savestate of the running game, if enabled,
+// with a thumbnail like the manual savestates, and records it in the history
+// so the game can be resumed from it
+func saveAutoSavestate() {
+	if !settings.Current.SavestateAuto || state.GamePath == "" {
+		return
+	}
+	// TakeScreenshot leaves the menu open, which is not ours to decide here
+	menuActive := state.MenuActive
+	if err := vid.TakeScreenshot(savestates.AutoName(state.GamePath)); err != nil {
+		log.Println("[Core]:", err)
+	}
+	state.MenuActive = menuActive
+	path, err := savestates.SaveAuto()
+	if err != nil {
+		log.Println("[Core]:", err)
+		return
+	}
+	if err := history.SetSavestate(state.GamePath, path); err != nil {
+		log.Println("[Core]:", err)
+	}
+}
+
+// getGameInfo opens a rom and return the libretro.GameInfo needed to launch it.
+// ROMs in archives are read straight from the archive, either by us for the
+// cores that take the data, or by the core through VFS. The other cores get an
+// extracted copy.
+func getGameInfo(filename string, blockExtract bool, needFullpath bool) (*libretro.GameInfo, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 
 	fi, err := file.Stat()
 	if err != nil {
@@ -247,8 +460,15 @@ func getGameInfo(filename string, blockExtract bool) (*libretro.GameInfo, error)
 	}
 
 	if !blockExtract {
-		switch filepath.Ext(filename) {
-		case ".zip", ".zst", ".rar", ".tar":
+		if vfs.IsArchive(filename) && (!needFullpath || state.Core != nil && state.Core.VFS) {
+			name, size, err := gameInArchive(filename)
+			if err != nil {
+				return nil, err
+			}
+			return &libretro.GameInfo{Path: vfs.Join(filename, name), Size: size}, nil
+		}
+
+		if vfs.IsArchive(filename) || strings.EqualFold(filepath.Ext(filename), ".zst") {
 			path, size, err := unarchiveGame(filename)
 			if err != nil {
 				return nil, err
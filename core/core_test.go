@@ -1,15 +1,19 @@
 package core
 
 import (
+	"fmt"
 	"log"
-	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/adrg/xdg"
 	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/libretro/ludo/input"
 	"github.com/libretro/ludo/libretro"
+	ntf "github.com/libretro/ludo/notifications"
+	"github.com/libretro/ludo/settings"
 	"github.com/libretro/ludo/state"
 	"github.com/libretro/ludo/utils"
 	"github.com/libretro/ludo/video"
@@ -65,6 +69,7 @@ func Test_getGameInfo(t *testing.T) {
 	type args struct {
 		filename     string
 		blockExtract bool
+		needFullpath bool
 	}
 	tests := []struct {
 		name    string
@@ -82,10 +87,10 @@ func Test_getGameInfo(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "Returns the right path and size for a zipped ROM",
+			name: "Returns the path inside the archive and size for a zipped ROM",
 			args: args{filename: "testdata/Polar Rescue (USA).zip", blockExtract: false},
 			want: &libretro.GameInfo{
-				Path: filepath.Join(os.TempDir(), "Polar Rescue (USA).vec"),
+				Path: "testdata/Polar Rescue (USA).zip#Polar Rescue (USA).vec",
 				Size: 8192,
 			},
 			wantErr: false,
@@ -126,7 +131,7 @@ func Test_getGameInfo(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := getGameInfo(tt.args.filename, tt.args.blockExtract)
+			got, err := getGameInfo(tt.args.filename, tt.args.blockExtract, tt.args.needFullpath)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getGameInfo() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -150,9 +155,16 @@ func Test_unarchiveGame(t *testing.T) {
 		wantErr bool
 	}{
 		{
-			name:    "Should unzip to the right path",
+			name:    "Should unzip to a directory of its own",
 			args:    args{filename: "testdata/Polar Rescue (USA).zip"},
-			want:    filepath.Join(os.TempDir(), "Polar Rescue (USA).vec"),
+			want:    "Polar Rescue (USA).vec",
+			want1:   8192,
+			wantErr: false,
+		},
+		{
+			name:    "Should decompress single .zst files",
+			args:    args{filename: "testdata/Polar Rescue (USA).vec.zst"},
+			want:    "Polar Rescue (USA).vec",
 			want1:   8192,
 			wantErr: false,
 		},
@@ -173,11 +185,18 @@ func Test_unarchiveGame(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			defer removeExtracted()
 			got, got1, err := unarchiveGame(tt.args.filename)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("unarchiveGame() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if got != "" && filepath.Dir(got) != extracted[len(extracted)-1] {
+				t.Errorf("unarchiveGame() extracted to %v, want %v", filepath.Dir(got), extracted[len(extracted)-1])
+			}
+			if got != "" {
+				got = filepath.Base(got)
+			}
 			if got != tt.want {
 				t.Errorf("unarchiveGame() got = %v, want %v", got, tt.want)
 			}
@@ -188,6 +207,275 @@ func Test_unarchiveGame(t *testing.T) {
 	}
 }
 
+func Test_RunHeadless(t *testing.T) {
+	state.Headless = true
+
+	ext := utils.CoreExt()
+
+	Init(&video.Video{})
+
+	Load("testdata/vecx_libretro" + ext)
+	utils.CaptureOutput(func() { LoadGame("testdata/Polar Rescue (USA).vec") })
+
+	t.Run("Runs the requested number of frames", func(t *testing.T) {
+		got := RunHeadless(120, nil, nil)
+		if got != 120 {
+			t.Errorf("got = %v, want %v", got, 120)
+		}
+	})
+
+	t.Run("Stops when the condition is met", func(t *testing.T) {
+		i := 0
+		got := RunHeadless(0, nil, func() bool { i++; return i == 10 })
+		if got != 10 {
+			t.Errorf("got = %v, want %v", got, 10)
+		}
+	})
+
+	UnloadGame()
+
+	t.Run("Doesn't run without a game", func(t *testing.T) {
+		got := RunHeadless(10, nil, nil)
+		if got != 0 {
+			t.Errorf("got = %v, want %v", got, 0)
+		}
+	})
+
+	Unload()
+	state.Headless = false
+}
+
+func Test_runAhead(t *testing.T) {
+	state.Headless = true
+
+	ext := utils.CoreExt()
+
+	Init(&video.Video{})
+
+	Load("testdata/vecx_libretro" + ext)
+	utils.CaptureOutput(func() { LoadGame("testdata/Polar Rescue (USA).vec") })
+
+	t.Run("Keeps running the game", func(t *testing.T) {
+		settings.Current.RunAheadFrames = 2
+		got := RunHeadless(30, nil, nil)
+		if got != 30 {
+			t.Errorf("got = %v, want %v", got, 30)
+		}
+		if runAheadDisabled {
+			t.Errorf("got = %v, want %v", runAheadDisabled, false)
+		}
+	})
+
+	t.Run("Turns itself off for incomplete savestates", func(t *testing.T) {
+		state.Core.SerializationQuirks = libretro.SerializationQuirkIncomplete
+		utils.CaptureOutput(func() {
+			if runAheadEnabled() {
+				t.Errorf("got = %v, want %v", true, false)
+			}
+		})
+		if !runAheadDisabled {
+			t.Errorf("got = %v, want %v", runAheadDisabled, true)
+		}
+	})
+
+	settings.Current.RunAheadFrames = 0
+	Unload()
+	state.Headless = false
+}
+
+func Test_LoadGameSpecial(t *testing.T) {
+	sub := libretro.SubsystemInfo{
+		Desc:  "Super Game Boy",
+		Ident: "sgb",
+		ROMs: []libretro.SubsystemROMInfo{
+			{Desc: "BIOS", Required: true},
+			{Desc: "Game", Required: false},
+		},
+	}
+
+	t.Run("Needs one path per ROM", func(t *testing.T) {
+		if err := LoadGameSpecial(sub, []string{"bios.sfc"}); err == nil {
+			t.Errorf("got = %v, want an error", err)
+		}
+	})
+
+	t.Run("Required ROMs can't be skipped", func(t *testing.T) {
+		err := LoadGameSpecial(sub, []string{"", "game.gb"})
+		if err == nil || err.Error() != "BIOS is required" {
+			t.Errorf("got = %v, want %v", err, "BIOS is required")
+		}
+	})
+
+	t.Run("Needs at least one ROM", func(t *testing.T) {
+		sub := libretro.SubsystemInfo{ROMs: []libretro.SubsystemROMInfo{{Desc: "Game"}}}
+		if err := LoadGameSpecial(sub, []string{""}); err == nil {
+			t.Errorf("got = %v, want an error", err)
+		}
+	})
+}
+
+func Test_PortDevices(t *testing.T) {
+	saved := state.Core
+	defer func() { state.Core = saved }()
+
+	t.Run("Offers a RetroPad when the core has no controller info", func(t *testing.T) {
+		state.Core = &libretro.Core{}
+		got := len(PortDevices(0))
+		if got != 3 {
+			t.Errorf("got = %v, want %v", got, 3)
+		}
+	})
+
+	t.Run("Follows the controller info of the core", func(t *testing.T) {
+		multitap := libretro.DeviceSubclass(libretro.DeviceJoypad, 1)
+		state.Core = &libretro.Core{Controllers: [][]libretro.ControllerDescription{
+			{{Desc: "SNES Joypad", ID: libretro.DeviceJoypad}},
+			{{Desc: "SNES Joypad", ID: libretro.DeviceJoypad}, {Desc: "Multitap", ID: multitap}},
+		}}
+		if got := len(PortDevices(1)); got != 3 {
+			t.Errorf("got = %v, want %v", got, 3)
+		}
+		if !acceptsDevice(1, multitap) {
+			t.Errorf("got = %v, want %v", false, true)
+		}
+		if acceptsDevice(0, multitap) {
+			t.Errorf("got = %v, want %v", true, false)
+		}
+	})
+}
+
+func Test_SaveRemaps(t *testing.T) {
+	config := xdg.ConfigHome
+	xdg.ConfigHome = t.TempDir()
+	state.CorePath = "cores/snes9x_libretro.so"
+	state.GamePath = "roms/Super Metroid.sfc"
+	defer func() {
+		xdg.ConfigHome = config
+		state.CorePath = ""
+		state.GamePath = ""
+		input.ResetRemaps()
+	}()
+
+	input.SetRemap(0, libretro.DeviceIDJoypadB, libretro.DeviceIDJoypadY)
+	if err := SaveRemaps(false); err != nil {
+		t.Fatal(err)
+	}
+	input.SetRemap(1, libretro.DeviceIDJoypadA, libretro.DeviceIDJoypadX)
+	if err := SaveRemaps(true); err != nil {
+		t.Fatal(err)
+	}
+	input.ResetRemaps()
+
+	t.Run("The game remap overrides the core remap", func(t *testing.T) {
+		loadRemaps()
+		if got := input.Remapped(1, libretro.DeviceIDJoypadA); got != libretro.DeviceIDJoypadX {
+			t.Errorf("got = %v, want %v", got, libretro.DeviceIDJoypadX)
+		}
+	})
+
+	t.Run("Removing the game remap falls back to the core remap", func(t *testing.T) {
+		if err := RemoveGameRemaps(); err != nil {
+			t.Fatal(err)
+		}
+		if got := input.Remapped(1, libretro.DeviceIDJoypadA); got != libretro.DeviceIDJoypadA {
+			t.Errorf("got = %v, want %v", got, libretro.DeviceIDJoypadA)
+		}
+		if got := input.Remapped(0, libretro.DeviceIDJoypadB); got != libretro.DeviceIDJoypadY {
+			t.Errorf("got = %v, want %v", got, libretro.DeviceIDJoypadY)
+		}
+	})
+}
+
+func Test_SaveDisk(t *testing.T) {
+	config := xdg.ConfigHome
+	xdg.ConfigHome = t.TempDir()
+	saved := state.Core
+	state.CorePath = "cores/mednafen_psx_libretro.so"
+	state.GamePath = "roms/Final Fantasy VII.m3u"
+	state.CoreRunning = true
+	defer func() {
+		xdg.ConfigHome = config
+		state.Core = saved
+		state.CorePath = ""
+		state.GamePath = ""
+		state.CoreRunning = false
+	}()
+
+	var initialIndex uint
+	var initialPath string
+	state.Core = &libretro.Core{DiskControlCallback: &libretro.DiskControlCallback{
+		GetImageIndex: func() uint { return 2 },
+		GetImagePath: func(index uint) (string, bool) {
+			return fmt.Sprintf("roms/Final Fantasy VII (Disc %d).chd", index+1), true
+		},
+		SetInitialImage: func(index uint, path string) bool {
+			initialIndex, initialPath = index, path
+			return true
+		},
+	}}
+
+	if err := SaveDisk(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Resumes on the last used disk", func(t *testing.T) {
+		setInitialDisk(state.GamePath)
+		if initialIndex != 2 {
+			t.Errorf("got = %v, want %v", initialIndex, 2)
+		}
+		want := "roms/Final Fantasy VII (Disc 3).chd"
+		if initialPath != want {
+			t.Errorf("got = %v, want %v", initialPath, want)
+		}
+	})
+
+	t.Run("Uses the image file name as a label", func(t *testing.T) {
+		want := "Final Fantasy VII (Disc 2).chd"
+		if got := DiskLabel(1); got != want {
+			t.Errorf("got = %v, want %v", got, want)
+		}
+	})
+}
+
+func Test_showMessage(t *testing.T) {
+	ntf.Clear()
+	defer ntf.Clear()
+
+	t.Run("Warnings are displayed as warnings", func(t *testing.T) {
+		showMessage(libretro.Message{Msg: "BIOS not found", Duration: 2, Level: libretro.LogLevelWarn})
+		list := ntf.List()
+		if len(list) != 1 {
+			t.Fatalf("got = %v, want %v", len(list), 1)
+		}
+		if list[0].Severity != ntf.Warning || list[0].Duration != 2 {
+			t.Errorf("got = %v, want %v", *list[0], ntf.Notification{Severity: ntf.Warning, Message: "BIOS not found", Duration: 2})
+		}
+	})
+
+	t.Run("Log only messages are not displayed", func(t *testing.T) {
+		ntf.Clear()
+		showMessage(libretro.Message{Msg: "Loaded", Target: libretro.MessageTargetLog})
+		if got := len(ntf.List()); got != 0 {
+			t.Errorf("got = %v, want %v", got, 0)
+		}
+	})
+
+	t.Run("Progress messages update a single notification", func(t *testing.T) {
+		ntf.Clear()
+		for _, p := range []int8{10, 50, 90} {
+			showMessage(libretro.Message{Msg: "Compiling shaders", Type: libretro.MessageTypeProgress, Progress: p})
+		}
+		list := ntf.List()
+		if len(list) != 1 {
+			t.Fatalf("got = %v, want %v", len(list), 1)
+		}
+		want := "Compiling shaders (90%)"
+		if list[0].Message != want {
+			t.Errorf("got = %v, want %v", list[0].Message, want)
+		}
+	})
+}
+
 func Test_coreLoadGame(t *testing.T) {
 	state.Verbose = true
 
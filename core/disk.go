@@ -0,0 +1,133 @@
+package core
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/adrg/xdg"
+	"github.com/libretro/ludo/libretro"
+	"github.com/libretro/ludo/state"
+	"github.com/libretro/ludo/utils"
+	"github.com/pelletier/go-toml"
+
+	"github.com/libretro/ludo/l10n"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// diskRecord is the disk image a multi-disk game was last played with
+type diskRecord struct {
+	Index uint   `toml:"index"`
+	Path  string `toml:"path"`
+}
+
+// diskPath returns the path of the disk record of a game
+func diskPath(gamePath string) string {
+	return filepath.Join(xdg.ConfigHome, "ludo", utils.FileName(state.CorePath), utils.FileName(gamePath)+".disk.toml")
+}
+
+// canRecordDisk tells if the core lets us restore the last used disk image.
+// It needs both set_initial_image and get_image_path.
+func canRecordDisk() bool {
+	if state.Core == nil || state.Core.DiskControlCallback == nil {
+		return false
+	}
+	dcc := state.Core.DiskControlCallback
+	return dcc.SetInitialImage != nil && dcc.GetImagePath != nil
+}
+
+// setInitialDisk tells the core which disk image to start on, so resuming a
+// multi-disk game comes back on the last used disk. It must be called before
+// loading the game.
+func setInitialDisk(gamePath string) {
+	if !canRecordDisk() {
+		return
+	}
+	b, err := ioutil.ReadFile(diskPath(gamePath))
+	if err != nil {
+		return
+	}
+	var r diskRecord
+	if err := toml.Unmarshal(b, &r); err != nil {
+		return
+	}
+	state.Core.DiskControlCallback.SetInitialImage(r.Index, r.Path)
+}
+
+// SaveDisk records the current disk image of the game
+func SaveDisk() error {
+	if !canRecordDisk() || !state.CoreRunning {
+		return nil
+	}
+	dcc := state.Core.DiskControlCallback
+	r := diskRecord{Index: dcc.GetImageIndex()}
+	r.Path, _ = dcc.GetImagePath(r.Index)
+
+	b, err := toml.Marshal(r)
+	if err != nil {
+		return err
+	}
+	path := diskPath(state.GamePath)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
+
+// SwitchDisk ejects the current disk image, selects another one, and inserts
+// it back if the tray was closed
+func SwitchDisk(index uint) error {
+	dcc := state.Core.DiskControlCallback
+	ejected := dcc.GetEjectState()
+	if !ejected {
+		dcc.SetEjectState(true)
+	}
+	dcc.SetImageIndex(index)
+	if !ejected {
+		dcc.SetEjectState(false)
+	}
+	return SaveDisk()
+}
+
+// AppendDisk adds a disk image at the end of the disk list of the core
+func AppendDisk(path string) error {
+	dcc := state.Core.DiskControlCallback
+	if dcc == nil || dcc.AddImageIndex == nil || dcc.ReplaceImageIndex == nil {
+		txtI18n := l10n.T9(&i18n.Message{ID: "DiskAppendUnsupported", Other: "the core can't append disk images"})
+		return errors.New(txtI18n)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if !dcc.AddImageIndex() {
+		txtI18n := l10n.T9(&i18n.Message{ID: "DiskAppendFailed", Other: "failed to append the disk image"})
+		return errors.New(txtI18n)
+	}
+	index := dcc.GetNumImages() - 1
+	if !dcc.ReplaceImageIndex(index, &libretro.GameInfo{Path: path, Size: fi.Size()}) {
+		txtI18n := l10n.T9(&i18n.Message{ID: "DiskAppendFailed", Other: "failed to append the disk image"})
+		return errors.New(txtI18n)
+	}
+	return nil
+}
+
+// DiskLabel returns the name of a disk image, as given by the core, or the
+// name of the image file
+func DiskLabel(index uint) string {
+	dcc := state.Core.DiskControlCallback
+	if dcc.GetImageLabel != nil {
+		if label, ok := dcc.GetImageLabel(index); ok && label != "" {
+			return label
+		}
+	}
+	if dcc.GetImagePath != nil {
+		if path, ok := dcc.GetImagePath(index); ok && path != "" {
+			return filepath.Base(path)
+		}
+	}
+	return ""
+}
@@ -7,10 +7,13 @@ import (
 	"time"
 	"unsafe"
 
+	"github.com/libretro/ludo/input"
 	"github.com/libretro/ludo/libretro"
 	"github.com/libretro/ludo/options"
+	"github.com/libretro/ludo/rumble"
 	"github.com/libretro/ludo/settings"
 	"github.com/libretro/ludo/state"
+	"github.com/libretro/ludo/vfs"
 )
 
 var logLevels = map[uint32]string{
@@ -85,7 +88,7 @@ func environmentSetVariables(data unsafe.Pointer) bool {
 	}
 
 	var err error
-	Options, err = options.New(pass)
+	Options, err = options.New(pass, nil)
 	if err != nil {
 		log.Println(err)
 		return false
@@ -103,7 +106,7 @@ func environmentSetCoreOptions(data unsafe.Pointer) bool {
 	}
 
 	var err error
-	Options, err = options.New(pass)
+	Options, err = options.New(pass, nil)
 	if err != nil {
 		log.Println(err)
 		return false
@@ -121,7 +124,7 @@ func environmentSetCoreOptionsIntl(data unsafe.Pointer) bool {
 	}
 
 	var err error
-	Options, err = options.New(pass)
+	Options, err = options.New(pass, nil)
 	if err != nil {
 		log.Println(err)
 		return false
@@ -129,6 +132,38 @@ func environmentSetCoreOptionsIntl(data unsafe.Pointer) bool {
 	return true
 }
 
+// setCoreOptionsV2 caches the categories and definitions of the options v2
+func setCoreOptionsV2(categories []libretro.CoreOptionV2Category, definitions []libretro.CoreOptionV2Definition) bool {
+	cats := []options.Category{}
+	for _, c := range categories {
+		c := c
+		cats = append(cats, options.Category{Key: c.Key(), Desc: c.Desc(), Info: c.Info()})
+	}
+
+	pass := []options.VariableInterface{}
+	for _, cod := range definitions {
+		cod := cod
+		pass = append(pass, &cod)
+	}
+
+	var err error
+	Options, err = options.New(pass, cats)
+	if err != nil {
+		log.Println(err)
+	}
+	// We display the categories
+	return true
+}
+
+func environmentSetCoreOptionsDisplay(data unsafe.Pointer) bool {
+	if Options == nil {
+		return false
+	}
+	key, visible := libretro.GetCoreOptionDisplay(data)
+	Options.SetVisible(key, visible)
+	return true
+}
+
 func environment(cmd uint32, data unsafe.Pointer) bool {
 	switch cmd {
 	case libretro.EnvironmentSetRotation:
@@ -152,13 +187,22 @@ func environment(cmd uint32, data unsafe.Pointer) bool {
 	case libretro.EnvironmentGetSaveDirectory:
 		return environmentGetSaveDirectory(data)
 	case libretro.EnvironmentShutdown:
+		shutdownRequested = true
 		vid.SetShouldClose(true)
 	case libretro.EnvironmentGetCoreOptionsVersion:
-		libretro.SetUint(data, 1)
+		libretro.SetUint(data, 2)
 	case libretro.EnvironmentSetCoreOptions:
 		return environmentSetCoreOptions(data)
 	case libretro.EnvironmentSetCoreOptionsIntl:
 		return environmentSetCoreOptionsIntl(data)
+	case libretro.EnvironmentSetCoreOptionsV2:
+		return setCoreOptionsV2(libretro.GetCoreOptionsV2(data))
+	case libretro.EnvironmentSetCoreOptionsV2Intl:
+		return setCoreOptionsV2(libretro.GetCoreOptionsV2Intl(data))
+	case libretro.EnvironmentSetCoreOptionsDisplay:
+		return environmentSetCoreOptionsDisplay(data)
+	case libretro.EnvironmentSetCoreOptionsUpdateDisplayCb:
+		state.Core.SetCoreOptionsUpdateDisplayCallback(data)
 	case libretro.EnvironmentGetVariable:
 		return environmentGetVariable(data)
 	case libretro.EnvironmentSetVariables:
@@ -173,14 +217,40 @@ func environment(cmd uint32, data unsafe.Pointer) bool {
 	case libretro.EnvironmentSetSystemAVInfo:
 		avi := libretro.GetSystemAVInfo(data)
 		vid.Geom = avi.Geometry
+	case libretro.EnvironmentSetSerializationQuirks:
+		state.Core.SetSerializationQuirks(data)
+	case libretro.EnvironmentSetSubsystemInfo:
+		state.Core.Subsystems = libretro.GetSubsystemInfo(data)
+	case libretro.EnvironmentSetInputDescriptors:
+		state.Core.InputDescriptors = libretro.GetInputDescriptors(data)
+	case libretro.EnvironmentSetControllerInfo:
+		state.Core.Controllers = libretro.GetControllerInfo(data)
+	case libretro.EnvironmentGetInputDeviceCapabilities:
+		libretro.SetUint64(data, input.Capabilities())
+	case libretro.EnvironmentSetSupportAchievements:
+		state.Core.SupportsAchievements = libretro.GetBool(data)
 	case libretro.EnvironmentGetFastforwarding:
 		libretro.SetBool(data, state.FastForward)
 	case libretro.EnvironmentGetLanguage:
 		libretro.SetUint(data, 0)
+	case libretro.EnvironmentSetMessage:
+		showMessage(libretro.GetMessage(data, messageFPS))
+	case libretro.EnvironmentGetMessageInterfaceVersion:
+		libretro.SetUint(data, 1)
+	case libretro.EnvironmentSetMessageExt:
+		showMessage(libretro.GetMessageExt(data))
 	case libretro.EnvironmentGetDiskControlInterfaceVersion:
-		libretro.SetUint(data, 0)
+		libretro.SetUint(data, 1)
+	case libretro.EnvironmentGetRumbleInterface:
+		state.Core.BindRumbleInterface(data, rumble.SetState)
+	case libretro.EnvironmentSetKeyboardCallback:
+		state.Core.SetKeyboardCallback(data)
+	case libretro.EnvironmentGetVFSInterface:
+		return state.Core.BindVFSInterface(data, vfs.FS{})
 	case libretro.EnvironmentSetDiskControlInterface:
 		state.Core.SetDiskControlCallback(data)
+	case libretro.EnvironmentGetDiskControlExtInterface:
+		state.Core.SetDiskControlExtCallback(data)
 	default:
 		//log.Println("[Env]: Not implemented:", cmd)
 		return false
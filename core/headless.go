@@ -0,0 +1,32 @@
+package core
+
+import (
+	"github.com/libretro/ludo/state"
+)
+
+// shutdownRequested is set when the core asks the frontend to shut down
+// through the environment callback.
+var shutdownRequested bool
+
+// RunHeadless drives the loaded game without a window, a GL context or an
+// audio device. It runs at most frames frames, or forever if frames is 0, and
+// returns early when stop returns true or when the core requests a shutdown.
+// step runs a single frame, RunFrame is used when it is nil. stop can be nil.
+// It returns the number of frames that were run.
+func RunHeadless(frames int, step func(), stop func() bool) int {
+	if step == nil {
+		step = RunFrame
+	}
+	n := 0
+	for state.CoreRunning && !shutdownRequested {
+		if frames > 0 && n >= frames {
+			break
+		}
+		step()
+		n++
+		if stop != nil && stop() {
+			break
+		}
+	}
+	return n
+}
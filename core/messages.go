@@ -0,0 +1,74 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/libretro/ludo/libretro"
+	ntf "github.com/libretro/ludo/notifications"
+)
+
+// messageFPS converts the durations of legacy messages, given in frames.
+// Cores can send messages before the AV info is known, so we assume 60 FPS.
+const messageFPS = 60
+
+// progress is the notification updated in place by progress and status
+// messages, and the priority of the message it shows
+var progress struct {
+	n        *ntf.Notification
+	priority uint
+}
+
+var messageSeverities = map[uint32]ntf.Severity{
+	libretro.LogLevelDebug: ntf.Info,
+	libretro.LogLevelInfo:  ntf.Info,
+	libretro.LogLevelWarn:  ntf.Warning,
+	libretro.LogLevelError: ntf.Error,
+}
+
+// displayed tells if a notification is still on screen
+func displayed(n *ntf.Notification) bool {
+	for _, m := range ntf.List() {
+		if m == n {
+			return true
+		}
+	}
+	return false
+}
+
+// showMessage routes a core message to the log and the notifications
+func showMessage(m libretro.Message) {
+	if m.Target != libretro.MessageTargetOSD {
+		logCallback(m.Level, m.Msg)
+	}
+	if m.Target == libretro.MessageTargetLog {
+		return
+	}
+
+	duration := m.Duration
+	if duration <= 0 {
+		duration = ntf.Medium
+	}
+	severity := messageSeverities[m.Level]
+	msg := m.Msg
+	if m.Type == libretro.MessageTypeProgress && m.Progress >= 0 {
+		msg = fmt.Sprintf("%s (%d%%)", msg, m.Progress)
+	}
+
+	if m.Type != libretro.MessageTypeProgress && m.Type != libretro.MessageTypeStatus {
+		ntf.Display(severity, msg, duration)
+		return
+	}
+
+	// Progress updates replace each other instead of piling up
+	if progress.n != nil && displayed(progress.n) {
+		if m.Priority < progress.priority {
+			return
+		}
+		progress.n.Severity = severity
+		progress.n.Message = msg
+		progress.n.Duration = duration
+	} else {
+		progress.n = ntf.Display(severity, msg, duration)
+	}
+	progress.priority = m.Priority
+}
@@ -0,0 +1,39 @@
+package core
+
+import (
+	"unsafe"
+
+	"github.com/libretro/ludo/audio"
+	"github.com/libretro/ludo/state"
+)
+
+// setOutputs plugs the video and audio callbacks of the core either to the
+// video and audio packages, or to stubs discarding the frames. Audio is always
+// discarded in headless mode.
+func setOutputs(video, sound bool) {
+	if video {
+		state.Core.SetVideoRefresh(vid.Refresh)
+	} else {
+		state.Core.SetVideoRefresh(nullRefresh)
+	}
+
+	if sound && !state.Headless {
+		state.Core.SetAudioSample(audio.Sample)
+		state.Core.SetAudioSampleBatch(audio.SampleBatch)
+	} else {
+		state.Core.SetAudioSample(nullSample)
+		state.Core.SetAudioSampleBatch(nullSampleBatch)
+	}
+}
+
+// nullRefresh is a video refresh callback that discards the frame.
+func nullRefresh(data unsafe.Pointer, width int32, height int32, pitch int32) {}
+
+// nullSample is an audio sample callback that discards the sample.
+func nullSample(left int16, right int16) {}
+
+// nullSampleBatch is an audio sample batch callback that discards the samples,
+// it acknowledges all the frames as written.
+func nullSampleBatch(buf []byte, size int32) int32 {
+	return size * 4
+}
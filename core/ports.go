@@ -0,0 +1,102 @@
+package core
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/adrg/xdg"
+	"github.com/libretro/ludo/input"
+	"github.com/libretro/ludo/libretro"
+	"github.com/libretro/ludo/state"
+	"github.com/libretro/ludo/utils"
+	"github.com/pelletier/go-toml"
+
+	"github.com/libretro/ludo/l10n"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// portsPath returns the path of the file storing the device types selected
+// for the current game on the current core
+func portsPath() string {
+	return filepath.Join(
+		xdg.ConfigHome, "ludo",
+		utils.FileName(state.CorePath),
+		utils.FileName(state.GamePath)+".ports.toml")
+}
+
+// PortDevices returns the device types the core accepts on a port. A port
+// can always be left unplugged, and takes a RetroPad when the core didn't
+// declare its controllers.
+func PortDevices(port uint) []libretro.ControllerDescription {
+	tNone := l10n.T9(&i18n.Message{ID: "DeviceNone", Other: "None"})
+	devices := []libretro.ControllerDescription{
+		{Desc: tNone, ID: libretro.DeviceNone},
+	}
+	if state.Core != nil && int(port) < len(state.Core.Controllers) {
+		for _, d := range state.Core.Controllers[port] {
+			if d.ID != libretro.DeviceNone {
+				devices = append(devices, d)
+			}
+		}
+		return devices
+	}
+	return append(devices,
+		libretro.ControllerDescription{Desc: "RetroPad", ID: libretro.DeviceJoypad},
+		libretro.ControllerDescription{Desc: "RetroPad w/ Analog", ID: libretro.DeviceAnalog},
+	)
+}
+
+// SetPortDevice plugs a device type in a port, for both the core and the
+// input package
+func SetPortDevice(port uint, device uint32) {
+	input.SetDevice(port, device)
+	state.Core.SetControllerPortDevice(port, device)
+}
+
+// loadPorts plugs the device types saved for the game, or RetroPads
+func loadPorts() {
+	input.ResetDevices()
+
+	saved := map[string]uint32{}
+	if b, err := ioutil.ReadFile(portsPath()); err == nil {
+		toml.Unmarshal(b, &saved)
+	}
+
+	for port := uint(0); port < input.MaxPlayers; port++ {
+		device := libretro.DeviceJoypad
+		if d, ok := saved[strconv.Itoa(int(port))]; ok && acceptsDevice(port, d) {
+			device = d
+		}
+		SetPortDevice(port, device)
+	}
+}
+
+// acceptsDevice checks that a saved device type is still offered by the core
+func acceptsDevice(port uint, device uint32) bool {
+	for _, d := range PortDevices(port) {
+		if d.ID == device {
+			return true
+		}
+	}
+	return false
+}
+
+// SavePorts saves the device types plugged in each port for the current game
+func SavePorts() error {
+	m := map[string]uint32{}
+	for port := uint(0); port < input.MaxPlayers; port++ {
+		m[strconv.Itoa(int(port))] = input.Device(port)
+	}
+	b, err := toml.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	path := portsPath()
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
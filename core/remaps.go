@@ -0,0 +1,118 @@
+package core
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/adrg/xdg"
+	"github.com/libretro/ludo/input"
+	"github.com/libretro/ludo/state"
+	"github.com/libretro/ludo/utils"
+	"github.com/pelletier/go-toml"
+)
+
+// remapPath returns the path of the button remapping of the current core, or
+// of the current game when forGame is true
+func remapPath(forGame bool) string {
+	core := utils.FileName(state.CorePath)
+	if forGame {
+		return filepath.Join(xdg.ConfigHome, "ludo", "remaps", core, utils.FileName(state.GamePath)+".toml")
+	}
+	return filepath.Join(xdg.ConfigHome, "ludo", "remaps", core+".toml")
+}
+
+// buttonID returns the RetroPad button id matching a short name
+func buttonID(name string) (uint32, bool) {
+	for id, n := range input.ButtonNames {
+		if n == name {
+			return uint32(id), true
+		}
+	}
+	return 0, false
+}
+
+// readRemaps parses a remapping file. Only the remapped buttons are stored,
+// as tables of core button = RetroPad button per port.
+func readRemaps(path string) (input.Remaps, error) {
+	r := input.DefaultRemaps()
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return r, err
+	}
+
+	var m map[string]map[string]string
+	if err := toml.Unmarshal(b, &m); err != nil {
+		return r, err
+	}
+
+	for port := range r {
+		for k, v := range m[fmt.Sprintf("port%d", port+1)] {
+			id, ok1 := buttonID(k)
+			button, ok2 := buttonID(v)
+			if ok1 && ok2 {
+				r[port][id] = button
+			}
+		}
+	}
+	return r, nil
+}
+
+// loadRemaps applies the remapping of the game if any, or else the one of the
+// core
+func loadRemaps() {
+	if r, err := readRemaps(remapPath(true)); err == nil {
+		input.SetRemaps(r)
+		return
+	}
+	r, _ := readRemaps(remapPath(false))
+	input.SetRemaps(r)
+}
+
+// SaveRemaps saves the current button remapping for the current core, or for
+// the current game when forGame is true
+func SaveRemaps(forGame bool) error {
+	m := map[string]map[string]string{}
+	r := input.CurrentRemaps()
+	for port := range r {
+		for id, button := range r[port] {
+			if uint32(id) == button {
+				continue
+			}
+			key := fmt.Sprintf("port%d", port+1)
+			if m[key] == nil {
+				m[key] = map[string]string{}
+			}
+			m[key][input.ButtonNames[id]] = input.ButtonNames[button]
+		}
+	}
+
+	b, err := toml.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	path := remapPath(forGame)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
+
+// GameRemapsExist tells if the current game overrides the remapping of the core
+func GameRemapsExist() bool {
+	_, err := os.Stat(remapPath(true))
+	return err == nil
+}
+
+// RemoveGameRemaps deletes the remapping of the current game, and falls back
+// to the remapping of the core
+func RemoveGameRemaps() error {
+	if err := os.Remove(remapPath(true)); err != nil {
+		return err
+	}
+	loadRemaps()
+	return nil
+}
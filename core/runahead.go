@@ -0,0 +1,71 @@
+package core
+
+import (
+	"github.com/libretro/ludo/libretro"
+	ntf "github.com/libretro/ludo/notifications"
+	"github.com/libretro/ludo/settings"
+	"github.com/libretro/ludo/state"
+
+	"github.com/libretro/ludo/l10n"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// runAheadDisabled is set when run-ahead can't be used with the current core
+var runAheadDisabled bool
+
+// runAheadEnabled checks the settings and the serialization capabilities of
+// the core. It turns run-ahead off for cores that report incomplete savestates.
+func runAheadEnabled() bool {
+	if settings.Current.RunAheadFrames <= 0 || runAheadDisabled {
+		return false
+	}
+	if state.Core.SerializationQuirks&libretro.SerializationQuirkIncomplete != 0 {
+		disableRunAhead(l10n.T9(&i18n.Message{ID: "SerializationNotDeterministic", Other: "savestates are not deterministic"}))
+		return false
+	}
+	if state.Core.SerializeSize() == 0 {
+		disableRunAhead(l10n.T9(&i18n.Message{ID: "SerializationNotSupported", Other: "savestates are not supported"}))
+		return false
+	}
+	return true
+}
+
+// disableRunAhead turns run-ahead off until another core is loaded
+func disableRunAhead(reason string) {
+	runAheadDisabled = true
+	txtI18n := l10n.T9(&i18n.Message{ID: "RunAheadDisabled", Other: "Run-ahead disabled: %s"})
+	ntf.DisplayAndLog(ntf.Warning, "Core", txtI18n, reason)
+}
+
+// runAhead runs the current frame with the audio on and saves the state, then
+// runs the next frames without output, and displays the last one before
+// restoring the saved state. This hides frames of input lag for games that
+// react to input late.
+func runAhead(frames int) {
+	defer setOutputs(true, true)
+
+	setOutputs(false, true)
+	runOnce()
+
+	s := state.Core.SerializeSize()
+	bytes, err := state.Core.Serialize(s)
+	if err != nil {
+		// Some cores can't serialize before their first frames
+		if state.Core.SerializationQuirks&libretro.SerializationQuirkMustInitialize == 0 {
+			disableRunAhead(err.Error())
+		}
+		return
+	}
+
+	setOutputs(false, false)
+	for i := 1; i < frames; i++ {
+		runOnce()
+	}
+
+	setOutputs(true, false)
+	runOnce()
+
+	if err := state.Core.Unserialize(bytes, s); err != nil {
+		disableRunAhead(err.Error())
+	}
+}
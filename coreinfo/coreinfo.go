@@ -0,0 +1,203 @@
+// Package coreinfo reads the libretro .info files describing the cores: their
+// display name, the file extensions they support, the databases of the
+// systems they emulate and the firmware they need.
+package coreinfo
+
+import (
+	"bufio"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/libretro/ludo/settings"
+)
+
+// Firmware is a BIOS or a system file needed by a core
+type Firmware struct {
+	Desc     string
+	Path     string // Relative to the system directory
+	Optional bool
+	MD5      string // Lower case, empty if unknown
+}
+
+// Info describes a core
+type Info struct {
+	Name        string // File name of the core, without extension
+	DisplayName string
+	CoreName    string
+	SystemName  string
+	Extensions  []string // Without the leading dot
+	Databases   []string
+	Firmware    []Firmware
+	Cheats      bool // the core applies the codes passed to retro_cheat_set
+}
+
+// FirmwareStatus tells if a firmware file is usable
+type FirmwareStatus int
+
+const (
+	// FirmwareMissing is for files not found in the system directory
+	FirmwareMissing FirmwareStatus = iota
+	// FirmwareMismatch is for files with an unexpected checksum
+	FirmwareMismatch
+	// FirmwareFound is for files found with the expected checksum, or whose
+	// checksum is unknown
+	FirmwareFound
+)
+
+// Infos is the core info database, indexed by core file name without extension
+var Infos = map[string]Info{}
+
+// notesMD5 matches the checksums listed in the notes of the .info files, like
+// (!) scph5501.bin (md5): 490f666e1afb15b7362b406ed1cea246
+var notesMD5 = regexp.MustCompile(`\(!\)\s*(.+?)\s*\(md5\):\s*([0-9a-fA-F]{32})`)
+
+// splitList splits the lists of the .info files, separated by pipes
+func splitList(s string) []string {
+	var list []string
+	for _, e := range strings.Split(s, "|") {
+		if e = strings.TrimSpace(e); e != "" {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+// Parse reads a .info file
+func Parse(r io.Reader) (Info, error) {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+		if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return Info{}, err
+	}
+
+	info := Info{
+		DisplayName: values["display_name"],
+		CoreName:    values["corename"],
+		SystemName:  values["systemname"],
+		Extensions:  splitList(values["supported_extensions"]),
+		Databases:   splitList(values["database"]),
+		Cheats:      values["cheats"] == "true",
+	}
+
+	md5s := map[string]string{}
+	for _, m := range notesMD5.FindAllStringSubmatch(values["notes"], -1) {
+		md5s[m[1]] = strings.ToLower(m[2])
+	}
+
+	count, _ := strconv.Atoi(values["firmware_count"])
+	for i := 0; i < count; i++ {
+		prefix := "firmware" + strconv.Itoa(i) + "_"
+		fw := Firmware{
+			Desc:     values[prefix+"desc"],
+			Path:     values[prefix+"path"],
+			Optional: values[prefix+"opt"] == "true",
+		}
+		if fw.Path == "" {
+			continue
+		}
+		if sum, ok := md5s[fw.Path]; ok {
+			fw.MD5 = sum
+		} else {
+			fw.MD5 = md5s[filepath.Base(fw.Path)]
+		}
+		info.Firmware = append(info.Firmware, fw)
+	}
+
+	return info, nil
+}
+
+// Load reads the .info files of the info directory into Infos
+func Load() {
+	Infos = map[string]Info{}
+	paths, err := filepath.Glob(filepath.Join(settings.Current.InfoDirectory, "*.info"))
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		info, err := Parse(f)
+		f.Close()
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		info.Name = strings.TrimSuffix(filepath.Base(path), ".info")
+		Infos[info.Name] = info
+	}
+}
+
+// Get returns the info of a core, given its path or its file name
+func Get(core string) (Info, bool) {
+	name := strings.TrimSuffix(filepath.Base(core), filepath.Ext(core))
+	info, ok := Infos[name]
+	return info, ok
+}
+
+// DisplayName returns the human readable name of a core, from its info file or
+// the names of the cores Ludo ships with, or its file name if the core is
+// unknown
+func DisplayName(core string) string {
+	if info, ok := Get(core); ok && info.DisplayName != "" {
+		return info.DisplayName
+	}
+	if name, ok := fallbackNames[strings.TrimSuffix(filepath.Base(core), filepath.Ext(core))]; ok {
+		return name
+	}
+	return core
+}
+
+// ExtensionsWithDot returns the extensions supported by the core, with a
+// leading dot as returned by filepath.Ext
+func (info Info) ExtensionsWithDot() []string {
+	exts := make([]string, len(info.Extensions))
+	for i, ext := range info.Extensions {
+		exts[i] = "." + ext
+	}
+	return exts
+}
+
+// Status checks if a firmware file is present in the system directory, with
+// the expected checksum
+func (fw Firmware) Status(systemDir string) FirmwareStatus {
+	b, err := ioutil.ReadFile(filepath.Join(systemDir, fw.Path))
+	if err != nil {
+		return FirmwareMissing
+	}
+	if fw.MD5 == "" {
+		return FirmwareFound
+	}
+	sum := md5.Sum(b)
+	if hex.EncodeToString(sum[:]) != fw.MD5 {
+		return FirmwareMismatch
+	}
+	return FirmwareFound
+}
@@ -0,0 +1,131 @@
+package coreinfo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/libretro/ludo/settings"
+)
+
+const beetlePSX = `# Software Information
+display_name = "Sony - PlayStation (Beetle PSX)"
+authors = "Mednafen Team"
+supported_extensions = "exe|cue|toc|ccd|m3u|pbp|chd"
+corename = "Beetle PSX"
+
+# Hardware Information
+manufacturer = "Sony"
+systemname = "PlayStation"
+database = "Sony - PlayStation"
+cheats = "true"
+
+firmware_count = 2
+firmware0_desc = "scph5500.bin (PS1 JP BIOS)"
+firmware0_path = "scph5500.bin"
+firmware0_opt = "false"
+firmware1_desc = "scph5501.bin (PS1 US BIOS)"
+firmware1_path = "scph5501.bin"
+firmware1_opt = "true"
+notes = "(!) scph5500.bin (md5): 8dd7d5296a650fac7319bce665a6a53c|(!) scph5501.bin (md5): 490f666e1afb15b7362b406ed1cea246"
+`
+
+func Test_Parse(t *testing.T) {
+	info, err := Parse(strings.NewReader(beetlePSX))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Reads the names", func(t *testing.T) {
+		if info.DisplayName != "Sony - PlayStation (Beetle PSX)" {
+			t.Errorf("got = %v, want %v", info.DisplayName, "Sony - PlayStation (Beetle PSX)")
+		}
+		if !reflect.DeepEqual(info.Databases, []string{"Sony - PlayStation"}) {
+			t.Errorf("got = %v, want %v", info.Databases, []string{"Sony - PlayStation"})
+		}
+	})
+
+	t.Run("Reads the cheat support", func(t *testing.T) {
+		if !info.Cheats {
+			t.Errorf("got = %v, want %v", info.Cheats, true)
+		}
+	})
+
+	t.Run("Splits the extensions", func(t *testing.T) {
+		want := []string{".exe", ".cue", ".toc", ".ccd", ".m3u", ".pbp", ".chd"}
+		if got := info.ExtensionsWithDot(); !reflect.DeepEqual(got, want) {
+			t.Errorf("got = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Takes the firmware checksums from the notes", func(t *testing.T) {
+		want := []Firmware{
+			{Desc: "scph5500.bin (PS1 JP BIOS)", Path: "scph5500.bin", MD5: "8dd7d5296a650fac7319bce665a6a53c"},
+			{Desc: "scph5501.bin (PS1 US BIOS)", Path: "scph5501.bin", Optional: true, MD5: "490f666e1afb15b7362b406ed1cea246"},
+		}
+		if !reflect.DeepEqual(info.Firmware, want) {
+			t.Errorf("got = %v, want %v", info.Firmware, want)
+		}
+	})
+}
+
+func Test_Load(t *testing.T) {
+	dir := t.TempDir()
+	saved := settings.Current.InfoDirectory
+	settings.Current.InfoDirectory = dir
+	defer func() {
+		settings.Current.InfoDirectory = saved
+		Infos = map[string]Info{}
+	}()
+
+	err := ioutil.WriteFile(filepath.Join(dir, "mednafen_psx_libretro.info"), []byte(beetlePSX), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Load()
+
+	t.Run("Prettifies known cores", func(t *testing.T) {
+		got := DisplayName("cores/mednafen_psx_libretro.so")
+		if got != "Sony - PlayStation (Beetle PSX)" {
+			t.Errorf("got = %v, want %v", got, "Sony - PlayStation (Beetle PSX)")
+		}
+	})
+
+	t.Run("Falls back to the names of the shipped cores", func(t *testing.T) {
+		if got := DisplayName("snes9x_libretro"); got != "Nintendo - SNES / SFC (Snes9x - Current)" {
+			t.Errorf("got = %v, want %v", got, "Nintendo - SNES / SFC (Snes9x - Current)")
+		}
+	})
+
+	t.Run("Keeps the name of unknown cores", func(t *testing.T) {
+		if got := DisplayName("foo_libretro"); got != "foo_libretro" {
+			t.Errorf("got = %v, want %v", got, "foo_libretro")
+		}
+	})
+}
+
+func Test_Status(t *testing.T) {
+	dir := t.TempDir()
+	fw := Firmware{Path: "bios.bin", MD5: "5d41402abc4b2a76b9719d911017c592"} // md5 of "hello"
+
+	if got := fw.Status(dir); got != FirmwareMissing {
+		t.Errorf("got = %v, want %v", got, FirmwareMissing)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "bios.bin"), []byte("world"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if got := fw.Status(dir); got != FirmwareMismatch {
+		t.Errorf("got = %v, want %v", got, FirmwareMismatch)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "bios.bin"), []byte("hello"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if got := fw.Status(dir); got != FirmwareFound {
+		t.Errorf("got = %v, want %v", got, FirmwareFound)
+	}
+}
@@ -0,0 +1,39 @@
+package coreinfo
+
+// fallbackNames are the human readable names of the cores Ludo ships with,
+// used when their info file is missing, as in source builds
+var fallbackNames = map[string]string{
+	"atari800_libretro":            "Atari - 5200 (Atari800)",
+	"bluemsx_libretro":             "MSX/SVI/ColecoVision/SG-1000 (blueMSX)",
+	"fbneo_libretro":               "Arcade (FinalBurn Neo)",
+	"fceumm_libretro":              "Nintendo - NES / Famicom (FCEUmm)",
+	"gambatte_libretro":            "Nintendo - Game Boy / Color (Gambatte)",
+	"gearsystem_libretro":          "Sega - MS/GG/SG-1000 (Gearsystem)",
+	"genesis_plus_gx_libretro":     "Sega - MS/GG/MD/CD (Genesis Plus GX)",
+	"handy_libretro":               "Atari - Lynx (Handy)",
+	"lutro_libretro":               "Lua Engine (Lutro)",
+	"mednafen_ngp_libretro":        "SNK - Neo Geo Pocket / Color (Beetle NeoPop)",
+	"mednafen_pce_fast_libretro":   "NEC - PC Engine / CD (Beetle PCE FAST)",
+	"mednafen_pce_libretro":        "NEC - PC Engine / SuperGrafx / CD (Beetle PCE)",
+	"mednafen_pcfx_libretro":       "NEC - PC-FX (Beetle PC-FX)",
+	"mednafen_psx_libretro":        "Sony - PlayStation (Beetle PSX)",
+	"mednafen_saturn_libretro":     "Sega - Saturn (Beetle Saturn)",
+	"mednafen_supergrafx_libretro": "NEC - PC Engine SuperGrafx (Beetle SuperGrafx)",
+	"mednafen_vb_libretro":         "Nintendo - Virtual Boy (Beetle VB)",
+	"mednafen_wswan_libretro":      "Bandai - WonderSwan/Color (Beetle Cygne)",
+	"melonds_libretro":             "Nintendo - DS (melonDS)",
+	"mgba_libretro":                "Nintendo - Game Boy Advance (mGBA)",
+	"mupen64plus_next_libretro":    "Nintendo - Nintendo 64 (Mupen64Plus-Next)",
+	"np2kai_libretro":              "NEC - PC-98 (Neko Project II Kai)",
+	"o2em_libretro":                "Magnavox - Odyssey2 / Phillips Videopac+ (O2EM)",
+	"pcsx_rearmed_libretro":        "Sony - PlayStation (PCSX ReARMed)",
+	"picodrive_libretro":           "Sega - MS/GG/MD/CD/32X (PicoDrive)",
+	"pokemini_libretro":            "Nintendo - Pokemon Mini (PokeMini)",
+	"prosystem_libretro":           "Atari - 7800 (ProSystem)",
+	"sameboy_libretro":             "Nintendo - Game Boy / Color (SameBoy)",
+	"snes9x_libretro":              "Nintendo - SNES / SFC (Snes9x - Current)",
+	"stella2014_libretro":          "Atari - 2600 (Stella 2014)",
+	"swanstation_libretro":         "Sony - PlayStation (SwanStation)",
+	"vecx_libretro":                "GCE - Vectrex (vecx)",
+	"virtualjaguar_libretro":       "Atari - Jaguar (Virtual Jaguar)",
+}
@@ -0,0 +1,163 @@
+// Package firmware verifies the BIOS and firmware files of the system
+// directory against a registry of known good dumps.
+package firmware
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+
+	"github.com/libretro/ludo/coreinfo"
+	"github.com/libretro/ludo/settings"
+)
+
+// File is a known good dump of a firmware file
+type File struct {
+	System string
+	Name   string // Relative to the system directory
+	Size   int64
+	MD5    string
+	SHA1   string // Empty if unknown
+}
+
+// Status tells if a firmware file is usable
+type Status int
+
+const (
+	// Missing is for files not found in the system directory
+	Missing Status = iota
+	// BadHash is for files whose size or checksums match no known dump
+	BadHash
+	// Present is for files matching a known dump, or files unknown to the
+	// registry
+	Present
+)
+
+// Registry lists the known dumps, per system. A file can have several good
+// dumps, like the revisions of a BIOS.
+var Registry = []File{
+	{System: "Atari - 5200", Name: "5200.rom", Size: 2048, MD5: "281f20ea4320404ec820fb7ec0693b38"},
+	{System: "Atari - 7800", Name: "7800 BIOS (U).rom", Size: 4096, MD5: "0763f1ffb006ddbe32e52d497ee848ae"},
+	{System: "Atari - Lynx", Name: "lynxboot.img", Size: 512, MD5: "fcd403db69f54290b51035d82f835e7b"},
+	{System: "NEC - PC Engine CD - TurboGrafx-CD", Name: "syscard3.pce", Size: 262144, MD5: "38179df8f4ac870017db21ebcbf53114"},
+	{System: "NEC - PC-FX", Name: "pcfx.rom", Size: 1048576, MD5: "08e36edbea28a017f79f8d4f7ff9b6d7"},
+	{System: "Nintendo - Family Computer Disk System", Name: "disksys.rom", Size: 8192, MD5: "ca30b50f880eb660a320674ed365ef7a"},
+	{System: "Nintendo - Game Boy", Name: "gb_bios.bin", Size: 256, MD5: "32fbbd84168d3482956eb3c5051637f5"},
+	{System: "Nintendo - Game Boy Color", Name: "gbc_bios.bin", Size: 2304, MD5: "dbfce9db9deaa2567f6a84fde55f9680"},
+	{System: "Nintendo - Game Boy Advance", Name: "gba_bios.bin", Size: 16384, MD5: "a860e8c0b6d573d191e4ec7db1b1e4f6", SHA1: "300c20df6731a33952ded8c436f7f186d25d3492"},
+	{System: "Nintendo - Nintendo DS", Name: "bios7.bin", Size: 16384, MD5: "df692a80a5b1bc90728bc3dfc76cd948"},
+	{System: "Nintendo - Nintendo DS", Name: "bios9.bin", Size: 4096, MD5: "a392174eb3e572fed6447e956bde4b25"},
+	{System: "Nintendo - Pokemon Mini", Name: "bios.min", Size: 4096, MD5: "1e4fb124a3a886865acb574f388c803d"},
+	{System: "Nintendo - Super Nintendo Entertainment System", Name: "sgb_bios.bin", Size: 256, MD5: "d574d4f9c12f305074798f54c091a8b4"},
+	{System: "Sega - Mega-CD - Sega CD", Name: "bios_CD_E.bin", Size: 131072, MD5: "e66fa1dc5820d254611fdcdba0662372"},
+	{System: "Sega - Mega-CD - Sega CD", Name: "bios_CD_J.bin", Size: 131072, MD5: "278a9397d192149e84e820ac621a8edd"},
+	{System: "Sega - Mega-CD - Sega CD", Name: "bios_CD_U.bin", Size: 131072, MD5: "2efd74e3232ff260e371b99f84024f7f"},
+	{System: "Sega - Saturn", Name: "mpr-17933.bin", Size: 524288, MD5: "3240872c70984b6cbfda1586cab68dbe"},
+	{System: "Sega - Saturn", Name: "sega_101.bin", Size: 524288, MD5: "85ec9ca47d8f6807718151cbcca8b964"},
+	{System: "Sony - PlayStation", Name: "scph5500.bin", Size: 524288, MD5: "8dd7d5296a650fac7319bce665a6a53c", SHA1: "b05def971d8ec59f346f2d9ac21fb742e3eb6917"},
+	{System: "Sony - PlayStation", Name: "scph5501.bin", Size: 524288, MD5: "490f666e1afb15b7362b406ed1cea246", SHA1: "0555c6fae8906f3f09baf5988f00e55f88e9f30b"},
+	{System: "Sony - PlayStation", Name: "scph5502.bin", Size: 524288, MD5: "32736f17079d0b2b7024407c39bd3050", SHA1: "f6bc2d1f5eb6593de7d089c425ac681d6fffd3f0"},
+}
+
+// Results of the last scan of the system directory, indexed by file name
+var (
+	results = map[string]Status{}
+	mutex   sync.Mutex
+)
+
+// known returns the dumps of the registry for a file name
+func known(name string) []File {
+	var files []File
+	for _, f := range Registry {
+		if f.Name == name {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// matches tells if some content is one of the known dumps
+func matches(b []byte, dumps []File) bool {
+	md5Sum := md5.Sum(b)
+	sha1Sum := sha1.Sum(b)
+	for _, d := range dumps {
+		if d.Size != 0 && d.Size != int64(len(b)) {
+			continue
+		}
+		if d.MD5 != "" && d.MD5 != hex.EncodeToString(md5Sum[:]) {
+			continue
+		}
+		if d.SHA1 != "" && d.SHA1 != hex.EncodeToString(sha1Sum[:]) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// Verify checks a file of a system directory against the registry
+func Verify(dir, name string) Status {
+	b, err := ioutil.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return Missing
+	}
+	dumps := known(name)
+	if len(dumps) == 0 || matches(b, dumps) {
+		return Present
+	}
+	return BadHash
+}
+
+// Scan verifies the files of the registry found in the system directory
+func Scan() {
+	r := map[string]Status{}
+	for _, f := range Registry {
+		if _, ok := r[f.Name]; !ok {
+			r[f.Name] = Verify(settings.Current.SystemDirectory, f.Name)
+		}
+	}
+	mutex.Lock()
+	results = r
+	mutex.Unlock()
+}
+
+// Result returns the status of a file of the registry found by the last scan
+func Result(name string) Status {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return results[name]
+}
+
+// StatusOf checks a firmware file listed in a core info file. The registry
+// is used when it knows the file, the checksum of the info file otherwise.
+func StatusOf(fw coreinfo.Firmware) Status {
+	if len(known(fw.Path)) > 0 {
+		return Verify(settings.Current.SystemDirectory, fw.Path)
+	}
+	switch fw.Status(settings.Current.SystemDirectory) {
+	case coreinfo.FirmwareFound:
+		return Present
+	case coreinfo.FirmwareMismatch:
+		return BadHash
+	}
+	return Missing
+}
+
+// MissingFor lists the required firmware files of a core that are missing or
+// don't match a known dump
+func MissingFor(corePath string) []string {
+	info, ok := coreinfo.Get(corePath)
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, fw := range info.Firmware {
+		if !fw.Optional && StatusOf(fw) != Present {
+			names = append(names, fw.Path)
+		}
+	}
+	return names
+}
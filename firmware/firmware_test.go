@@ -0,0 +1,79 @@
+package firmware
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/libretro/ludo/coreinfo"
+	"github.com/libretro/ludo/settings"
+)
+
+func Test_Verify(t *testing.T) {
+	dir := t.TempDir()
+	saved := Registry
+	defer func() { Registry = saved }()
+	Registry = []File{
+		{System: "Test", Name: "bios.bin", Size: 5, MD5: "5d41402abc4b2a76b9719d911017c592", SHA1: "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d"},
+		{System: "Test", Name: "bios.bin", Size: 5, MD5: "7d793037a0760186574b0282f2f435e7"},
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    Status
+	}{
+		{"Finds the first known dump", "hello", Present},
+		{"Finds another known dump", "world", Present},
+		{"Rejects a bad dump", "hellp", BadHash},
+		{"Rejects a file with a wrong size", "hello world", BadHash},
+	}
+	if got := Verify(dir, "bios.bin"); got != Missing {
+		t.Errorf("got = %v, want %v", got, Missing)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ioutil.WriteFile(filepath.Join(dir, "bios.bin"), []byte(tt.content), os.ModePerm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Verify(dir, "bios.bin"); got != tt.want {
+				t.Errorf("got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_MissingFor(t *testing.T) {
+	dir := t.TempDir()
+	savedDir := settings.Current.SystemDirectory
+	settings.Current.SystemDirectory = dir
+	defer func() {
+		settings.Current.SystemDirectory = savedDir
+		coreinfo.Infos = map[string]coreinfo.Info{}
+	}()
+
+	coreinfo.Infos = map[string]coreinfo.Info{
+		"mednafen_psx_libretro": {Firmware: []coreinfo.Firmware{
+			{Path: "scph5500.bin"},
+			{Path: "scph5501.bin", Optional: true},
+			{Path: "unknown.bin"},
+		}},
+	}
+	err := ioutil.WriteFile(filepath.Join(dir, "unknown.bin"), []byte("anything"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := MissingFor("cores/mednafen_psx_libretro.so")
+	want := []string{"scph5500.bin"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got = %v, want %v", got, want)
+	}
+
+	if got := MissingFor("cores/snes9x_libretro.so"); got != nil {
+		t.Errorf("got = %v, want %v", got, nil)
+	}
+}
@@ -27,8 +27,22 @@ type History []Game
 // List is the list of recently played games
 var List History
 
-// Push pushes a game onto the stack
+// Find returns the history entry of a game
+func Find(path string) (Game, bool) {
+	for _, g := range List {
+		if g.Path == path {
+			return g, true
+		}
+	}
+	return Game{}, false
+}
+
+// Push pushes a game onto the stack. The savestate of an earlier entry of the
+// same game is kept if the new entry has none.
 func Push(g Game) {
+	if old, ok := Find(g.Path); ok && g.Savestate == "" {
+		g.Savestate = old.Savestate
+	}
 	List = append([]Game{g}, List...)
 
 	// Deduplicate
@@ -48,6 +62,17 @@ func Push(g Game) {
 	}
 }
 
+// SetSavestate records the last savestate of a game of the history
+func SetSavestate(path, savestate string) error {
+	for i := range List {
+		if List[i].Path == path {
+			List[i].Savestate = savestate
+			return Save()
+		}
+	}
+	return nil
+}
+
 // Load loads history.csv in memory
 func Load() error {
 	file, err := os.Open(filepath.Join(xdg.DataHome, "ludo", "history.csv"))
@@ -57,6 +82,7 @@ func Load() error {
 	defer file.Close()
 
 	wr := csv.NewReader(bufio.NewReader(file))
+	wr.FieldsPerRecord = -1 // older history files have no savestate column
 
 	List = History{}
 	for {
@@ -67,12 +93,19 @@ func Load() error {
 		if err != nil {
 			return err
 		}
-		List = append(List, Game{
+		if len(record) < 4 {
+			continue
+		}
+		g := Game{
 			Path:     record[0],
 			Name:     record[1],
 			System:   record[2],
 			CorePath: record[3],
-		})
+		}
+		if len(record) > 4 {
+			g.Savestate = record[4]
+		}
+		List = append(List, g)
 	}
 
 	return nil
@@ -95,6 +128,7 @@ func Save() error {
 			game.Name,
 			game.System,
 			game.CorePath,
+			game.Savestate,
 		})
 	}
 
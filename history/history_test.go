@@ -0,0 +1,54 @@
+package history
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/adrg/xdg"
+)
+
+func Test_SaveLoad(t *testing.T) {
+	saved := xdg.DataHome
+	defer func() { xdg.DataHome = saved; List = nil }()
+	xdg.DataHome = t.TempDir()
+	if err := os.MkdirAll(filepath.Join(xdg.DataHome, "ludo"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Reads history files without savestate column", func(t *testing.T) {
+		csv := "/roms/Tetris.gb,Tetris,Nintendo - Game Boy,/cores/gambatte_libretro.so\n"
+		if err := ioutil.WriteFile(filepath.Join(xdg.DataHome, "ludo", "history.csv"), []byte(csv), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := Load(); err != nil {
+			t.Fatal(err)
+		}
+		want := History{{Path: "/roms/Tetris.gb", Name: "Tetris", System: "Nintendo - Game Boy", CorePath: "/cores/gambatte_libretro.so"}}
+		if !reflect.DeepEqual(List, want) {
+			t.Errorf("got = %v, want %v", List, want)
+		}
+	})
+
+	t.Run("Persists the savestate", func(t *testing.T) {
+		if err := SetSavestate("/roms/Tetris.gb", "/states/Tetris@auto.state"); err != nil {
+			t.Fatal(err)
+		}
+		List = nil
+		if err := Load(); err != nil {
+			t.Fatal(err)
+		}
+		if g, _ := Find("/roms/Tetris.gb"); g.Savestate != "/states/Tetris@auto.state" {
+			t.Errorf("got = %v, want %v", g.Savestate, "/states/Tetris@auto.state")
+		}
+	})
+
+	t.Run("Push keeps the savestate of the game", func(t *testing.T) {
+		Push(Game{Path: "/roms/Tetris.gb", Name: "Tetris"})
+		if len(List) != 1 || List[0].Savestate != "/states/Tetris@auto.state" {
+			t.Errorf("got = %v, want the savestate to be kept", List)
+		}
+	})
+}
@@ -19,7 +19,9 @@ var keyBinds = map[glfw.Key]uint32{
 	glfw.KeyEnter:      libretro.DeviceIDJoypadStart,
 	glfw.KeyRightShift: libretro.DeviceIDJoypadSelect,
 	glfw.KeySpace:      ActionFastForwardToggle,
+	glfw.KeyR:          ActionRewind,
 	glfw.KeyP:          ActionMenuToggle,
 	glfw.KeyF:          ActionFullscreenToggle,
 	glfw.KeyEscape:     ActionShouldClose,
+	glfw.KeyScrollLock: ActionGameFocusToggle,
 }
@@ -0,0 +1,119 @@
+package input
+
+import (
+	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/libretro/ludo/libretro"
+)
+
+// retroKeys maps the host keys to the keysyms of the libretro keyboard
+var retroKeys = map[glfw.Key]uint{
+	glfw.KeySpace:        libretro.KeySpace,
+	glfw.KeyApostrophe:   libretro.KeyQuote,
+	glfw.KeyComma:        libretro.KeyComma,
+	glfw.KeyMinus:        libretro.KeyMinus,
+	glfw.KeyPeriod:       libretro.KeyPeriod,
+	glfw.KeySlash:        libretro.KeySlash,
+	glfw.Key0:            libretro.Key0,
+	glfw.Key1:            libretro.Key1,
+	glfw.Key2:            libretro.Key2,
+	glfw.Key3:            libretro.Key3,
+	glfw.Key4:            libretro.Key4,
+	glfw.Key5:            libretro.Key5,
+	glfw.Key6:            libretro.Key6,
+	glfw.Key7:            libretro.Key7,
+	glfw.Key8:            libretro.Key8,
+	glfw.Key9:            libretro.Key9,
+	glfw.KeySemicolon:    libretro.KeySemicolon,
+	glfw.KeyEqual:        libretro.KeyEquals,
+	glfw.KeyA:            libretro.KeyA,
+	glfw.KeyB:            libretro.KeyB,
+	glfw.KeyC:            libretro.KeyC,
+	glfw.KeyD:            libretro.KeyD,
+	glfw.KeyE:            libretro.KeyE,
+	glfw.KeyF:            libretro.KeyF,
+	glfw.KeyG:            libretro.KeyG,
+	glfw.KeyH:            libretro.KeyH,
+	glfw.KeyI:            libretro.KeyI,
+	glfw.KeyJ:            libretro.KeyJ,
+	glfw.KeyK:            libretro.KeyK,
+	glfw.KeyL:            libretro.KeyL,
+	glfw.KeyM:            libretro.KeyM,
+	glfw.KeyN:            libretro.KeyN,
+	glfw.KeyO:            libretro.KeyO,
+	glfw.KeyP:            libretro.KeyP,
+	glfw.KeyQ:            libretro.KeyQ,
+	glfw.KeyR:            libretro.KeyR,
+	glfw.KeyS:            libretro.KeyS,
+	glfw.KeyT:            libretro.KeyT,
+	glfw.KeyU:            libretro.KeyU,
+	glfw.KeyV:            libretro.KeyV,
+	glfw.KeyW:            libretro.KeyW,
+	glfw.KeyX:            libretro.KeyX,
+	glfw.KeyY:            libretro.KeyY,
+	glfw.KeyZ:            libretro.KeyZ,
+	glfw.KeyLeftBracket:  libretro.KeyLeftBracket,
+	glfw.KeyBackslash:    libretro.KeyBackslash,
+	glfw.KeyRightBracket: libretro.KeyRightBracket,
+	glfw.KeyGraveAccent:  libretro.KeyBackQuote,
+	glfw.KeyWorld1:       libretro.KeyOEM102,
+	glfw.KeyEscape:       libretro.KeyEscape,
+	glfw.KeyEnter:        libretro.KeyReturn,
+	glfw.KeyTab:          libretro.KeyTab,
+	glfw.KeyBackspace:    libretro.KeyBackspace,
+	glfw.KeyInsert:       libretro.KeyInsert,
+	glfw.KeyDelete:       libretro.KeyDelete,
+	glfw.KeyRight:        libretro.KeyRight,
+	glfw.KeyLeft:         libretro.KeyLeft,
+	glfw.KeyDown:         libretro.KeyDown,
+	glfw.KeyUp:           libretro.KeyUp,
+	glfw.KeyPageUp:       libretro.KeyPageUp,
+	glfw.KeyPageDown:     libretro.KeyPageDown,
+	glfw.KeyHome:         libretro.KeyHome,
+	glfw.KeyEnd:          libretro.KeyEnd,
+	glfw.KeyCapsLock:     libretro.KeyCapsLock,
+	glfw.KeyScrollLock:   libretro.KeyScrolLock,
+	glfw.KeyNumLock:      libretro.KeyNumLock,
+	glfw.KeyPrintScreen:  libretro.KeyPrint,
+	glfw.KeyPause:        libretro.KeyPause,
+	glfw.KeyF1:           libretro.KeyF1,
+	glfw.KeyF2:           libretro.KeyF2,
+	glfw.KeyF3:           libretro.KeyF3,
+	glfw.KeyF4:           libretro.KeyF4,
+	glfw.KeyF5:           libretro.KeyF5,
+	glfw.KeyF6:           libretro.KeyF6,
+	glfw.KeyF7:           libretro.KeyF7,
+	glfw.KeyF8:           libretro.KeyF8,
+	glfw.KeyF9:           libretro.KeyF9,
+	glfw.KeyF10:          libretro.KeyF10,
+	glfw.KeyF11:          libretro.KeyF11,
+	glfw.KeyF12:          libretro.KeyF12,
+	glfw.KeyF13:          libretro.KeyF13,
+	glfw.KeyF14:          libretro.KeyF14,
+	glfw.KeyF15:          libretro.KeyF15,
+	glfw.KeyKP0:          libretro.KeyKP0,
+	glfw.KeyKP1:          libretro.KeyKP1,
+	glfw.KeyKP2:          libretro.KeyKP2,
+	glfw.KeyKP3:          libretro.KeyKP3,
+	glfw.KeyKP4:          libretro.KeyKP4,
+	glfw.KeyKP5:          libretro.KeyKP5,
+	glfw.KeyKP6:          libretro.KeyKP6,
+	glfw.KeyKP7:          libretro.KeyKP7,
+	glfw.KeyKP8:          libretro.KeyKP8,
+	glfw.KeyKP9:          libretro.KeyKP9,
+	glfw.KeyKPDecimal:    libretro.KeyKPPeriod,
+	glfw.KeyKPDivide:     libretro.KeyKPDivide,
+	glfw.KeyKPMultiply:   libretro.KeyKPMultiply,
+	glfw.KeyKPSubtract:   libretro.KeyKPMinus,
+	glfw.KeyKPAdd:        libretro.KeyKPPlus,
+	glfw.KeyKPEnter:      libretro.KeyKPEnter,
+	glfw.KeyKPEqual:      libretro.KeyKPEquals,
+	glfw.KeyLeftShift:    libretro.KeyLShift,
+	glfw.KeyLeftControl:  libretro.KeyLCtrl,
+	glfw.KeyLeftAlt:      libretro.KeyLAlt,
+	glfw.KeyLeftSuper:    libretro.KeyLSuper,
+	glfw.KeyRightShift:   libretro.KeyRShift,
+	glfw.KeyRightControl: libretro.KeyRCtrl,
+	glfw.KeyRightAlt:     libretro.KeyRAlt,
+	glfw.KeyRightSuper:   libretro.KeyRSuper,
+	glfw.KeyMenu:         libretro.KeyMenu,
+}
@@ -10,6 +10,7 @@ import (
 	lr "github.com/libretro/ludo/libretro"
 	ntf "github.com/libretro/ludo/notifications"
 	"github.com/libretro/ludo/settings"
+	"github.com/libretro/ludo/state"
 	"github.com/libretro/ludo/video"
 
 	"github.com/libretro/ludo/l10n"
@@ -35,8 +36,11 @@ var (
 	NewAnalogState AnalogStates // analog input state for the current frame
 )
 
-var oldMouseX float64
-var oldMouseY float64
+// Joypad states injected by other sources than the local devices, like netplay
+var (
+	injected      [MaxPlayers]bool
+	injectedState [MaxPlayers]uint16
+)
 
 // Hot keys
 const (
@@ -48,8 +52,12 @@ const (
 	ActionShouldClose uint32 = lr.DeviceIDJoypadR3 + 3
 	// ActionFastForwardToggle will run the core as fast as possible
 	ActionFastForwardToggle uint32 = lr.DeviceIDJoypadR3 + 4
+	// ActionRewind steps back in time while held
+	ActionRewind uint32 = lr.DeviceIDJoypadR3 + 5
+	// ActionGameFocusToggle sends the whole keyboard to the core
+	ActionGameFocusToggle uint32 = lr.DeviceIDJoypadR3 + 6
 	// ActionLast is used for iterating
-	ActionLast uint32 = lr.DeviceIDJoypadR3 + 5
+	ActionLast uint32 = lr.DeviceIDJoypadR3 + 7
 )
 
 // joystickCallback is triggered when a joypad is plugged.
@@ -139,14 +147,31 @@ func pollJoypads(state States, analogState AnalogStates) (States, AnalogStates)
 	return state, analogState
 }
 
-// pollKeyboard processes keyboard keys
-func pollKeyboard(state States) States {
+// GamepadNames returns the names of the connected gamepads, in the order they
+// are assigned to the ports
+func GamepadNames() []string {
+	var names []string
+	for joy := glfw.Joystick(0); joy < glfw.JoystickLast; joy++ {
+		if joy.IsGamepad() {
+			names = append(names, joy.GetName())
+		}
+	}
+	return names
+}
+
+// pollKeyboard processes keyboard keys. In game focus, the keyboard belongs
+// to the core and only the game focus hotkey is processed.
+func pollKeyboard(st States) States {
+	focus := state.GameFocus && !state.MenuActive
 	for k, v := range keyBinds {
+		if focus && v != ActionGameFocusToggle {
+			continue
+		}
 		if vid.Window.GetKey(k) == glfw.Press {
-			state[0][v] = 1
+			st[0][v] = 1
 		}
 	}
-	return state
+	return st
 }
 
 // Compute the keys pressed or released during this frame
@@ -175,6 +200,11 @@ func Poll() {
 	NewState = pollKeyboard(NewState)
 	Pressed, Released = getPressedReleased(NewState, OldState)
 
+	NewKeyboardState = pollRetroKeyboard()
+	keyboardEvents()
+
+	NewMouseState = pollMouse()
+
 	// Store the old input state for comparisons
 	OldState = NewState
 }
@@ -182,15 +212,33 @@ func Poll() {
 // State is a callback passed to core.SetInputState
 // It returns 1 if the button corresponding to the parameters is pressed
 func State(port uint, device uint32, index uint, id uint) int16 {
-	if port >= MaxPlayers {
+	v := source.State(port, device, index, id)
+	if observer != nil {
+		observer(port, device, index, id, v)
+	}
+	return v
+}
+
+// State reads the input state from the local devices
+func (devices) State(port uint, device uint32, index uint, id uint) int16 {
+	if port >= MaxPlayers || Device(port) == lr.DeviceNone {
 		return 0
 	}
 
+	// Subclasses like a multitap or a Justifier are polled as their base type
+	device &= lr.DeviceMask
+
 	if device == lr.DeviceJoypad {
 		if id >= uint(ActionLast) || index > 0 {
 			return 0
 		}
-		return NewState[port][id]
+		if injected[port] {
+			if id > uint(lr.DeviceIDJoypadR3) {
+				return 0
+			}
+			return int16(injectedState[port] >> id & 1)
+		}
+		return NewState[port][Remapped(port, uint32(id))]
 	}
 	if device == lr.DeviceAnalog {
 		if index > uint(lr.DeviceIndexAnalogRight) || id > uint(lr.DeviceIDAnalogY) {
@@ -200,25 +248,49 @@ func State(port uint, device uint32, index uint, id uint) int16 {
 		return NewAnalogState[port][index][id]
 	}
 
-	if device == lr.DeviceMouse {
-		x, y := vid.Window.GetCursorPos()
-		if id == uint(lr.DeviceIDMouseX) {
-			d := x - oldMouseX
-			oldMouseX = x
-			return int16(d)
-		}
-		if id == uint(lr.DeviceIDMouseY) {
-			d := y - oldMouseY
-			oldMouseY = y
-			return int16(d)
-		}
-		if id == uint(lr.DeviceIDMouseLeft) && vid.Window.GetMouseButton(glfw.MouseButton1) == glfw.Press {
-			return 1
-		}
-		if id == uint(lr.DeviceIDMouseRight) && vid.Window.GetMouseButton(glfw.MouseButton2) == glfw.Press {
-			return 1
+	if device == lr.DeviceKeyboard {
+		if id >= lr.KeyLast || !NewKeyboardState[id] {
+			return 0
 		}
+		return 1
+	}
+
+	if device == lr.DeviceMouse {
+		return mouseState(NewMouseState, uint32(id))
+	}
+
+	if device == lr.DeviceLightgun {
+		return lightgunState(NewMouseState, NewState[port], uint32(id))
+	}
+
+	if device == lr.DevicePointer {
+		return pointerState(NewMouseState, index, uint32(id))
 	}
 
 	return 0
 }
+
+// Buttons returns the joypad state of a local player as a bitmask of libretro
+// joypad buttons, as seen by the core after remapping
+func Buttons(player int) uint16 {
+	var b uint16
+	for id := lr.DeviceIDJoypadB; id <= lr.DeviceIDJoypadR3; id++ {
+		if NewState[player][Remapped(uint(player), id)] == 1 {
+			b |= 1 << id
+		}
+	}
+	return b
+}
+
+// Inject replaces the joypad state of a port with a bitmask of libretro joypad
+// buttons, until Release is called
+func Inject(port uint, buttons uint16) {
+	injected[port] = true
+	injectedState[port] = buttons
+}
+
+// Release gives a port back to the local devices
+func Release(port uint) {
+	injected[port] = false
+	injectedState[port] = 0
+}
@@ -1,7 +1,11 @@
 package input
 
 import (
+	"reflect"
 	"testing"
+
+	lr "github.com/libretro/ludo/libretro"
+	"github.com/libretro/ludo/state"
 )
 
 func Test_getPressedReleased(t *testing.T) {
@@ -23,3 +27,249 @@ func Test_getPressedReleased(t *testing.T) {
 		}
 	})
 }
+
+func Test_Inject(t *testing.T) {
+	NewState = States{}
+	NewState[1][lr.DeviceIDJoypadA] = 1
+
+	t.Run("Injected buttons replace the local state", func(t *testing.T) {
+		Inject(1, 1<<lr.DeviceIDJoypadStart)
+		if got := State(1, lr.DeviceJoypad, 0, uint(lr.DeviceIDJoypadStart)); got != 1 {
+			t.Errorf("got = %v, want %v", got, 1)
+		}
+		if got := State(1, lr.DeviceJoypad, 0, uint(lr.DeviceIDJoypadA)); got != 0 {
+			t.Errorf("got = %v, want %v", got, 0)
+		}
+	})
+
+	t.Run("Released ports read the local state again", func(t *testing.T) {
+		Release(1)
+		if got := State(1, lr.DeviceJoypad, 0, uint(lr.DeviceIDJoypadA)); got != 1 {
+			t.Errorf("got = %v, want %v", got, 1)
+		}
+	})
+
+	t.Run("Buttons converts the local state to a bitmask", func(t *testing.T) {
+		if got := Buttons(1); got != 1<<lr.DeviceIDJoypadA {
+			t.Errorf("got = %v, want %v", got, 1<<lr.DeviceIDJoypadA)
+		}
+	})
+
+	NewState = States{}
+}
+
+func Test_SetDevice(t *testing.T) {
+	NewState = States{}
+	NewState[0][lr.DeviceIDJoypadB] = 1
+
+	t.Run("Subclasses are polled as their base type", func(t *testing.T) {
+		multitap := lr.DeviceSubclass(lr.DeviceJoypad, 1)
+		SetDevice(0, multitap)
+		if got := State(0, multitap, 0, uint(lr.DeviceIDJoypadB)); got != 1 {
+			t.Errorf("got = %v, want %v", got, 1)
+		}
+	})
+
+	t.Run("Unplugged ports don't answer", func(t *testing.T) {
+		SetDevice(0, lr.DeviceNone)
+		if got := State(0, lr.DeviceJoypad, 0, uint(lr.DeviceIDJoypadB)); got != 0 {
+			t.Errorf("got = %v, want %v", got, 0)
+		}
+	})
+
+	t.Run("Reset plugs a RetroPad back", func(t *testing.T) {
+		ResetDevices()
+		if got := Device(0); got != lr.DeviceJoypad {
+			t.Errorf("got = %v, want %v", got, lr.DeviceJoypad)
+		}
+	})
+
+	NewState = States{}
+}
+
+func Test_SetRemap(t *testing.T) {
+	NewState = States{}
+	NewState[0][lr.DeviceIDJoypadY] = 1
+
+	t.Run("A remapped button is fed by another RetroPad button", func(t *testing.T) {
+		SetRemap(0, lr.DeviceIDJoypadB, lr.DeviceIDJoypadY)
+		if got := State(0, lr.DeviceJoypad, 0, uint(lr.DeviceIDJoypadB)); got != 1 {
+			t.Errorf("got = %v, want %v", got, 1)
+		}
+		if got := Buttons(0); got&(1<<lr.DeviceIDJoypadB) == 0 {
+			t.Errorf("got = %b, want B pressed", got)
+		}
+	})
+
+	t.Run("Other ports are not remapped", func(t *testing.T) {
+		NewState[1][lr.DeviceIDJoypadY] = 1
+		if got := State(1, lr.DeviceJoypad, 0, uint(lr.DeviceIDJoypadB)); got != 0 {
+			t.Errorf("got = %v, want %v", got, 0)
+		}
+	})
+
+	ResetRemaps()
+	NewState = States{}
+}
+
+func Test_sendKeyboardEvents(t *testing.T) {
+	type event struct {
+		down      bool
+		keycode   uint
+		character uint32
+		modifiers uint16
+	}
+	var events []event
+	cb := func(down bool, keycode uint, character uint32, modifiers uint16) {
+		events = append(events, event{down, keycode, character, modifiers})
+	}
+
+	var old, new KeyboardStates
+	new[lr.KeyLShift] = true
+	new[lr.KeyA] = true
+	sendKeyboardEvents(new, old, cb)
+
+	t.Run("Presses carry the typed character and the modifiers", func(t *testing.T) {
+		want := []event{
+			{true, lr.KeyA, 'A', lr.KeyModShift},
+			{true, lr.KeyLShift, 0, lr.KeyModShift},
+		}
+		if !reflect.DeepEqual(events, want) {
+			t.Errorf("got = %v, want %v", events, want)
+		}
+	})
+
+	events = nil
+	old = new
+	new[lr.KeyA] = false
+	sendKeyboardEvents(new, old, cb)
+
+	t.Run("Releases carry no character", func(t *testing.T) {
+		want := []event{{false, lr.KeyA, 0, lr.KeyModShift}}
+		if !reflect.DeepEqual(events, want) {
+			t.Errorf("got = %v, want %v", events, want)
+		}
+	})
+
+	t.Run("The keyboard device reads the keyboard state", func(t *testing.T) {
+		NewKeyboardState = new
+		if got := State(0, lr.DeviceKeyboard, 0, lr.KeyLShift); got != 1 {
+			t.Errorf("got = %v, want %v", got, 1)
+		}
+		NewKeyboardState = KeyboardStates{}
+	})
+}
+
+func Test_keyboardEvents(t *testing.T) {
+	var released []uint
+	cb := func(down bool, keycode uint, character uint32, modifiers uint16) {
+		if !down {
+			released = append(released, keycode)
+		}
+	}
+	state.Core = &lr.Core{KeyboardCallback: cb}
+	state.CoreRunning = true
+	defer func() {
+		state.Core = nil
+		state.CoreRunning = false
+		state.MenuActive = false
+		NewKeyboardState = KeyboardStates{}
+		OldKeyboardState = KeyboardStates{}
+	}()
+
+	NewKeyboardState[lr.KeyA] = true
+	keyboardEvents()
+
+	// The key is released while the menu is open
+	state.MenuActive = true
+	NewKeyboardState = KeyboardStates{}
+	keyboardEvents()
+	if len(released) != 0 {
+		t.Errorf("got = %v, want no events in the menu", released)
+	}
+
+	state.MenuActive = false
+	keyboardEvents()
+	if !reflect.DeepEqual(released, []uint{lr.KeyA}) {
+		t.Errorf("got = %v, want %v", released, []uint{lr.KeyA})
+	}
+}
+
+func Test_character(t *testing.T) {
+	tests := []struct {
+		keycode uint
+		mod     uint16
+		want    uint32
+	}{
+		{lr.KeyZ, lr.KeyModNone, 'z'},
+		{lr.Key1, lr.KeyModShift, '!'},
+		{lr.KeyKP5, lr.KeyModNone, '5'},
+		{lr.KeyF1, lr.KeyModNone, 0},
+	}
+	for _, tt := range tests {
+		if got := character(tt.keycode, tt.mod); got != tt.want {
+			t.Errorf("character(%d, %d) = %q, want %q", tt.keycode, tt.mod, got, tt.want)
+		}
+	}
+}
+
+func Test_screenCoords(t *testing.T) {
+	tests := []struct {
+		name          string
+		px, py        float64
+		wantX, wantY  int16
+		wantOffscreen bool
+	}{
+		{"center", 400, 300, 0, 0, false},
+		{"top left", 100, 0, -0x7fff, -0x7fff, false},
+		{"bottom right", 700, 600, 0x7fff, 0x7fff, false},
+		{"left border", 50, 300, -0x8000, -0x8000, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y, offscreen := screenCoords(tt.px, tt.py, 100, 0, 600, 600)
+			if x != tt.wantX || y != tt.wantY || offscreen != tt.wantOffscreen {
+				t.Errorf("got = %d, %d, %v, want %d, %d, %v", x, y, offscreen, tt.wantX, tt.wantY, tt.wantOffscreen)
+			}
+		})
+	}
+}
+
+func Test_lightgunState(t *testing.T) {
+	defer ResetDevices()
+	SetDevice(0, lr.DeviceLightgun)
+	NewMouseState = MouseState{X: 100, Y: -200}
+	NewMouseState.Buttons[0] = true
+	NewState[0][lr.DeviceIDJoypadStart] = 1
+	defer func() {
+		NewMouseState = MouseState{}
+		NewState = States{}
+	}()
+
+	t.Run("The mouse aims and shoots", func(t *testing.T) {
+		got := []int16{
+			State(0, lr.DeviceLightgun, 0, uint(lr.DeviceIDLightgunScreenX)),
+			State(0, lr.DeviceLightgun, 0, uint(lr.DeviceIDLightgunScreenY)),
+			State(0, lr.DeviceLightgun, 0, uint(lr.DeviceIDLightgunTrigger)),
+			State(0, lr.DeviceLightgun, 0, uint(lr.DeviceIDLightgunIsOffscreen)),
+			State(0, lr.DeviceLightgun, 0, uint(lr.DeviceIDLightgunStart)),
+		}
+		want := []int16{100, -200, 1, 0, 1}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Reloading shoots off-screen", func(t *testing.T) {
+		NewMouseState.Buttons[1] = true
+		got := []int16{
+			State(0, lr.DeviceLightgun, 0, uint(lr.DeviceIDLightgunReload)),
+			State(0, lr.DeviceLightgun, 0, uint(lr.DeviceIDLightgunIsOffscreen)),
+			State(0, lr.DeviceLightgun, 0, uint(lr.DeviceIDLightgunScreenX)),
+		}
+		want := []int16{1, 1, -0x8000}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got = %v, want %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package input
+
+import (
+	"github.com/go-gl/glfw/v3.3/glfw"
+	lr "github.com/libretro/ludo/libretro"
+	"github.com/libretro/ludo/state"
+)
+
+// KeyboardStates can store the state of every libretro keysym
+type KeyboardStates [lr.KeyLast]bool
+
+// Keyboard state as seen by the core
+var (
+	NewKeyboardState KeyboardStates // keyboard state for the current frame
+	OldKeyboardState KeyboardStates // keyboard state last sent to the core
+)
+
+// shiftedKeys are the characters typed with shift on a US layout
+var shiftedKeys = map[uint]rune{
+	lr.Key1: '!', lr.Key2: '@', lr.Key3: '#', lr.Key4: '$', lr.Key5: '%',
+	lr.Key6: '^', lr.Key7: '&', lr.Key8: '*', lr.Key9: '(', lr.Key0: ')',
+	lr.KeyMinus: '_', lr.KeyEquals: '+', lr.KeyLeftBracket: '{',
+	lr.KeyRightBracket: '}', lr.KeyBackslash: '|', lr.KeySemicolon: ':',
+	lr.KeyQuote: '"', lr.KeyComma: '<', lr.KeyPeriod: '>', lr.KeySlash: '?',
+	lr.KeyBackQuote: '~',
+}
+
+// modifiers returns the libretro modifiers held in a keyboard state
+func modifiers(keys KeyboardStates) uint16 {
+	var mod uint16
+	if keys[lr.KeyLShift] || keys[lr.KeyRShift] {
+		mod |= lr.KeyModShift
+	}
+	if keys[lr.KeyLCtrl] || keys[lr.KeyRCtrl] {
+		mod |= lr.KeyModCtrl
+	}
+	if keys[lr.KeyLAlt] || keys[lr.KeyRAlt] {
+		mod |= lr.KeyModAlt
+	}
+	if keys[lr.KeyLSuper] || keys[lr.KeyRSuper] || keys[lr.KeyLMeta] || keys[lr.KeyRMeta] {
+		mod |= lr.KeyModMeta
+	}
+	return mod
+}
+
+// character returns the character typed by a keysym, or 0 for keys that
+// don't type anything
+func character(keycode uint, mod uint16) uint32 {
+	shift := mod&lr.KeyModShift != 0
+	switch {
+	case keycode >= lr.KeyA && keycode <= lr.KeyZ:
+		if shift {
+			return uint32(keycode - lr.KeyA + 'A')
+		}
+		return uint32(keycode)
+	case keycode >= lr.KeyKP0 && keycode <= lr.KeyKP9:
+		return uint32(keycode - lr.KeyKP0 + '0')
+	case keycode == lr.KeySpace, keycode == lr.KeyReturn, keycode == lr.KeyTab:
+		return uint32(keycode)
+	case keycode > lr.KeySpace && keycode < lr.KeyDelete:
+		if r, ok := shiftedKeys[keycode]; ok && shift {
+			return uint32(r)
+		}
+		return uint32(keycode)
+	}
+	return 0
+}
+
+// pollRetroKeyboard reads the whole keyboard for the libretro keyboard device
+func pollRetroKeyboard() KeyboardStates {
+	var keys KeyboardStates
+	if vid == nil || vid.Window == nil {
+		return keys
+	}
+	for k, v := range retroKeys {
+		if vid.Window.GetKey(k) == glfw.Press {
+			keys[v] = true
+		}
+	}
+	return keys
+}
+
+// sendKeyboardEvents notifies the core about the keys pressed and released
+// since the last frame
+func sendKeyboardEvents(new, old KeyboardStates, cb lr.KeyboardCallback) {
+	mod := modifiers(new)
+	for k := range new {
+		if new[k] == old[k] {
+			continue
+		}
+		var char uint32
+		if new[k] {
+			char = character(uint(k), mod)
+		}
+		cb(new[k], uint(k), char, mod)
+	}
+}
+
+// keyboardEvents sends the keyboard events to the core, if it asked for them
+// and is currently running. While the events are suppressed, the old state
+// keeps the keys the core saw last, so a key released in the menu is released
+// in the core when the game resumes.
+func keyboardEvents() {
+	if state.Core == nil || state.Core.KeyboardCallback == nil || !state.CoreRunning || state.MenuActive {
+		return
+	}
+	sendKeyboardEvents(NewKeyboardState, OldKeyboardState, state.Core.KeyboardCallback)
+	OldKeyboardState = NewKeyboardState
+}
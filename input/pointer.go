@@ -0,0 +1,162 @@
+package input
+
+import (
+	"github.com/go-gl/glfw/v3.3/glfw"
+	lr "github.com/libretro/ludo/libretro"
+)
+
+// MouseState is the host mouse as seen by the core during a frame
+type MouseState struct {
+	DX, DY    int16 // motion since the previous frame, in window pixels
+	X, Y      int16 // position over the game screen, between -0x7fff and 0x7fff
+	Offscreen bool  // the cursor is outside of the game screen
+	Buttons   [5]bool
+}
+
+// Mouse state for the current frame
+var (
+	NewMouseState MouseState
+	mouseX        float64
+	mouseY        float64
+)
+
+// offscreenCoord is the coordinate libretro reports on both axes for a
+// position outside of the game screen
+const offscreenCoord = -0x8000
+
+// screenCoords maps a position in the framebuffer to the libretro screen
+// coordinates of a game displayed in the viewport vx, vy, vw, vh
+func screenCoords(px, py, vx, vy, vw, vh float64) (x, y int16, offscreen bool) {
+	if vw <= 0 || vh <= 0 {
+		return offscreenCoord, offscreenCoord, true
+	}
+	nx := (px-vx)/vw*2 - 1
+	ny := (py-vy)/vh*2 - 1
+	if nx < -1 || nx > 1 || ny < -1 || ny > 1 {
+		return offscreenCoord, offscreenCoord, true
+	}
+	return int16(nx * 0x7fff), int16(ny * 0x7fff), false
+}
+
+// pollMouse reads the cursor and the buttons of the host mouse
+func pollMouse() MouseState {
+	var m MouseState
+	if vid == nil || vid.Window == nil {
+		return m
+	}
+
+	x, y := vid.Window.GetCursorPos()
+	m.DX = int16(x - mouseX)
+	m.DY = int16(y - mouseY)
+	mouseX, mouseY = x, y
+
+	// The cursor is in window coordinates, the viewport in framebuffer pixels
+	ww, wh := vid.Window.GetSize()
+	fbw, fbh := vid.Window.GetFramebufferSize()
+	if ww > 0 && wh > 0 {
+		vx, vy, vw, vh := vid.Viewport(fbw, fbh)
+		m.X, m.Y, m.Offscreen = screenCoords(
+			x*float64(fbw)/float64(ww), y*float64(fbh)/float64(wh),
+			float64(vx), float64(vy), float64(vw), float64(vh))
+	}
+
+	for i := range m.Buttons {
+		m.Buttons[i] = vid.Window.GetMouseButton(glfw.MouseButton1+glfw.MouseButton(i)) == glfw.Press
+	}
+	return m
+}
+
+// Cursor returns the position of the host cursor over the game screen, for
+// drawing a crosshair
+func Cursor() (x, y int16, offscreen bool) {
+	return NewMouseState.X, NewMouseState.Y, NewMouseState.Offscreen
+}
+
+func boolToInt16(b bool) int16 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// mouseState answers the core for the mouse device
+func mouseState(m MouseState, id uint32) int16 {
+	switch id {
+	case lr.DeviceIDMouseX:
+		return m.DX
+	case lr.DeviceIDMouseY:
+		return m.DY
+	case lr.DeviceIDMouseLeft:
+		return boolToInt16(m.Buttons[0])
+	case lr.DeviceIDMouseRight:
+		return boolToInt16(m.Buttons[1])
+	}
+	return 0
+}
+
+// lightgunState answers the core for the lightgun device. The mouse aims and
+// shoots, the gun buttons that aren't on a mouse come from the joypad of the
+// same port. Reloading shoots off-screen.
+func lightgunState(m MouseState, joypad [ActionLast]int16, id uint32) int16 {
+	reload := m.Buttons[1]
+	switch id {
+	case lr.DeviceIDLightgunScreenX:
+		if reload {
+			return -0x8000
+		}
+		return m.X
+	case lr.DeviceIDLightgunScreenY:
+		if reload {
+			return -0x8000
+		}
+		return m.Y
+	case lr.DeviceIDLightgunIsOffscreen:
+		return boolToInt16(m.Offscreen || reload)
+	case lr.DeviceIDLightgunTrigger:
+		return boolToInt16(m.Buttons[0] || reload)
+	case lr.DeviceIDLightgunReload:
+		return boolToInt16(reload)
+	case lr.DeviceIDLightgunAuxA:
+		return boolToInt16(m.Buttons[2]) | joypad[lr.DeviceIDJoypadA]
+	case lr.DeviceIDLightgunAuxB:
+		return boolToInt16(m.Buttons[3]) | joypad[lr.DeviceIDJoypadB]
+	case lr.DeviceIDLightgunAuxC:
+		return boolToInt16(m.Buttons[4]) | joypad[lr.DeviceIDJoypadY]
+	case lr.DeviceIDLightgunStart:
+		return joypad[lr.DeviceIDJoypadStart]
+	case lr.DeviceIDLightgunSelect:
+		return joypad[lr.DeviceIDJoypadSelect]
+	case lr.DeviceIDLightgunDpadUp:
+		return joypad[lr.DeviceIDJoypadUp]
+	case lr.DeviceIDLightgunDpadDown:
+		return joypad[lr.DeviceIDJoypadDown]
+	case lr.DeviceIDLightgunDpadLeft:
+		return joypad[lr.DeviceIDJoypadLeft]
+	case lr.DeviceIDLightgunDpadRight:
+		return joypad[lr.DeviceIDJoypadRight]
+	case lr.DeviceIDLightgunX:
+		return m.DX
+	case lr.DeviceIDLightgunY:
+		return m.DY
+	}
+	return 0
+}
+
+// pointerState answers the core for the pointer device, with a single touch
+// made by the left button
+func pointerState(m MouseState, index uint, id uint32) int16 {
+	if index > 0 {
+		return 0
+	}
+	switch id {
+	case lr.DeviceIDPointerX:
+		return m.X
+	case lr.DeviceIDPointerY:
+		return m.Y
+	case lr.DeviceIDPointerPressed:
+		return boolToInt16(m.Buttons[0] && !m.Offscreen)
+	case lr.DeviceIDPointerCount:
+		return boolToInt16(m.Buttons[0] && !m.Offscreen)
+	}
+	return 0
+}
@@ -0,0 +1,44 @@
+package input
+
+import (
+	lr "github.com/libretro/ludo/libretro"
+)
+
+// ports stores the device type plugged in each port, as selected by the user
+var ports = defaultPorts()
+
+func defaultPorts() (p [MaxPlayers]uint32) {
+	for i := range p {
+		p[i] = lr.DeviceJoypad
+	}
+	return
+}
+
+// Capabilities returns the bitmask of the device types the local devices can
+// answer for
+func Capabilities() uint64 {
+	return 1<<lr.DeviceJoypad | 1<<lr.DeviceAnalog | 1<<lr.DeviceMouse | 1<<lr.DeviceKeyboard |
+		1<<lr.DeviceLightgun | 1<<lr.DevicePointer
+}
+
+// SetDevice records the device type plugged in a port. It can be a subclass of
+// a base device type.
+func SetDevice(port uint, device uint32) {
+	if port >= MaxPlayers {
+		return
+	}
+	ports[port] = device
+}
+
+// Device returns the device type plugged in a port
+func Device(port uint) uint32 {
+	if port >= MaxPlayers {
+		return lr.DeviceNone
+	}
+	return ports[port]
+}
+
+// ResetDevices plugs a RetroPad in every port
+func ResetDevices() {
+	ports = defaultPorts()
+}
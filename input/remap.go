@@ -0,0 +1,76 @@
+package input
+
+import (
+	lr "github.com/libretro/ludo/libretro"
+)
+
+// ButtonsCount is the number of buttons of the RetroPad
+const ButtonsCount = lr.DeviceIDJoypadR3 + 1
+
+// ButtonNames are the short names of the RetroPad buttons, indexed by id
+var ButtonNames = [ButtonsCount]string{
+	lr.DeviceIDJoypadB:      "B",
+	lr.DeviceIDJoypadY:      "Y",
+	lr.DeviceIDJoypadSelect: "Select",
+	lr.DeviceIDJoypadStart:  "Start",
+	lr.DeviceIDJoypadUp:     "Up",
+	lr.DeviceIDJoypadDown:   "Down",
+	lr.DeviceIDJoypadLeft:   "Left",
+	lr.DeviceIDJoypadRight:  "Right",
+	lr.DeviceIDJoypadA:      "A",
+	lr.DeviceIDJoypadX:      "X",
+	lr.DeviceIDJoypadL:      "L",
+	lr.DeviceIDJoypadR:      "R",
+	lr.DeviceIDJoypadL2:     "L2",
+	lr.DeviceIDJoypadR2:     "R2",
+	lr.DeviceIDJoypadL3:     "L3",
+	lr.DeviceIDJoypadR3:     "R3",
+}
+
+// Remaps tells, for each port, which RetroPad button of the local devices
+// feeds each button the core polls
+type Remaps [MaxPlayers][ButtonsCount]uint32
+
+var remaps = DefaultRemaps()
+
+// DefaultRemaps returns the identity remapping
+func DefaultRemaps() (r Remaps) {
+	for p := range r {
+		for id := range r[p] {
+			r[p][id] = uint32(id)
+		}
+	}
+	return
+}
+
+// SetRemaps replaces the remapping of every port
+func SetRemaps(r Remaps) {
+	remaps = r
+}
+
+// CurrentRemaps returns the remapping of every port
+func CurrentRemaps() Remaps {
+	return remaps
+}
+
+// SetRemap makes a RetroPad button of the local devices feed a button of the
+// core on a given port
+func SetRemap(port uint, id uint32, button uint32) {
+	if port >= MaxPlayers || id >= ButtonsCount || button >= ButtonsCount {
+		return
+	}
+	remaps[port][id] = button
+}
+
+// Remapped returns the RetroPad button that feeds a button of the core
+func Remapped(port uint, id uint32) uint32 {
+	if port >= MaxPlayers || id >= ButtonsCount {
+		return id
+	}
+	return remaps[port][id]
+}
+
+// ResetRemaps restores the identity remapping
+func ResetRemaps() {
+	remaps = DefaultRemaps()
+}
@@ -0,0 +1,33 @@
+package input
+
+// Source answers the input state callback of the core. The default source
+// reads the local devices, other sources can replace it, like movie playback.
+type Source interface {
+	State(port uint, device uint32, index uint, id uint) int16
+}
+
+// Observer is called with every answer given to the core
+type Observer func(port uint, device uint32, index uint, id uint, value int16)
+
+// devices is the default Source, it reads the joypads, keyboard and mouse
+type devices struct{}
+
+var (
+	source   Source = devices{}
+	observer Observer
+)
+
+// SetSource replaces the source of the input state. Passing nil restores the
+// local devices.
+func SetSource(s Source) {
+	if s == nil {
+		s = devices{}
+	}
+	source = s
+}
+
+// SetObserver registers a function called with every answer given to the
+// core. Passing nil removes it.
+func SetObserver(o Observer) {
+	observer = o
+}
@@ -128,6 +128,10 @@ func ReInit(setLng string) {
 
 // Translate(t9)
 func T9(id2 *i18n.Message) string {
+	// Before Init, e.g. in headless mode or in tests, use the default message
+	if lng == nil {
+		return id2.Other
+	}
 	return lng.l.MustLocalize(&i18n.LocalizeConfig{
 		DefaultMessage: id2,
 	})
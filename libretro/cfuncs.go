@@ -179,6 +179,10 @@ bool bridge_retro_load_game(void *f, struct retro_game_info *gi) {
   return ((bool (*)(struct retro_game_info *))f)(gi);
 }
 
+bool bridge_retro_load_game_special(void *f, unsigned type, struct retro_game_info *gi, size_t num) {
+  return ((bool (*)(unsigned, struct retro_game_info *, size_t))f)(type, gi, num);
+}
+
 size_t bridge_retro_serialize_size(void *f) {
   return ((size_t (*)(void))f)();
 }
@@ -229,6 +233,14 @@ void bridge_retro_reset(void *f) {
 	run_wrapper(f);
 }
 
+void bridge_retro_cheat_reset(void *f) {
+	run_wrapper(f);
+}
+
+void bridge_retro_cheat_set(void *f, unsigned index, bool enabled, const char *code) {
+	((void (*)(unsigned, bool, const char *))f)(index, enabled, code);
+}
+
 size_t bridge_retro_get_memory_size(void *f, unsigned id) {
 	return ((size_t (*)(unsigned))f)(id);
 }
@@ -237,6 +249,14 @@ void* bridge_retro_get_memory_data(void *f, unsigned id) {
 	return ((void* (*)(unsigned))f)(id);
 }
 
+void bridge_retro_keyboard_event(retro_keyboard_event_t f, bool down, unsigned keycode, uint32_t character, uint16_t key_modifiers) {
+	f(down, keycode, character, key_modifiers);
+}
+
+bool bridge_retro_core_options_update_display(retro_core_options_update_display_callback_t f) {
+	return f();
+}
+
 void bridge_retro_set_eject_state(retro_set_eject_state_t f, bool state) {
 	f(state);
 }
@@ -257,6 +277,26 @@ unsigned bridge_retro_get_num_images(retro_get_num_images_t f) {
 	return ((unsigned (*)())f)();
 }
 
+bool bridge_retro_replace_image_index(retro_replace_image_index_t f, unsigned index, const struct retro_game_info *info) {
+	return f(index, info);
+}
+
+bool bridge_retro_add_image_index(retro_add_image_index_t f) {
+	return f();
+}
+
+bool bridge_retro_set_initial_image(retro_set_initial_image_t f, unsigned index, const char *path) {
+	return f(index, path);
+}
+
+bool bridge_retro_get_image_path(retro_get_image_path_t f, unsigned index, char *path, size_t len) {
+	return f(index, path, len);
+}
+
+bool bridge_retro_get_image_label(retro_get_image_label_t f, unsigned index, char *label, size_t len) {
+	return f(index, label, len);
+}
+
 bool coreEnvironment_cgo(unsigned cmd, void *data) {
 	bool coreEnvironment(unsigned, void*);
 	return coreEnvironment(cmd, data);
@@ -298,10 +338,110 @@ void coreLog_cgo(enum retro_log_level level, const char *fmt, ...) {
 	coreLog(level, msg);
 }
 
+bool coreSetRumbleState_cgo(unsigned port, enum retro_rumble_effect effect, uint16_t strength) {
+	bool coreSetRumbleState(unsigned port, enum retro_rumble_effect effect, uint16_t strength);
+	return coreSetRumbleState(port, effect, strength);
+}
+
 int64_t coreGetTimeUsec_cgo() {
 	uint64_t coreGetTimeUsec();
 	return coreGetTimeUsec();
 }
 
+const char *coreVFSGetPath_cgo(struct retro_vfs_file_handle *stream) {
+	const char *coreVFSGetPath(struct retro_vfs_file_handle *stream);
+	return coreVFSGetPath(stream);
+}
+
+struct retro_vfs_file_handle *coreVFSOpen_cgo(const char *path, unsigned mode, unsigned hints) {
+	struct retro_vfs_file_handle *coreVFSOpen(char *path, unsigned mode, unsigned hints);
+	return coreVFSOpen((char*)path, mode, hints);
+}
+
+int coreVFSClose_cgo(struct retro_vfs_file_handle *stream) {
+	int coreVFSClose(struct retro_vfs_file_handle *stream);
+	return coreVFSClose(stream);
+}
+
+int64_t coreVFSSize_cgo(struct retro_vfs_file_handle *stream) {
+	int64_t coreVFSSize(struct retro_vfs_file_handle *stream);
+	return coreVFSSize(stream);
+}
+
+int64_t coreVFSTruncate_cgo(struct retro_vfs_file_handle *stream, int64_t length) {
+	int64_t coreVFSTruncate(struct retro_vfs_file_handle *stream, int64_t length);
+	return coreVFSTruncate(stream, length);
+}
+
+int64_t coreVFSTell_cgo(struct retro_vfs_file_handle *stream) {
+	int64_t coreVFSTell(struct retro_vfs_file_handle *stream);
+	return coreVFSTell(stream);
+}
+
+int64_t coreVFSSeek_cgo(struct retro_vfs_file_handle *stream, int64_t offset, int seek_position) {
+	int64_t coreVFSSeek(struct retro_vfs_file_handle *stream, int64_t offset, int seek_position);
+	return coreVFSSeek(stream, offset, seek_position);
+}
+
+int64_t coreVFSRead_cgo(struct retro_vfs_file_handle *stream, void *s, uint64_t len) {
+	int64_t coreVFSRead(struct retro_vfs_file_handle *stream, void *s, uint64_t len);
+	return coreVFSRead(stream, s, len);
+}
+
+int64_t coreVFSWrite_cgo(struct retro_vfs_file_handle *stream, const void *s, uint64_t len) {
+	int64_t coreVFSWrite(struct retro_vfs_file_handle *stream, void *s, uint64_t len);
+	return coreVFSWrite(stream, (void*)s, len);
+}
+
+int coreVFSFlush_cgo(struct retro_vfs_file_handle *stream) {
+	int coreVFSFlush(struct retro_vfs_file_handle *stream);
+	return coreVFSFlush(stream);
+}
+
+int coreVFSRemove_cgo(const char *path) {
+	int coreVFSRemove(char *path);
+	return coreVFSRemove((char*)path);
+}
+
+int coreVFSRename_cgo(const char *old_path, const char *new_path) {
+	int coreVFSRename(char *old_path, char *new_path);
+	return coreVFSRename((char*)old_path, (char*)new_path);
+}
+
+int coreVFSStat_cgo(const char *path, int32_t *size) {
+	int coreVFSStat(char *path, int32_t *size);
+	return coreVFSStat((char*)path, size);
+}
+
+int coreVFSMkdir_cgo(const char *dir) {
+	int coreVFSMkdir(char *dir);
+	return coreVFSMkdir((char*)dir);
+}
+
+struct retro_vfs_dir_handle *coreVFSOpendir_cgo(const char *dir, bool include_hidden) {
+	struct retro_vfs_dir_handle *coreVFSOpendir(char *dir, bool include_hidden);
+	return coreVFSOpendir((char*)dir, include_hidden);
+}
+
+bool coreVFSReaddir_cgo(struct retro_vfs_dir_handle *dirstream) {
+	bool coreVFSReaddir(struct retro_vfs_dir_handle *dirstream);
+	return coreVFSReaddir(dirstream);
+}
+
+const char *coreVFSDirentGetName_cgo(struct retro_vfs_dir_handle *dirstream) {
+	const char *coreVFSDirentGetName(struct retro_vfs_dir_handle *dirstream);
+	return coreVFSDirentGetName(dirstream);
+}
+
+bool coreVFSDirentIsDir_cgo(struct retro_vfs_dir_handle *dirstream) {
+	bool coreVFSDirentIsDir(struct retro_vfs_dir_handle *dirstream);
+	return coreVFSDirentIsDir(dirstream);
+}
+
+int coreVFSClosedir_cgo(struct retro_vfs_dir_handle *dirstream) {
+	int coreVFSClosedir(struct retro_vfs_dir_handle *dirstream);
+	return coreVFSClosedir(dirstream);
+}
+
 */
 import "C"
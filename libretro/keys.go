@@ -0,0 +1,165 @@
+package libretro
+
+/*
+#include "libretro.h"
+*/
+import "C"
+
+// Keysyms used as id when polling DeviceKeyboard, and as keycode in the
+// keyboard events sent to the core.
+const (
+	KeyUnknown      = uint(C.RETROK_UNKNOWN)
+	KeyBackspace    = uint(C.RETROK_BACKSPACE)
+	KeyTab          = uint(C.RETROK_TAB)
+	KeyClear        = uint(C.RETROK_CLEAR)
+	KeyReturn       = uint(C.RETROK_RETURN)
+	KeyPause        = uint(C.RETROK_PAUSE)
+	KeyEscape       = uint(C.RETROK_ESCAPE)
+	KeySpace        = uint(C.RETROK_SPACE)
+	KeyExclaim      = uint(C.RETROK_EXCLAIM)
+	KeyQuoteDbl     = uint(C.RETROK_QUOTEDBL)
+	KeyHash         = uint(C.RETROK_HASH)
+	KeyDollar       = uint(C.RETROK_DOLLAR)
+	KeyAmpersand    = uint(C.RETROK_AMPERSAND)
+	KeyQuote        = uint(C.RETROK_QUOTE)
+	KeyLeftParen    = uint(C.RETROK_LEFTPAREN)
+	KeyRightParen   = uint(C.RETROK_RIGHTPAREN)
+	KeyAsterisk     = uint(C.RETROK_ASTERISK)
+	KeyPlus         = uint(C.RETROK_PLUS)
+	KeyComma        = uint(C.RETROK_COMMA)
+	KeyMinus        = uint(C.RETROK_MINUS)
+	KeyPeriod       = uint(C.RETROK_PERIOD)
+	KeySlash        = uint(C.RETROK_SLASH)
+	Key0            = uint(C.RETROK_0)
+	Key1            = uint(C.RETROK_1)
+	Key2            = uint(C.RETROK_2)
+	Key3            = uint(C.RETROK_3)
+	Key4            = uint(C.RETROK_4)
+	Key5            = uint(C.RETROK_5)
+	Key6            = uint(C.RETROK_6)
+	Key7            = uint(C.RETROK_7)
+	Key8            = uint(C.RETROK_8)
+	Key9            = uint(C.RETROK_9)
+	KeyColon        = uint(C.RETROK_COLON)
+	KeySemicolon    = uint(C.RETROK_SEMICOLON)
+	KeyLess         = uint(C.RETROK_LESS)
+	KeyEquals       = uint(C.RETROK_EQUALS)
+	KeyGreater      = uint(C.RETROK_GREATER)
+	KeyQuestion     = uint(C.RETROK_QUESTION)
+	KeyAt           = uint(C.RETROK_AT)
+	KeyLeftBracket  = uint(C.RETROK_LEFTBRACKET)
+	KeyBackslash    = uint(C.RETROK_BACKSLASH)
+	KeyRightBracket = uint(C.RETROK_RIGHTBRACKET)
+	KeyCaret        = uint(C.RETROK_CARET)
+	KeyUnderscore   = uint(C.RETROK_UNDERSCORE)
+	KeyBackQuote    = uint(C.RETROK_BACKQUOTE)
+	KeyA            = uint(C.RETROK_a)
+	KeyB            = uint(C.RETROK_b)
+	KeyC            = uint(C.RETROK_c)
+	KeyD            = uint(C.RETROK_d)
+	KeyE            = uint(C.RETROK_e)
+	KeyF            = uint(C.RETROK_f)
+	KeyG            = uint(C.RETROK_g)
+	KeyH            = uint(C.RETROK_h)
+	KeyI            = uint(C.RETROK_i)
+	KeyJ            = uint(C.RETROK_j)
+	KeyK            = uint(C.RETROK_k)
+	KeyL            = uint(C.RETROK_l)
+	KeyM            = uint(C.RETROK_m)
+	KeyN            = uint(C.RETROK_n)
+	KeyO            = uint(C.RETROK_o)
+	KeyP            = uint(C.RETROK_p)
+	KeyQ            = uint(C.RETROK_q)
+	KeyR            = uint(C.RETROK_r)
+	KeyS            = uint(C.RETROK_s)
+	KeyT            = uint(C.RETROK_t)
+	KeyU            = uint(C.RETROK_u)
+	KeyV            = uint(C.RETROK_v)
+	KeyW            = uint(C.RETROK_w)
+	KeyX            = uint(C.RETROK_x)
+	KeyY            = uint(C.RETROK_y)
+	KeyZ            = uint(C.RETROK_z)
+	KeyLeftBrace    = uint(C.RETROK_LEFTBRACE)
+	KeyBar          = uint(C.RETROK_BAR)
+	KeyRightBrace   = uint(C.RETROK_RIGHTBRACE)
+	KeyTilde        = uint(C.RETROK_TILDE)
+	KeyDelete       = uint(C.RETROK_DELETE)
+	KeyKP0          = uint(C.RETROK_KP0)
+	KeyKP1          = uint(C.RETROK_KP1)
+	KeyKP2          = uint(C.RETROK_KP2)
+	KeyKP3          = uint(C.RETROK_KP3)
+	KeyKP4          = uint(C.RETROK_KP4)
+	KeyKP5          = uint(C.RETROK_KP5)
+	KeyKP6          = uint(C.RETROK_KP6)
+	KeyKP7          = uint(C.RETROK_KP7)
+	KeyKP8          = uint(C.RETROK_KP8)
+	KeyKP9          = uint(C.RETROK_KP9)
+	KeyKPPeriod     = uint(C.RETROK_KP_PERIOD)
+	KeyKPDivide     = uint(C.RETROK_KP_DIVIDE)
+	KeyKPMultiply   = uint(C.RETROK_KP_MULTIPLY)
+	KeyKPMinus      = uint(C.RETROK_KP_MINUS)
+	KeyKPPlus       = uint(C.RETROK_KP_PLUS)
+	KeyKPEnter      = uint(C.RETROK_KP_ENTER)
+	KeyKPEquals     = uint(C.RETROK_KP_EQUALS)
+	KeyUp           = uint(C.RETROK_UP)
+	KeyDown         = uint(C.RETROK_DOWN)
+	KeyRight        = uint(C.RETROK_RIGHT)
+	KeyLeft         = uint(C.RETROK_LEFT)
+	KeyInsert       = uint(C.RETROK_INSERT)
+	KeyHome         = uint(C.RETROK_HOME)
+	KeyEnd          = uint(C.RETROK_END)
+	KeyPageUp       = uint(C.RETROK_PAGEUP)
+	KeyPageDown     = uint(C.RETROK_PAGEDOWN)
+	KeyF1           = uint(C.RETROK_F1)
+	KeyF2           = uint(C.RETROK_F2)
+	KeyF3           = uint(C.RETROK_F3)
+	KeyF4           = uint(C.RETROK_F4)
+	KeyF5           = uint(C.RETROK_F5)
+	KeyF6           = uint(C.RETROK_F6)
+	KeyF7           = uint(C.RETROK_F7)
+	KeyF8           = uint(C.RETROK_F8)
+	KeyF9           = uint(C.RETROK_F9)
+	KeyF10          = uint(C.RETROK_F10)
+	KeyF11          = uint(C.RETROK_F11)
+	KeyF12          = uint(C.RETROK_F12)
+	KeyF13          = uint(C.RETROK_F13)
+	KeyF14          = uint(C.RETROK_F14)
+	KeyF15          = uint(C.RETROK_F15)
+	KeyNumLock      = uint(C.RETROK_NUMLOCK)
+	KeyCapsLock     = uint(C.RETROK_CAPSLOCK)
+	KeyScrolLock    = uint(C.RETROK_SCROLLOCK)
+	KeyRShift       = uint(C.RETROK_RSHIFT)
+	KeyLShift       = uint(C.RETROK_LSHIFT)
+	KeyRCtrl        = uint(C.RETROK_RCTRL)
+	KeyLCtrl        = uint(C.RETROK_LCTRL)
+	KeyRAlt         = uint(C.RETROK_RALT)
+	KeyLAlt         = uint(C.RETROK_LALT)
+	KeyRMeta        = uint(C.RETROK_RMETA)
+	KeyLMeta        = uint(C.RETROK_LMETA)
+	KeyLSuper       = uint(C.RETROK_LSUPER)
+	KeyRSuper       = uint(C.RETROK_RSUPER)
+	KeyMode         = uint(C.RETROK_MODE)
+	KeyCompose      = uint(C.RETROK_COMPOSE)
+	KeyHelp         = uint(C.RETROK_HELP)
+	KeyPrint        = uint(C.RETROK_PRINT)
+	KeySysReq       = uint(C.RETROK_SYSREQ)
+	KeyBreak        = uint(C.RETROK_BREAK)
+	KeyMenu         = uint(C.RETROK_MENU)
+	KeyPower        = uint(C.RETROK_POWER)
+	KeyEuro         = uint(C.RETROK_EURO)
+	KeyUndo         = uint(C.RETROK_UNDO)
+	KeyOEM102       = uint(C.RETROK_OEM_102)
+	KeyLast         = uint(C.RETROK_LAST)
+)
+
+// Modifiers sent along with the keyboard events
+const (
+	KeyModNone      = uint16(C.RETROKMOD_NONE)
+	KeyModShift     = uint16(C.RETROKMOD_SHIFT)
+	KeyModCtrl      = uint16(C.RETROKMOD_CTRL)
+	KeyModAlt       = uint16(C.RETROKMOD_ALT)
+	KeyModMeta      = uint16(C.RETROKMOD_META)
+	KeyModNumLock   = uint16(C.RETROKMOD_NUMLOCK)
+	KeyModCapsLock  = uint16(C.RETROKMOD_CAPSLOCK)
+	KeyModScrolLock = uint16(C.RETROKMOD_SCROLLOCK)
+)
@@ -29,12 +29,15 @@ void bridge_retro_set_input_state(void *f, void *callback);
 void bridge_retro_set_audio_sample(void *f, void *callback);
 void bridge_retro_set_audio_sample_batch(void *f, void *callback);
 bool bridge_retro_load_game(void *f, struct retro_game_info *gi);
+bool bridge_retro_load_game_special(void *f, unsigned type, struct retro_game_info *gi, size_t num);
 bool bridge_retro_serialize(void *f, void *data, size_t size);
 bool bridge_retro_unserialize(void *f, void *data, size_t size);
 size_t bridge_retro_serialize_size(void *f);
 void bridge_retro_unload_game(void *f);
 void bridge_retro_run(void *f);
 void bridge_retro_reset(void *f);
+void bridge_retro_cheat_reset(void *f);
+void bridge_retro_cheat_set(void *f, unsigned index, bool enabled, const char *code);
 void bridge_retro_frame_time_callback(retro_frame_time_callback_t f, retro_usec_t usec);
 void bridge_retro_audio_callback(retro_audio_callback_t f);
 void bridge_retro_audio_set_state(retro_audio_set_state_callback_t f, bool state);
@@ -45,6 +48,13 @@ bool bridge_retro_get_eject_state(retro_get_eject_state_t f);
 unsigned bridge_retro_get_image_index(retro_get_image_index_t f);
 void bridge_retro_set_image_index(retro_set_image_index_t f, unsigned index);
 unsigned bridge_retro_get_num_images(retro_get_num_images_t f);
+bool bridge_retro_replace_image_index(retro_replace_image_index_t f, unsigned index, const struct retro_game_info *info);
+bool bridge_retro_add_image_index(retro_add_image_index_t f);
+bool bridge_retro_set_initial_image(retro_set_initial_image_t f, unsigned index, const char *path);
+bool bridge_retro_get_image_path(retro_get_image_path_t f, unsigned index, char *path, size_t len);
+bool bridge_retro_get_image_label(retro_get_image_label_t f, unsigned index, char *label, size_t len);
+void bridge_retro_keyboard_event(retro_keyboard_event_t f, bool down, unsigned keycode, uint32_t character, uint16_t key_modifiers);
+bool bridge_retro_core_options_update_display(retro_core_options_update_display_callback_t f);
 
 bool coreEnvironment_cgo(unsigned cmd, void *data);
 void coreVideoRefresh_cgo(void *data, unsigned width, unsigned height, size_t pitch);
@@ -54,6 +64,7 @@ size_t coreAudioSampleBatch_cgo(const int16_t *data, size_t frames);
 int16_t coreInputState_cgo(unsigned port, unsigned device, unsigned index, unsigned id);
 void coreLog_cgo(enum retro_log_level level, const char *msg);
 int64_t coreGetTimeUsec_cgo();
+bool coreSetRumbleState_cgo(unsigned port, enum retro_rumble_effect effect, uint16_t strength);
 */
 import "C"
 import (
@@ -111,6 +122,46 @@ type MemoryDescriptor struct {
 	Addrspace  string
 }
 
+// SubsystemMemoryInfo describes a persistent memory of a subsystem ROM
+type SubsystemMemoryInfo struct {
+	Extension string
+	Type      uint32
+}
+
+// SubsystemROMInfo describes one of the ROMs required by a subsystem
+type SubsystemROMInfo struct {
+	Desc            string
+	ValidExtensions string
+	NeedFullpath    bool
+	BlockExtract    bool
+	Required        bool
+	Memory          []SubsystemMemoryInfo
+}
+
+// SubsystemInfo describes a special way of loading content, made of several
+// ROMs, like a Super Game Boy cartridge and a Game Boy game
+type SubsystemInfo struct {
+	Desc  string
+	Ident string
+	ROMs  []SubsystemROMInfo
+	ID    uint
+}
+
+// ControllerDescription describes a device type a core accepts on a port
+type ControllerDescription struct {
+	Desc string
+	ID   uint32
+}
+
+// InputDescriptor is a human readable name the core gives to an input
+type InputDescriptor struct {
+	Port        uint
+	Device      uint32
+	Index       uint
+	ID          uint
+	Description string
+}
+
 // Variable is a key value pair that represents a core option
 type Variable C.struct_retro_variable
 
@@ -195,10 +246,41 @@ func (cod *CoreOptionDefinition) Values() []CoreOptionValue {
 
 // Choices returns the CoreOptionDefinition values as a string slice for compatibility with options v0
 func (cod *CoreOptionDefinition) Choices() []string {
+	return optionChoices(&cod.values)
+}
+
+// DefaultValue returns the default value of a CoreOptionDefinition as a string
+func (cod *CoreOptionDefinition) DefaultValue() string {
+	return C.GoString(cod.default_value)
+}
+
+// Labels returns the human readable labels of the CoreOptionDefinition values,
+// empty when the value itself should be displayed
+func (cod *CoreOptionDefinition) Labels() []string {
+	return optionLabels(&cod.values)
+}
+
+// optionLabels returns the labels of a NULL terminated array of option values
+func optionLabels(values *[C.RETRO_NUM_CORE_OPTION_VALUES_MAX]C.struct_retro_core_option_value) []string {
+	labels := []string{}
+
+	for i := 0; i < C.RETRO_NUM_CORE_OPTION_VALUES_MAX; i++ {
+		v := values[i]
+		if v.value == nil {
+			break
+		}
+		labels = append(labels, C.GoString(v.label))
+	}
+
+	return labels
+}
+
+// optionChoices returns the values of a NULL terminated array of option values
+func optionChoices(values *[C.RETRO_NUM_CORE_OPTION_VALUES_MAX]C.struct_retro_core_option_value) []string {
 	choices := []string{}
 
 	for i := 0; i < C.RETRO_NUM_CORE_OPTION_VALUES_MAX; i++ {
-		v := (C.struct_retro_core_option_value)(cod.values[i])
+		v := values[i]
 		if v.value == nil {
 			break
 		}
@@ -208,8 +290,70 @@ func (cod *CoreOptionDefinition) Choices() []string {
 	return choices
 }
 
-// DefaultValue returns the default value of a CoreOptionDefinition as a string
-func (cod *CoreOptionDefinition) DefaultValue() string {
+// CoreOptionV2Category is a group of core options in the version 2 of the core
+// options API
+type CoreOptionV2Category C.struct_retro_core_option_v2_category
+
+// Key returns the key of a CoreOptionV2Category as a string
+func (c *CoreOptionV2Category) Key() string {
+	return C.GoString(c.key)
+}
+
+// Desc returns the name of a CoreOptionV2Category as a string
+func (c *CoreOptionV2Category) Desc() string {
+	return C.GoString(c.desc)
+}
+
+// Info returns the detailed description of a CoreOptionV2Category as a string
+func (c *CoreOptionV2Category) Info() string {
+	return C.GoString(c.info)
+}
+
+// CoreOptionV2Definition represents a core option in the version 2 of the core
+// options API
+type CoreOptionV2Definition C.struct_retro_core_option_v2_definition
+
+// Key returns the key of a CoreOptionV2Definition as a string
+func (cod *CoreOptionV2Definition) Key() string {
+	return C.GoString(cod.key)
+}
+
+// Desc returns the name of a CoreOptionV2Definition as a string. Options shown
+// inside their category can have a shorter name.
+func (cod *CoreOptionV2Definition) Desc() string {
+	if cod.category_key != nil && cod.desc_categorized != nil {
+		return C.GoString(cod.desc_categorized)
+	}
+	return C.GoString(cod.desc)
+}
+
+// Info returns the detailed description of a CoreOptionV2Definition as a string
+func (cod *CoreOptionV2Definition) Info() string {
+	if cod.category_key != nil && cod.info_categorized != nil {
+		return C.GoString(cod.info_categorized)
+	}
+	return C.GoString(cod.info)
+}
+
+// Category returns the key of the category of a CoreOptionV2Definition, empty
+// for options outside of any category
+func (cod *CoreOptionV2Definition) Category() string {
+	return C.GoString(cod.category_key)
+}
+
+// Choices returns the CoreOptionV2Definition values as a string slice
+func (cod *CoreOptionV2Definition) Choices() []string {
+	return optionChoices(&cod.values)
+}
+
+// Labels returns the human readable labels of the CoreOptionV2Definition
+// values, empty when the value itself should be displayed
+func (cod *CoreOptionV2Definition) Labels() []string {
+	return optionLabels(&cod.values)
+}
+
+// DefaultValue returns the default value of a CoreOptionV2Definition as a string
+func (cod *CoreOptionV2Definition) DefaultValue() string {
 	return C.GoString(cod.default_value)
 }
 
@@ -280,8 +424,24 @@ const (
 	// Positive Y axis is down.
 	// Only use ANALOG type when polling for analog values of the axes.
 	DeviceAnalog = uint32(C.RETRO_DEVICE_ANALOG)
+
+	// DevicePointer is an abstraction around touch input, like a touchscreen.
+	// Coordinates are absolute, between -0x7fff and 0x7fff over the screen.
+	DevicePointer = uint32(C.RETRO_DEVICE_POINTER)
+
+	// DeviceTypeShift is the shift applied to the subclass of a device type
+	DeviceTypeShift = uint32(C.RETRO_DEVICE_TYPE_SHIFT)
+
+	// DeviceMask extracts the base device type from a subclass
+	DeviceMask = uint32(C.RETRO_DEVICE_MASK)
 )
 
+// DeviceSubclass returns the id of a specialized version of a base device
+// type, like a multitap or a Justifier
+func DeviceSubclass(base uint32, id uint32) uint32 {
+	return ((id + 1) << DeviceTypeShift) | base
+}
+
 // Buttons for the RetroPad (JOYPAD).
 // The placement of these is equivalent to placements on the
 // Super Nintendo controller.
@@ -334,6 +494,42 @@ const (
 	DeviceIDMouseButton5        = uint32(C.RETRO_DEVICE_ID_MOUSE_BUTTON_5)
 )
 
+// Id values for LIGHTGUN. Screen coordinates are absolute, between -0x7fff and
+// 0x7fff over the game screen.
+const (
+	DeviceIDLightgunScreenX     = uint32(C.RETRO_DEVICE_ID_LIGHTGUN_SCREEN_X)
+	DeviceIDLightgunScreenY     = uint32(C.RETRO_DEVICE_ID_LIGHTGUN_SCREEN_Y)
+	DeviceIDLightgunIsOffscreen = uint32(C.RETRO_DEVICE_ID_LIGHTGUN_IS_OFFSCREEN)
+	DeviceIDLightgunTrigger     = uint32(C.RETRO_DEVICE_ID_LIGHTGUN_TRIGGER)
+	DeviceIDLightgunReload      = uint32(C.RETRO_DEVICE_ID_LIGHTGUN_RELOAD)
+	DeviceIDLightgunAuxA        = uint32(C.RETRO_DEVICE_ID_LIGHTGUN_AUX_A)
+	DeviceIDLightgunAuxB        = uint32(C.RETRO_DEVICE_ID_LIGHTGUN_AUX_B)
+	DeviceIDLightgunStart       = uint32(C.RETRO_DEVICE_ID_LIGHTGUN_START)
+	DeviceIDLightgunSelect      = uint32(C.RETRO_DEVICE_ID_LIGHTGUN_SELECT)
+	DeviceIDLightgunAuxC        = uint32(C.RETRO_DEVICE_ID_LIGHTGUN_AUX_C)
+	DeviceIDLightgunDpadUp      = uint32(C.RETRO_DEVICE_ID_LIGHTGUN_DPAD_UP)
+	DeviceIDLightgunDpadDown    = uint32(C.RETRO_DEVICE_ID_LIGHTGUN_DPAD_DOWN)
+	DeviceIDLightgunDpadLeft    = uint32(C.RETRO_DEVICE_ID_LIGHTGUN_DPAD_LEFT)
+	DeviceIDLightgunDpadRight   = uint32(C.RETRO_DEVICE_ID_LIGHTGUN_DPAD_RIGHT)
+	// Deprecated relative coordinates, reported like a mouse
+	DeviceIDLightgunX = uint32(C.RETRO_DEVICE_ID_LIGHTGUN_X)
+	DeviceIDLightgunY = uint32(C.RETRO_DEVICE_ID_LIGHTGUN_Y)
+)
+
+// Id values for POINTER.
+const (
+	DeviceIDPointerX       = uint32(C.RETRO_DEVICE_ID_POINTER_X)
+	DeviceIDPointerY       = uint32(C.RETRO_DEVICE_ID_POINTER_Y)
+	DeviceIDPointerPressed = uint32(C.RETRO_DEVICE_ID_POINTER_PRESSED)
+	DeviceIDPointerCount   = uint32(C.RETRO_DEVICE_ID_POINTER_COUNT)
+)
+
+// Rumble effects, the two motors of a gamepad
+const (
+	RumbleStrong = uint32(C.RETRO_RUMBLE_STRONG)
+	RumbleWeak   = uint32(C.RETRO_RUMBLE_WEAK)
+)
+
 // Environment callback API. See libretro.h for details
 const (
 	EnvironmentSetRotation                      = uint32(C.RETRO_ENVIRONMENT_SET_ROTATION)
@@ -392,6 +588,23 @@ const (
 	EnvironmentGetPrefferedHWRender             = uint32(C.RETRO_ENVIRONMENT_GET_PREFERRED_HW_RENDER)
 	EnvironmentGetDiskControlInterfaceVersion   = uint32(C.RETRO_ENVIRONMENT_GET_DISK_CONTROL_INTERFACE_VERSION)
 	EnvironmentGetDiskControlExtInterface       = uint32(C.RETRO_ENVIRONMENT_SET_DISK_CONTROL_EXT_INTERFACE)
+	EnvironmentGetMessageInterfaceVersion       = uint32(C.RETRO_ENVIRONMENT_GET_MESSAGE_INTERFACE_VERSION)
+	EnvironmentSetMessageExt                    = uint32(C.RETRO_ENVIRONMENT_SET_MESSAGE_EXT)
+	EnvironmentSetCoreOptionsV2                 = uint32(C.RETRO_ENVIRONMENT_SET_CORE_OPTIONS_V2)
+	EnvironmentSetCoreOptionsV2Intl             = uint32(C.RETRO_ENVIRONMENT_SET_CORE_OPTIONS_V2_INTL)
+	EnvironmentSetCoreOptionsUpdateDisplayCb    = uint32(C.RETRO_ENVIRONMENT_SET_CORE_OPTIONS_UPDATE_DISPLAY_CALLBACK)
+)
+
+// Message targets and types. See libretro.h for details
+const (
+	MessageTargetAll = uint32(C.RETRO_MESSAGE_TARGET_ALL)
+	MessageTargetOSD = uint32(C.RETRO_MESSAGE_TARGET_OSD)
+	MessageTargetLog = uint32(C.RETRO_MESSAGE_TARGET_LOG)
+
+	MessageTypeNotification    = uint32(C.RETRO_MESSAGE_TYPE_NOTIFICATION)
+	MessageTypeNotificationAlt = uint32(C.RETRO_MESSAGE_TYPE_NOTIFICATION_ALT)
+	MessageTypeStatus          = uint32(C.RETRO_MESSAGE_TYPE_STATUS)
+	MessageTypeProgress        = uint32(C.RETRO_MESSAGE_TYPE_PROGRESS)
 )
 
 // Debug levels
@@ -412,6 +625,26 @@ const (
 	MemoryVideoRAM  = uint32(C.RETRO_MEMORY_VIDEO_RAM)
 )
 
+// Memory descriptor flags
+const (
+	MemDescConst     = uint64(C.RETRO_MEMDESC_CONST)
+	MemDescBigEndian = uint64(C.RETRO_MEMDESC_BIGENDIAN)
+	MemDescSystemRAM = uint64(C.RETRO_MEMDESC_SYSTEM_RAM)
+	MemDescSaveRAM   = uint64(C.RETRO_MEMDESC_SAVE_RAM)
+	MemDescVideoRAM  = uint64(C.RETRO_MEMDESC_VIDEO_RAM)
+)
+
+// Serialization quirks reported by the core
+const (
+	SerializationQuirkIncomplete        = uint64(C.RETRO_SERIALIZATION_QUIRK_INCOMPLETE)
+	SerializationQuirkMustInitialize    = uint64(C.RETRO_SERIALIZATION_QUIRK_MUST_INITIALIZE)
+	SerializationQuirkCoreVariableSize  = uint64(C.RETRO_SERIALIZATION_QUIRK_CORE_VARIABLE_SIZE)
+	SerializationQuirkFrontVariableSize = uint64(C.RETRO_SERIALIZATION_QUIRK_FRONT_VARIABLE_SIZE)
+	SerializationQuirkSingleSession     = uint64(C.RETRO_SERIALIZATION_QUIRK_SINGLE_SESSION)
+	SerializationQuirkEndianDependent   = uint64(C.RETRO_SERIALIZATION_QUIRK_ENDIAN_DEPENDENT)
+	SerializationQuirkPlatformDependent = uint64(C.RETRO_SERIALIZATION_QUIRK_PLATFORM_DEPENDENT)
+)
+
 type (
 	environmentFunc      func(uint32, unsafe.Pointer) bool
 	videoRefreshFunc     func(unsafe.Pointer, int32, int32, int32)
@@ -421,6 +654,7 @@ type (
 	inputStateFunc       func(uint, uint32, uint, uint) int16
 	logFunc              func(uint32, string)
 	getTimeUsecFunc      func() int64
+	setRumbleStateFunc   func(uint, uint32, uint16) bool
 )
 
 var (
@@ -432,6 +666,7 @@ var (
 	inputState       inputStateFunc
 	log              logFunc
 	getTimeUsec      getTimeUsecFunc
+	setRumbleState   setRumbleStateFunc
 )
 
 // Load dynamically loads a libretro core at the given path and returns a Core instance
@@ -461,12 +696,15 @@ func Load(sofile string) (*Core, error) {
 	core.symRetroRun = DlSym(core.handle, "retro_run")
 	core.symRetroReset = DlSym(core.handle, "retro_reset")
 	core.symRetroLoadGame = DlSym(core.handle, "retro_load_game")
+	core.symRetroLoadGameSpecial = DlSym(core.handle, "retro_load_game_special")
 	core.symRetroUnloadGame = DlSym(core.handle, "retro_unload_game")
 	core.symRetroSerializeSize = DlSym(core.handle, "retro_serialize_size")
 	core.symRetroSerialize = DlSym(core.handle, "retro_serialize")
 	core.symRetroUnserialize = DlSym(core.handle, "retro_unserialize")
 	core.symRetroGetMemorySize = DlSym(core.handle, "retro_get_memory_size")
 	core.symRetroGetMemoryData = DlSym(core.handle, "retro_get_memory_data")
+	core.symRetroCheatReset = DlSym(core.handle, "retro_cheat_reset")
+	core.symRetroCheatSet = DlSym(core.handle, "retro_cheat_set")
 
 	return &core, nil
 }
@@ -487,6 +725,11 @@ func (core *Core) Deinit() {
 	C.bridge_retro_deinit(core.symRetroDeinit)
 	DlClose(core.handle)
 	core.MemoryMap = nil
+	core.Subsystems = nil
+	core.Controllers = nil
+	core.InputDescriptors = nil
+	core.VFS = false
+	closeVFS()
 	environment = nil
 	videoRefresh = nil
 	audioSample = nil
@@ -495,6 +738,7 @@ func (core *Core) Deinit() {
 	inputState = nil
 	log = nil
 	getTimeUsec = nil
+	setRumbleState = nil
 }
 
 // Run runs the game for one video frame.
@@ -512,6 +756,19 @@ func (core *Core) Reset() {
 	C.bridge_retro_reset(core.symRetroReset)
 }
 
+// CheatReset disables all the cheats previously set.
+func (core *Core) CheatReset() {
+	C.bridge_retro_cheat_reset(core.symRetroCheatReset)
+}
+
+// CheatSet enables or disables a cheat code at a given index. The format of
+// the code is up to the core, usually Game Genie or Action Replay codes.
+func (core *Core) CheatSet(index uint, enabled bool, code string) {
+	cs := C.CString(code)
+	defer C.free(unsafe.Pointer(cs))
+	C.bridge_retro_cheat_set(core.symRetroCheatSet, C.unsigned(index), C.bool(enabled), cs)
+}
+
 // GetSystemInfo returns statically known system info. Pointers provided in *info
 // must be statically allocated.
 // Can be called at any time, even before retro_init().
@@ -560,6 +817,33 @@ func (core *Core) LoadGame(gi GameInfo) bool {
 	return bool(C.bridge_retro_load_game(core.symRetroLoadGame, &rgi))
 }
 
+// LoadGameSpecial loads a game made of several ROMs through a subsystem.
+// The order of the GameInfo must follow the order of the subsystem ROMs.
+func (core *Core) LoadGameSpecial(id uint, gis []GameInfo) bool {
+	if core.symRetroLoadGameSpecial == nil || len(gis) == 0 {
+		return false
+	}
+	rgis := make([]C.struct_retro_game_info, len(gis))
+	for i, gi := range gis {
+		// Optional ROMs that were skipped are passed as an empty game info
+		if gi.Path == "" {
+			continue
+		}
+		rgis[i].path = C.CString(gi.Path)
+		rgis[i].size = C.size_t(gi.Size)
+		rgis[i].data = gi.Data
+	}
+	// The paths are only valid during the call, cores copy what they keep
+	defer func() {
+		for i := range rgis {
+			if rgis[i].path != nil {
+				C.free(unsafe.Pointer(rgis[i].path))
+			}
+		}
+	}()
+	return bool(C.bridge_retro_load_game_special(core.symRetroLoadGameSpecial, C.unsigned(id), &rgis[0], C.size_t(len(gis))))
+}
+
 // SerializeSize returns the amount of data the implementation requires to serialize
 // internal state (save states).
 // Between calls to retro_load_game() and retro_unload_game(), the
@@ -650,6 +934,13 @@ func (core *Core) BindLogCallback(data unsafe.Pointer, f logFunc) {
 	cb.log = (C.retro_log_printf_t)(C.coreLog_cgo)
 }
 
+// BindRumbleInterface binds f to the rumble interface set_rumble_state
+func (core *Core) BindRumbleInterface(data unsafe.Pointer, f setRumbleStateFunc) {
+	setRumbleState = f
+	cb := (*C.struct_retro_rumble_interface)(data)
+	cb.set_rumble_state = (C.retro_set_rumble_state_t)(C.coreSetRumbleState_cgo)
+}
+
 // BindPerfCallback binds f to the perf callback get_time_usec
 func (core *Core) BindPerfCallback(data unsafe.Pointer, f getTimeUsecFunc) {
 	getTimeUsec = f
@@ -726,6 +1017,14 @@ func coreGetTimeUsec() C.uint64_t {
 	return C.uint64_t(getTimeUsec())
 }
 
+//export coreSetRumbleState
+func coreSetRumbleState(port C.unsigned, effect C.enum_retro_rumble_effect, strength C.uint16_t) C.bool {
+	if setRumbleState == nil {
+		return false
+	}
+	return C.bool(setRumbleState(uint(port), uint32(effect), uint16(strength)))
+}
+
 // SetData is a setter for the data of a GameInfo type
 func (gi *GameInfo) SetData(bytes []byte) {
 	cstr := C.CString(string(bytes))
@@ -805,6 +1104,104 @@ func GetCoreOptionsIntl(data unsafe.Pointer) []CoreOptionDefinition {
 	return definitions
 }
 
+// getCoreOptionsV2 reads the NULL terminated categories and definitions of a
+// retro_core_options_v2 struct
+func getCoreOptionsV2(opts *C.struct_retro_core_options_v2) ([]CoreOptionV2Category, []CoreOptionV2Definition) {
+	var categories []CoreOptionV2Category
+	var definitions []CoreOptionV2Definition
+	if opts == nil {
+		return categories, definitions
+	}
+
+	if opts.categories != nil {
+		for c := opts.categories; c.key != nil; c = (*C.struct_retro_core_option_v2_category)(unsafe.Pointer(uintptr(unsafe.Pointer(c)) + unsafe.Sizeof(*c))) {
+			categories = append(categories, *(*CoreOptionV2Category)(c))
+		}
+	}
+
+	if opts.definitions != nil {
+		for d := opts.definitions; d.key != nil; d = (*C.struct_retro_core_option_v2_definition)(unsafe.Pointer(uintptr(unsafe.Pointer(d)) + unsafe.Sizeof(*d))) {
+			definitions = append(definitions, *(*CoreOptionV2Definition)(d))
+		}
+	}
+
+	return categories, definitions
+}
+
+// GetCoreOptionsV2 is an environment callback helper that returns the option
+// categories and the options needed by a core
+func GetCoreOptionsV2(data unsafe.Pointer) ([]CoreOptionV2Category, []CoreOptionV2Definition) {
+	return getCoreOptionsV2((*C.struct_retro_core_options_v2)(data))
+}
+
+// GetCoreOptionsV2Intl is an environment callback helper that returns the US
+// English option categories and options needed by a core
+func GetCoreOptionsV2Intl(data unsafe.Pointer) ([]CoreOptionV2Category, []CoreOptionV2Definition) {
+	intl := (*C.struct_retro_core_options_v2_intl)(data)
+	return getCoreOptionsV2(intl.us)
+}
+
+// GetCoreOptionDisplay is an environment callback helper that returns the key
+// of an option and whether the core wants it displayed
+func GetCoreOptionDisplay(data unsafe.Pointer) (string, bool) {
+	d := (*C.struct_retro_core_option_display)(data)
+	return C.GoString(d.key), bool(d.visible)
+}
+
+// SetCoreOptionsUpdateDisplayCallback is an environment callback helper to set
+// the OptionsUpdateDisplayCallback
+func (core *Core) SetCoreOptionsUpdateDisplayCallback(data unsafe.Pointer) {
+	c := *(*C.struct_retro_core_options_update_display_callback)(data)
+	if c.callback == nil {
+		core.OptionsUpdateDisplayCallback = nil
+		return
+	}
+	core.OptionsUpdateDisplayCallback = func() bool {
+		return bool(C.bridge_retro_core_options_update_display(c.callback))
+	}
+}
+
+// Message is a message sent by the core to be displayed or logged
+type Message struct {
+	Msg      string
+	Duration float32 // in seconds
+	Priority uint
+	Level    uint32
+	Target   uint32
+	Type     uint32
+	Progress int8 // in percent, -1 if unknown
+}
+
+// GetMessage is an environment callback helper that returns the message sent
+// with EnvironmentSetMessage. The duration in frames is converted to seconds
+// using fps.
+func GetMessage(data unsafe.Pointer, fps float64) Message {
+	m := (*C.struct_retro_message)(data)
+	return Message{
+		Msg:      C.GoString(m.msg),
+		Duration: float32(float64(m.frames) / fps),
+		Level:    LogLevelInfo,
+		Target:   MessageTargetAll,
+		Type:     MessageTypeNotification,
+		Progress: -1,
+	}
+}
+
+// GetMessageExt is an environment callback helper that returns the message
+// sent with EnvironmentSetMessageExt
+func GetMessageExt(data unsafe.Pointer) Message {
+	m := (*C.struct_retro_message_ext)(data)
+	return Message{
+		Msg:      C.GoString(m.msg),
+		Duration: float32(m.duration) / 1000,
+		Priority: uint(m.priority),
+		Level:    uint32(m.level),
+		Target:   uint32(m.target),
+		Type:     uint32(m._type),
+		Progress: int8(m.progress),
+	}
+}
+
 // GetMemoryMap is an environment callback helper that returns the list of
 // memory regions EnvironmentSetMemoryMap.
 func GetMemoryMap(data unsafe.Pointer) []MemoryDescriptor {
@@ -817,7 +1214,8 @@ func GetMemoryMap(data unsafe.Pointer) []MemoryDescriptor {
 		descriptors[i] = MemoryDescriptor{
 			Flags:      uint64(d.flags),
 			Ptr:        d.ptr,
-			Offset:     uintptr(d.start),
+			Offset:     uintptr(d.offset),
+			Start:      uintptr(d.start),
 			Select:     uintptr(d._select),
 			Disconnect: uintptr(d.disconnect),
 			Len:        uintptr(d.len),
@@ -827,6 +1225,92 @@ func GetMemoryMap(data unsafe.Pointer) []MemoryDescriptor {
 	return descriptors
 }
 
+// GetSubsystemInfo is an environment callback helper that returns the list of
+// subsystems in EnvironmentSetSubsystemInfo.
+func GetSubsystemInfo(data unsafe.Pointer) []SubsystemInfo {
+	subsystems := []SubsystemInfo{}
+	for i := uintptr(0); ; i++ {
+		cInfo := (*C.struct_retro_subsystem_info)(unsafe.Pointer(uintptr(data) + i*unsafe.Sizeof(C.struct_retro_subsystem_info{})))
+		if cInfo.ident == nil {
+			break
+		}
+
+		info := SubsystemInfo{
+			Desc:  C.GoString(cInfo.desc),
+			Ident: C.GoString(cInfo.ident),
+			ID:    uint(cInfo.id),
+		}
+		for j := uintptr(0); j < uintptr(cInfo.num_roms); j++ {
+			cROM := (*C.struct_retro_subsystem_rom_info)(unsafe.Pointer(uintptr(unsafe.Pointer(cInfo.roms)) + j*unsafe.Sizeof(*cInfo.roms)))
+			rom := SubsystemROMInfo{
+				Desc:            C.GoString(cROM.desc),
+				ValidExtensions: C.GoString(cROM.valid_extensions),
+				NeedFullpath:    bool(cROM.need_fullpath),
+				BlockExtract:    bool(cROM.block_extract),
+				Required:        bool(cROM.required),
+			}
+			for k := uintptr(0); k < uintptr(cROM.num_memory); k++ {
+				cMem := (*C.struct_retro_subsystem_memory_info)(unsafe.Pointer(uintptr(unsafe.Pointer(cROM.memory)) + k*unsafe.Sizeof(*cROM.memory)))
+				rom.Memory = append(rom.Memory, SubsystemMemoryInfo{
+					Extension: C.GoString(cMem.extension),
+					Type:      uint32(cMem._type),
+				})
+			}
+			info.ROMs = append(info.ROMs, rom)
+		}
+		subsystems = append(subsystems, info)
+	}
+	return subsystems
+}
+
+// GetControllerInfo is an environment callback helper that returns the device
+// types accepted on each port in EnvironmentSetControllerInfo.
+func GetControllerInfo(data unsafe.Pointer) [][]ControllerDescription {
+	ports := [][]ControllerDescription{}
+	for i := uintptr(0); ; i++ {
+		cInfo := (*C.struct_retro_controller_info)(unsafe.Pointer(uintptr(data) + i*unsafe.Sizeof(C.struct_retro_controller_info{})))
+		if cInfo.types == nil {
+			break
+		}
+
+		types := []ControllerDescription{}
+		for j := uintptr(0); j < uintptr(cInfo.num_types); j++ {
+			cType := (*C.struct_retro_controller_description)(unsafe.Pointer(uintptr(unsafe.Pointer(cInfo.types)) + j*unsafe.Sizeof(*cInfo.types)))
+			types = append(types, ControllerDescription{
+				Desc: C.GoString(cType.desc),
+				ID:   uint32(cType.id),
+			})
+		}
+		ports = append(ports, types)
+	}
+	return ports
+}
+
+// GetInputDescriptors is an environment callback helper that returns the
+// names of the inputs in EnvironmentSetInputDescriptors.
+func GetInputDescriptors(data unsafe.Pointer) []InputDescriptor {
+	descriptors := []InputDescriptor{}
+	for i := uintptr(0); ; i++ {
+		d := (*C.struct_retro_input_descriptor)(unsafe.Pointer(uintptr(data) + i*unsafe.Sizeof(C.struct_retro_input_descriptor{})))
+		if d.description == nil {
+			break
+		}
+		// Some cores forget the blank entry terminating the array, stop at the
+		// first entry that can't be a descriptor
+		if d.port >= 16 || uint32(d.device)&DeviceMask > uint32(C.RETRO_DEVICE_POINTER) {
+			break
+		}
+		descriptors = append(descriptors, InputDescriptor{
+			Port:        uint(d.port),
+			Device:      uint32(d.device),
+			Index:       uint(d.index),
+			ID:          uint(d.id),
+			Description: C.GoString(d.description),
+		})
+	}
+	return descriptors
+}
+
 // GetGeometry is an environment callback helper that returns the game geometry
 // in EnvironmentSetGeometry.
 func GetGeometry(data unsafe.Pointer) GameGeometry {
@@ -859,6 +1343,11 @@ func GetSystemAVInfo(data unsafe.Pointer) SystemAVInfo {
 	}
 }
 
+// GetBool is an environment callback helper that returns a boolean
+func GetBool(data unsafe.Pointer) bool {
+	return bool(*(*C.bool)(data))
+}
+
 // SetBool is an environment callback helper to set a boolean
 func SetBool(data unsafe.Pointer, val bool) {
 	b := (*C.bool)(data)
@@ -877,6 +1366,12 @@ func SetUint(data unsafe.Pointer, val uint) {
 	*i = C.uint(val)
 }
 
+// SetUint64 is an environment callback helper to set a 64 bits bitmask
+func SetUint64(data unsafe.Pointer, val uint64) {
+	i := (*C.uint64_t)(data)
+	*i = C.uint64_t(val)
+}
+
 // SetFrameTimeCallback is an environment callback helper to set the FrameTimeCallback
 func (core *Core) SetFrameTimeCallback(data unsafe.Pointer) {
 	c := *(*C.struct_retro_frame_time_callback)(data)
@@ -913,13 +1408,75 @@ func (core *Core) GetMemoryData(id uint32) unsafe.Pointer {
 	return C.bridge_retro_get_memory_data(core.symRetroGetMemoryData, C.unsigned(id))
 }
 
-// DiskControlCallback is an interface which frontend can use to eject and insert disk images
+// MemoryPointer returns a pointer to the byte at the given address of the
+// emulated system, following the memory map set by the core. Cores without a
+// memory map expose their system RAM from address 0. It returns nil if the
+// address isn't mapped.
+func (core *Core) MemoryPointer(addr uintptr) unsafe.Pointer {
+	if len(core.MemoryMap) == 0 {
+		size := uintptr(core.GetMemorySize(MemorySystemRAM))
+		ptr := core.GetMemoryData(MemorySystemRAM)
+		if ptr == nil || addr >= size {
+			return nil
+		}
+		return unsafe.Add(ptr, addr)
+	}
+
+	for _, d := range core.MemoryMap {
+		if ptr := d.Pointer(addr); ptr != nil {
+			return ptr
+		}
+	}
+	return nil
+}
+
+// Pointer returns a pointer to the byte at the given address if the address
+// belongs to the descriptor, nil otherwise.
+func (d *MemoryDescriptor) Pointer(addr uintptr) unsafe.Pointer {
+	if d.Ptr == nil {
+		return nil
+	}
+	if d.Select != 0 {
+		if addr&d.Select != d.Start&d.Select {
+			return nil
+		}
+	} else if addr < d.Start || (d.Len != 0 && addr >= d.Start+d.Len) {
+		return nil
+	}
+	off := reduceAddress(addr-d.Start, d.Disconnect)
+	if d.Len != 0 && off >= d.Len {
+		return nil
+	}
+	return unsafe.Add(d.Ptr, d.Offset+off)
+}
+
+// reduceAddress removes the bits of mask from addr, packing the remaining
+// bits together, as described for the disconnect field of memory descriptors.
+func reduceAddress(addr, mask uintptr) uintptr {
+	for mask != 0 {
+		tmp := (mask - 1) &^ mask
+		addr = (addr & tmp) | ((addr >> 1) &^ tmp)
+		mask = (mask & (mask - 1)) >> 1
+	}
+	return addr
+}
+
+// DiskControlCallback is an interface which frontend can use to eject and insert disk images.
+// The functions of the extended interface are nil when the core doesn't
+// provide them.
 type DiskControlCallback struct {
 	SetEjectState func(bool)
 	GetEjectState func() bool
 	GetImageIndex func() uint
 	SetImageIndex func(uint)
 	GetNumImages  func() uint
+
+	// Extended interface
+	ReplaceImageIndex func(index uint, gi *GameInfo) bool // a nil gi removes the image
+	AddImageIndex     func() bool
+	SetInitialImage   func(index uint, path string) bool
+	GetImagePath      func(index uint) (string, bool)
+	GetImageLabel     func(index uint) (string, bool)
 }
 
 // SetDiskControlCallback sets an interface which frontend can use to eject and insert disk images
@@ -943,3 +1500,73 @@ func (core *Core) SetDiskControlCallback(data unsafe.Pointer) {
 	}
 	core.DiskControlCallback = dcc
 }
+
+// SetDiskControlExtCallback sets the extended disk control interface, which
+// also gives the paths and labels of the disk images
+func (core *Core) SetDiskControlExtCallback(data unsafe.Pointer) {
+	c := *(*C.struct_retro_disk_control_ext_callback)(data)
+
+	// The v0 functions are laid out the same way
+	core.SetDiskControlCallback(data)
+	dcc := core.DiskControlCallback
+
+	if c.replace_image_index != nil {
+		dcc.ReplaceImageIndex = func(index uint, gi *GameInfo) bool {
+			if gi == nil {
+				return bool(C.bridge_retro_replace_image_index(c.replace_image_index, C.unsigned(index), nil))
+			}
+			rgi := C.struct_retro_game_info{}
+			rgi.path = C.CString(gi.Path)
+			rgi.size = C.size_t(gi.Size)
+			rgi.data = gi.Data
+			return bool(C.bridge_retro_replace_image_index(c.replace_image_index, C.unsigned(index), &rgi))
+		}
+	}
+	if c.add_image_index != nil {
+		dcc.AddImageIndex = func() bool {
+			return bool(C.bridge_retro_add_image_index(c.add_image_index))
+		}
+	}
+	if c.set_initial_image != nil {
+		dcc.SetInitialImage = func(index uint, path string) bool {
+			cpath := C.CString(path)
+			defer C.free(unsafe.Pointer(cpath))
+			return bool(C.bridge_retro_set_initial_image(c.set_initial_image, C.unsigned(index), cpath))
+		}
+	}
+	if c.get_image_path != nil {
+		dcc.GetImagePath = func(index uint) (string, bool) {
+			var buf [4096]C.char
+			ok := bool(C.bridge_retro_get_image_path(c.get_image_path, C.unsigned(index), &buf[0], C.size_t(len(buf))))
+			return C.GoString(&buf[0]), ok
+		}
+	}
+	if c.get_image_label != nil {
+		dcc.GetImageLabel = func(index uint) (string, bool) {
+			var buf [4096]C.char
+			ok := bool(C.bridge_retro_get_image_label(c.get_image_label, C.unsigned(index), &buf[0], C.size_t(len(buf))))
+			return C.GoString(&buf[0]), ok
+		}
+	}
+}
+
+// KeyboardCallback notifies the core about keyboard events
+type KeyboardCallback func(down bool, keycode uint, character uint32, modifiers uint16)
+
+// SetKeyboardCallback is an environment callback helper to set the
+// KeyboardCallback
+func (core *Core) SetKeyboardCallback(data unsafe.Pointer) {
+	c := *(*C.struct_retro_keyboard_callback)(data)
+	core.KeyboardCallback = func(down bool, keycode uint, character uint32, modifiers uint16) {
+		C.bridge_retro_keyboard_event(c.callback, C.bool(down), C.unsigned(keycode), C.uint32_t(character), C.uint16_t(modifiers))
+	}
+}
+
+// SetSerializationQuirks is an environment callback helper to store the
+// serialization quirks of the core. The flags we don't support are cleared so
+// the core knows about it.
+func (core *Core) SetSerializationQuirks(data unsafe.Pointer) {
+	q := (*C.uint64_t)(data)
+	*q &^= C.uint64_t(SerializationQuirkFrontVariableSize)
+	core.SerializationQuirks = uint64(*q)
+}
@@ -1287,6 +1287,55 @@ enum retro_mod
                                             * based systems).
                                             */
 
+#define RETRO_ENVIRONMENT_GET_MESSAGE_INTERFACE_VERSION 59
+                                           /* unsigned * --
+                                            * Unsigned value is the API version number of the message
+                                            * interface supported by the frontend. If callback returns
+                                            * false, API version is assumed to be 0.
+                                            *
+                                            * In legacy code, messages may be displayed in an
+                                            * implementation-specific manner via the
+                                            * RETRO_ENVIRONMENT_SET_MESSAGE environment callback.
+                                            * In the most recent interface version (1), messages
+                                            * displayed via RETRO_ENVIRONMENT_SET_MESSAGE_EXT also
+                                            * carry a duration in milliseconds, a priority, a log
+                                            * level, a target and a type.
+                                            */
+
+#define RETRO_ENVIRONMENT_SET_MESSAGE_EXT 60
+                                           /* const struct retro_message_ext * --
+                                            * Sets a message to be displayed in an implementation-specific
+                                            * manner for a certain amount of time, or to be logged.
+                                            * Only valid if GET_MESSAGE_INTERFACE_VERSION returns 1 or above.
+                                            */
+
+#define RETRO_ENVIRONMENT_SET_CORE_OPTIONS_V2 67
+                                           /* const struct retro_core_options_v2 * --
+                                            * Allows an implementation to signal the environment
+                                            * which variables it might want to check for later using
+                                            * GET_VARIABLE, with the options sorted in categories.
+                                            * Only valid if GET_CORE_OPTIONS_VERSION returns 2 or above.
+                                            *
+                                            * Returns true if the frontend displays the categories,
+                                            * in which case desc_categorized and info_categorized
+                                            * are used in place of desc and info.
+                                            */
+
+#define RETRO_ENVIRONMENT_SET_CORE_OPTIONS_V2_INTL 68
+                                           /* const struct retro_core_options_v2_intl * --
+                                            * Same as SET_CORE_OPTIONS_V2, with localised strings
+                                            * like SET_CORE_OPTIONS_INTL.
+                                            */
+
+#define RETRO_ENVIRONMENT_SET_CORE_OPTIONS_UPDATE_DISPLAY_CALLBACK 69
+                                           /* const struct retro_core_options_update_display_callback * --
+                                            * Allows a frontend to signal that a core must update
+                                            * the visibility of any dynamically hidden core options,
+                                            * and enables the frontend to detect visibility changes.
+                                            * The callback returns true if the visibility of any
+                                            * option changed since the last call.
+                                            */
+
 /* VFS functionality */
 
 /* File paths:
@@ -2506,6 +2555,37 @@ struct retro_message
    unsigned    frames;     /* Duration in frames of message. */
 };
 
+/* Defines where a message set with RETRO_ENVIRONMENT_SET_MESSAGE_EXT
+ * should go */
+enum retro_message_target
+{
+   RETRO_MESSAGE_TARGET_ALL = 0, /* On screen and in the log */
+   RETRO_MESSAGE_TARGET_OSD,     /* On screen only */
+   RETRO_MESSAGE_TARGET_LOG      /* In the log only */
+};
+
+/* Defines how a message set with RETRO_ENVIRONMENT_SET_MESSAGE_EXT
+ * should be displayed */
+enum retro_message_type
+{
+   RETRO_MESSAGE_TYPE_NOTIFICATION = 0, /* Standard notification */
+   RETRO_MESSAGE_TYPE_NOTIFICATION_ALT, /* Secondary notification */
+   RETRO_MESSAGE_TYPE_STATUS,           /* Persistent status, like a FPS counter */
+   RETRO_MESSAGE_TYPE_PROGRESS          /* Progress of a long operation */
+};
+
+struct retro_message_ext
+{
+   const char *msg;                  /* Message to be displayed or logged. */
+   unsigned duration;                /* Duration in milliseconds. */
+   unsigned priority;                /* Higher priority messages replace lower ones. */
+   enum retro_log_level level;       /* Severity of the message. */
+   enum retro_message_target target; /* Where the message should go. */
+   enum retro_message_type type;     /* How the message should be displayed. */
+   int8_t progress;                  /* Progress in percent, -1 if unknown.
+                                      * Only for RETRO_MESSAGE_TYPE_PROGRESS. */
+};
+
 /* Describes how the libretro implementation maps a libretro input bind
  * to its internal input system through a human readable string.
  * This string can be used to better let a user configure input. */
@@ -2679,6 +2759,83 @@ struct retro_core_options_intl
    struct retro_core_option_definition *local;
 };
 
+struct retro_core_option_v2_category
+{
+   /* Variable uniquely identifying the
+    * option category. Valid key characters
+    * are [a-z, A-Z, 0-9, _, -] */
+   const char *key;
+
+   /* Human-readable category description */
+   const char *desc;
+
+   /* Human-readable category information,
+    * may be NULL */
+   const char *info;
+};
+
+struct retro_core_option_v2_definition
+{
+   /* Variable to query in RETRO_ENVIRONMENT_GET_VARIABLE. */
+   const char *key;
+
+   /* Human-readable core option description,
+    * used when the frontend doesn't show categories */
+   const char *desc;
+
+   /* Human-readable core option description,
+    * used when the option is shown inside its category.
+    * May be NULL, in which case desc is used */
+   const char *desc_categorized;
+
+   /* Human-readable core option information, may be NULL */
+   const char *info;
+
+   /* Same as info for options shown inside their category.
+    * May be NULL, in which case info is used */
+   const char *info_categorized;
+
+   /* Key of the category the option belongs to, may be NULL */
+   const char *category_key;
+
+   /* Array of retro_core_option_value structs, terminated by NULL */
+   struct retro_core_option_value values[RETRO_NUM_CORE_OPTION_VALUES_MAX];
+
+   /* Default core option value. Must match one of the values
+    * in the retro_core_option_value array */
+   const char *default_value;
+};
+
+struct retro_core_options_v2
+{
+   /* Array of retro_core_option_v2_category structs,
+    * terminated by NULL. May be NULL */
+   struct retro_core_option_v2_category *categories;
+
+   /* Array of retro_core_option_v2_definition structs,
+    * terminated by NULL */
+   struct retro_core_option_v2_definition *definitions;
+};
+
+struct retro_core_options_v2_intl
+{
+   /* US English implementation, must point to a valid struct */
+   struct retro_core_options_v2 *us;
+
+   /* Implementation for current frontend language, may be NULL */
+   struct retro_core_options_v2 *local;
+};
+
+/* Used by the frontend to ask the core to update the visibility
+ * of its options. Returns true if any option visibility changed
+ * since the last call. */
+typedef bool (RETRO_CALLCONV *retro_core_options_update_display_callback_t)(void);
+
+struct retro_core_options_update_display_callback
+{
+   retro_core_options_update_display_callback_t callback;
+};
+
 struct retro_game_info
 {
    const char *path;       /* Path to game, UTF-8 encoded.
@@ -21,16 +21,30 @@ type Core struct {
 	symRetroRun                     unsafe.Pointer
 	symRetroReset                   unsafe.Pointer
 	symRetroLoadGame                unsafe.Pointer
+	symRetroLoadGameSpecial         unsafe.Pointer
 	symRetroUnloadGame              unsafe.Pointer
 	symRetroSerializeSize           unsafe.Pointer
 	symRetroSerialize               unsafe.Pointer
 	symRetroUnserialize             unsafe.Pointer
 	symRetroGetMemorySize           unsafe.Pointer
 	symRetroGetMemoryData           unsafe.Pointer
+	symRetroCheatReset              unsafe.Pointer
+	symRetroCheatSet                unsafe.Pointer
 
 	AudioCallback       *AudioCallback
 	FrameTimeCallback   *FrameTimeCallback
 	DiskControlCallback *DiskControlCallback
+	KeyboardCallback    KeyboardCallback
 
-	MemoryMap []MemoryDescriptor
+	// OptionsUpdateDisplayCallback asks the core to update the visibility of
+	// its options, it returns true if any changed
+	OptionsUpdateDisplayCallback func() bool
+
+	MemoryMap            []MemoryDescriptor
+	Subsystems           []SubsystemInfo
+	Controllers          [][]ControllerDescription
+	InputDescriptors     []InputDescriptor
+	SerializationQuirks  uint64
+	SupportsAchievements bool
+	VFS                  bool // the core opens its files through the VFS interface
 }
@@ -0,0 +1,439 @@
+package libretro
+
+/*
+#include "libretro.h"
+#include <stdbool.h>
+#include <stdlib.h>
+
+// The handles are opaque to the cores, they only point to our handle tables
+struct retro_vfs_file_handle {
+	uint64_t id;
+	char *path;
+};
+
+struct retro_vfs_dir_handle {
+	uint64_t id;
+	char *name;
+};
+
+const char *coreVFSGetPath_cgo(struct retro_vfs_file_handle *stream);
+struct retro_vfs_file_handle *coreVFSOpen_cgo(const char *path, unsigned mode, unsigned hints);
+int coreVFSClose_cgo(struct retro_vfs_file_handle *stream);
+int64_t coreVFSSize_cgo(struct retro_vfs_file_handle *stream);
+int64_t coreVFSTruncate_cgo(struct retro_vfs_file_handle *stream, int64_t length);
+int64_t coreVFSTell_cgo(struct retro_vfs_file_handle *stream);
+int64_t coreVFSSeek_cgo(struct retro_vfs_file_handle *stream, int64_t offset, int seek_position);
+int64_t coreVFSRead_cgo(struct retro_vfs_file_handle *stream, void *s, uint64_t len);
+int64_t coreVFSWrite_cgo(struct retro_vfs_file_handle *stream, const void *s, uint64_t len);
+int coreVFSFlush_cgo(struct retro_vfs_file_handle *stream);
+int coreVFSRemove_cgo(const char *path);
+int coreVFSRename_cgo(const char *old_path, const char *new_path);
+int coreVFSStat_cgo(const char *path, int32_t *size);
+int coreVFSMkdir_cgo(const char *dir);
+struct retro_vfs_dir_handle *coreVFSOpendir_cgo(const char *dir, bool include_hidden);
+bool coreVFSReaddir_cgo(struct retro_vfs_dir_handle *dirstream);
+const char *coreVFSDirentGetName_cgo(struct retro_vfs_dir_handle *dirstream);
+bool coreVFSDirentIsDir_cgo(struct retro_vfs_dir_handle *dirstream);
+int coreVFSClosedir_cgo(struct retro_vfs_dir_handle *dirstream);
+*/
+import "C"
+import (
+	"io"
+	"os"
+	"sync"
+	"unsafe"
+)
+
+// VFSVersion is the version of the VFS interface we implement
+const VFSVersion = 3
+
+// File access modes of the VFS interface
+const (
+	VFSFileAccessRead           = uint32(C.RETRO_VFS_FILE_ACCESS_READ)
+	VFSFileAccessWrite          = uint32(C.RETRO_VFS_FILE_ACCESS_WRITE)
+	VFSFileAccessReadWrite      = uint32(C.RETRO_VFS_FILE_ACCESS_READ_WRITE)
+	VFSFileAccessUpdateExisting = uint32(C.RETRO_VFS_FILE_ACCESS_UPDATE_EXISTING)
+)
+
+// VFSFile is a file opened by a core through the VFS interface
+type VFSFile interface {
+	io.ReadWriteSeeker
+	io.Closer
+	Size() (int64, error)
+	Truncate(size int64) error
+	Sync() error
+}
+
+// VFSDirEntry is an entry of a directory listed through the VFS interface
+type VFSDirEntry struct {
+	Name  string
+	IsDir bool
+}
+
+// FileSystem serves the files and directories requested by the core through
+// the VFS interface
+type FileSystem interface {
+	Open(path string, mode uint32) (VFSFile, error)
+	Remove(path string) error
+	Rename(oldPath, newPath string) error
+	Stat(path string) (size int64, isDir bool, err error)
+	Mkdir(dir string) error
+	ReadDir(dir string, includeHidden bool) ([]VFSDirEntry, error)
+}
+
+type vfsFile struct {
+	file VFSFile
+	c    *C.struct_retro_vfs_file_handle
+}
+
+type vfsDir struct {
+	entries []VFSDirEntry
+	pos     int
+	c       *C.struct_retro_vfs_dir_handle
+}
+
+var (
+	fileSystem FileSystem
+	vfsIface   *C.struct_retro_vfs_interface
+	vfsFiles   = map[uint64]*vfsFile{}
+	vfsDirs    = map[uint64]*vfsDir{}
+	vfsLastID  uint64
+	vfsMutex   sync.Mutex
+)
+
+// BindVFSInterface hands the VFS interface to the core, serving its files
+// with fs. It returns false if the core requires a newer version.
+func (core *Core) BindVFSInterface(data unsafe.Pointer, fs FileSystem) bool {
+	info := (*C.struct_retro_vfs_interface_info)(data)
+	if info.required_interface_version > VFSVersion {
+		return false
+	}
+
+	if vfsIface == nil {
+		vfsIface = (*C.struct_retro_vfs_interface)(C.calloc(1, C.sizeof_struct_retro_vfs_interface))
+		vfsIface.get_path = (C.retro_vfs_get_path_t)(C.coreVFSGetPath_cgo)
+		vfsIface.open = (C.retro_vfs_open_t)(C.coreVFSOpen_cgo)
+		vfsIface.close = (C.retro_vfs_close_t)(C.coreVFSClose_cgo)
+		vfsIface.size = (C.retro_vfs_size_t)(C.coreVFSSize_cgo)
+		vfsIface.truncate = (C.retro_vfs_truncate_t)(C.coreVFSTruncate_cgo)
+		vfsIface.tell = (C.retro_vfs_tell_t)(C.coreVFSTell_cgo)
+		vfsIface.seek = (C.retro_vfs_seek_t)(C.coreVFSSeek_cgo)
+		vfsIface.read = (C.retro_vfs_read_t)(C.coreVFSRead_cgo)
+		vfsIface.write = (C.retro_vfs_write_t)(C.coreVFSWrite_cgo)
+		vfsIface.flush = (C.retro_vfs_flush_t)(C.coreVFSFlush_cgo)
+		vfsIface.remove = (C.retro_vfs_remove_t)(C.coreVFSRemove_cgo)
+		vfsIface.rename = (C.retro_vfs_rename_t)(C.coreVFSRename_cgo)
+		vfsIface.stat = (C.retro_vfs_stat_t)(C.coreVFSStat_cgo)
+		vfsIface.mkdir = (C.retro_vfs_mkdir_t)(C.coreVFSMkdir_cgo)
+		vfsIface.opendir = (C.retro_vfs_opendir_t)(C.coreVFSOpendir_cgo)
+		vfsIface.readdir = (C.retro_vfs_readdir_t)(C.coreVFSReaddir_cgo)
+		vfsIface.dirent_get_name = (C.retro_vfs_dirent_get_name_t)(C.coreVFSDirentGetName_cgo)
+		vfsIface.dirent_is_dir = (C.retro_vfs_dirent_is_dir_t)(C.coreVFSDirentIsDir_cgo)
+		vfsIface.closedir = (C.retro_vfs_closedir_t)(C.coreVFSClosedir_cgo)
+	}
+
+	fileSystem = fs
+	core.VFS = true
+	info.required_interface_version = VFSVersion
+	info.iface = vfsIface
+	return true
+}
+
+// closeVFS closes the files and directories the core left open
+func closeVFS() {
+	vfsMutex.Lock()
+	defer vfsMutex.Unlock()
+
+	for id, f := range vfsFiles {
+		f.file.Close()
+		C.free(unsafe.Pointer(f.c.path))
+		C.free(unsafe.Pointer(f.c))
+		delete(vfsFiles, id)
+	}
+	for id, d := range vfsDirs {
+		C.free(unsafe.Pointer(d.c.name))
+		C.free(unsafe.Pointer(d.c))
+		delete(vfsDirs, id)
+	}
+	fileSystem = nil
+}
+
+func lookupFile(stream *C.struct_retro_vfs_file_handle) *vfsFile {
+	if stream == nil {
+		return nil
+	}
+	vfsMutex.Lock()
+	defer vfsMutex.Unlock()
+	return vfsFiles[uint64(stream.id)]
+}
+
+func lookupDir(dirstream *C.struct_retro_vfs_dir_handle) *vfsDir {
+	if dirstream == nil {
+		return nil
+	}
+	vfsMutex.Lock()
+	defer vfsMutex.Unlock()
+	return vfsDirs[uint64(dirstream.id)]
+}
+
+//export coreVFSGetPath
+func coreVFSGetPath(stream *C.struct_retro_vfs_file_handle) *C.char {
+	if stream == nil {
+		return nil
+	}
+	return stream.path
+}
+
+//export coreVFSOpen
+func coreVFSOpen(path *C.char, mode C.unsigned, hints C.unsigned) *C.struct_retro_vfs_file_handle {
+	if fileSystem == nil || path == nil {
+		return nil
+	}
+	file, err := fileSystem.Open(C.GoString(path), uint32(mode))
+	if err != nil {
+		return nil
+	}
+
+	vfsMutex.Lock()
+	defer vfsMutex.Unlock()
+	vfsLastID++
+	h := (*C.struct_retro_vfs_file_handle)(C.calloc(1, C.sizeof_struct_retro_vfs_file_handle))
+	h.id = C.uint64_t(vfsLastID)
+	h.path = C.CString(C.GoString(path))
+	vfsFiles[vfsLastID] = &vfsFile{file: file, c: h}
+	return h
+}
+
+//export coreVFSClose
+func coreVFSClose(stream *C.struct_retro_vfs_file_handle) C.int {
+	f := lookupFile(stream)
+	if f == nil {
+		return -1
+	}
+
+	vfsMutex.Lock()
+	delete(vfsFiles, uint64(stream.id))
+	vfsMutex.Unlock()
+
+	err := f.file.Close()
+	C.free(unsafe.Pointer(f.c.path))
+	C.free(unsafe.Pointer(f.c))
+	if err != nil {
+		return -1
+	}
+	return 0
+}
+
+//export coreVFSSize
+func coreVFSSize(stream *C.struct_retro_vfs_file_handle) C.int64_t {
+	f := lookupFile(stream)
+	if f == nil {
+		return -1
+	}
+	size, err := f.file.Size()
+	if err != nil {
+		return -1
+	}
+	return C.int64_t(size)
+}
+
+//export coreVFSTruncate
+func coreVFSTruncate(stream *C.struct_retro_vfs_file_handle, length C.int64_t) C.int64_t {
+	f := lookupFile(stream)
+	if f == nil || f.file.Truncate(int64(length)) != nil {
+		return -1
+	}
+	return 0
+}
+
+//export coreVFSTell
+func coreVFSTell(stream *C.struct_retro_vfs_file_handle) C.int64_t {
+	f := lookupFile(stream)
+	if f == nil {
+		return -1
+	}
+	pos, err := f.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return -1
+	}
+	return C.int64_t(pos)
+}
+
+//export coreVFSSeek
+func coreVFSSeek(stream *C.struct_retro_vfs_file_handle, offset C.int64_t, position C.int) C.int64_t {
+	f := lookupFile(stream)
+	if f == nil {
+		return -1
+	}
+	whence := io.SeekStart
+	switch position {
+	case C.RETRO_VFS_SEEK_POSITION_CURRENT:
+		whence = io.SeekCurrent
+	case C.RETRO_VFS_SEEK_POSITION_END:
+		whence = io.SeekEnd
+	}
+	pos, err := f.file.Seek(int64(offset), whence)
+	if err != nil {
+		return -1
+	}
+	return C.int64_t(pos)
+}
+
+//export coreVFSRead
+func coreVFSRead(stream *C.struct_retro_vfs_file_handle, s unsafe.Pointer, length C.uint64_t) C.int64_t {
+	f := lookupFile(stream)
+	if f == nil {
+		return -1
+	}
+	if length == 0 {
+		return 0
+	}
+	n, err := io.ReadFull(f.file, unsafe.Slice((*byte)(s), int(length)))
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return -1
+	}
+	return C.int64_t(n)
+}
+
+//export coreVFSWrite
+func coreVFSWrite(stream *C.struct_retro_vfs_file_handle, s unsafe.Pointer, length C.uint64_t) C.int64_t {
+	f := lookupFile(stream)
+	if f == nil {
+		return -1
+	}
+	if length == 0 {
+		return 0
+	}
+	n, err := f.file.Write(C.GoBytes(s, C.int(length)))
+	if err != nil {
+		return -1
+	}
+	return C.int64_t(n)
+}
+
+//export coreVFSFlush
+func coreVFSFlush(stream *C.struct_retro_vfs_file_handle) C.int {
+	f := lookupFile(stream)
+	if f == nil || f.file.Sync() != nil {
+		return -1
+	}
+	return 0
+}
+
+//export coreVFSRemove
+func coreVFSRemove(path *C.char) C.int {
+	if fileSystem == nil || path == nil || fileSystem.Remove(C.GoString(path)) != nil {
+		return -1
+	}
+	return 0
+}
+
+//export coreVFSRename
+func coreVFSRename(oldPath *C.char, newPath *C.char) C.int {
+	if fileSystem == nil || oldPath == nil || newPath == nil {
+		return -1
+	}
+	if fileSystem.Rename(C.GoString(oldPath), C.GoString(newPath)) != nil {
+		return -1
+	}
+	return 0
+}
+
+//export coreVFSStat
+func coreVFSStat(path *C.char, size *C.int32_t) C.int {
+	if fileSystem == nil || path == nil {
+		return 0
+	}
+	n, isDir, err := fileSystem.Stat(C.GoString(path))
+	if err != nil {
+		return 0
+	}
+	if size != nil {
+		*size = C.int32_t(n)
+	}
+	flags := C.RETRO_VFS_STAT_IS_VALID
+	if isDir {
+		flags |= C.RETRO_VFS_STAT_IS_DIRECTORY
+	}
+	return C.int(flags)
+}
+
+//export coreVFSMkdir
+func coreVFSMkdir(dir *C.char) C.int {
+	if fileSystem == nil || dir == nil {
+		return -1
+	}
+	err := fileSystem.Mkdir(C.GoString(dir))
+	if os.IsExist(err) {
+		return -2
+	}
+	if err != nil {
+		return -1
+	}
+	return 0
+}
+
+//export coreVFSOpendir
+func coreVFSOpendir(dir *C.char, includeHidden C.bool) *C.struct_retro_vfs_dir_handle {
+	if fileSystem == nil || dir == nil {
+		return nil
+	}
+	entries, err := fileSystem.ReadDir(C.GoString(dir), bool(includeHidden))
+	if err != nil {
+		return nil
+	}
+
+	vfsMutex.Lock()
+	defer vfsMutex.Unlock()
+	vfsLastID++
+	h := (*C.struct_retro_vfs_dir_handle)(C.calloc(1, C.sizeof_struct_retro_vfs_dir_handle))
+	h.id = C.uint64_t(vfsLastID)
+	vfsDirs[vfsLastID] = &vfsDir{entries: entries, pos: -1, c: h}
+	return h
+}
+
+//export coreVFSReaddir
+func coreVFSReaddir(dirstream *C.struct_retro_vfs_dir_handle) C.bool {
+	d := lookupDir(dirstream)
+	if d == nil || d.pos+1 >= len(d.entries) {
+		return false
+	}
+	d.pos++
+	C.free(unsafe.Pointer(d.c.name))
+	d.c.name = C.CString(d.entries[d.pos].Name)
+	return true
+}
+
+//export coreVFSDirentGetName
+func coreVFSDirentGetName(dirstream *C.struct_retro_vfs_dir_handle) *C.char {
+	d := lookupDir(dirstream)
+	if d == nil || d.pos < 0 {
+		return nil
+	}
+	return d.c.name
+}
+
+//export coreVFSDirentIsDir
+func coreVFSDirentIsDir(dirstream *C.struct_retro_vfs_dir_handle) C.bool {
+	d := lookupDir(dirstream)
+	if d == nil || d.pos < 0 {
+		return false
+	}
+	return C.bool(d.entries[d.pos].IsDir)
+}
+
+//export coreVFSClosedir
+func coreVFSClosedir(dirstream *C.struct_retro_vfs_dir_handle) C.int {
+	d := lookupDir(dirstream)
+	if d == nil {
+		return -1
+	}
+
+	vfsMutex.Lock()
+	delete(vfsDirs, uint64(dirstream.id))
+	vfsMutex.Unlock()
+
+	C.free(unsafe.Pointer(d.c.name))
+	C.free(unsafe.Pointer(d.c))
+	return 0
+}
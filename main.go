@@ -9,14 +9,20 @@ import (
 	"time"
 
 	"github.com/go-gl/glfw/v3.3/glfw"
+	"github.com/libretro/ludo/achievements"
 	"github.com/libretro/ludo/audio"
 	"github.com/libretro/ludo/core"
+	"github.com/libretro/ludo/coreinfo"
 	"github.com/libretro/ludo/favorites"
+	"github.com/libretro/ludo/firmware"
 	"github.com/libretro/ludo/history"
 	"github.com/libretro/ludo/input"
 	"github.com/libretro/ludo/menu"
+	"github.com/libretro/ludo/movie"
+	"github.com/libretro/ludo/netplay"
 	ntf "github.com/libretro/ludo/notifications"
 	"github.com/libretro/ludo/playlists"
+	"github.com/libretro/ludo/rewind"
 	"github.com/libretro/ludo/savefiles"
 	"github.com/libretro/ludo/scanner"
 	"github.com/libretro/ludo/settings"
@@ -47,15 +53,25 @@ func runLoop(vid *video.Video, m *menu.Menu) {
 		input.Poll()
 		if !state.MenuActive {
 			if state.CoreRunning {
-				state.Core.Run()
-				if state.Core.FrameTimeCallback != nil {
-					state.Core.FrameTimeCallback.Callback(state.Core.FrameTimeCallback.Reference)
-				}
-				if state.Core.AudioCallback != nil {
-					state.Core.AudioCallback.Callback()
+				state.PlayTime += currTime.Sub(prevTime)
+			}
+			if state.CoreRunning && netplay.Active() {
+				netplay.Frame()
+			} else if state.CoreRunning && movie.Active() {
+				movie.Frame()
+			} else if state.CoreRunning {
+				state.Rewinding = input.NewState[0][input.ActionRewind] == 1 && rewind.Pop()
+				core.RunFrame()
+				if !state.Rewinding {
+					rewind.Push()
 				}
 			}
+			if state.CoreRunning && !state.Rewinding {
+				achievements.Frame()
+			}
 			vid.Render()
+			m.RenderCrosshair()
+			m.RenderWatches()
 			frame++
 			if frame%600 == 0 { // save sram about every 10 sec
 				savefiles.SaveSRAM()
@@ -76,6 +92,78 @@ func runLoop(vid *video.Video, m *menu.Menu) {
 	}
 }
 
+// runHeadless loads the core and the game without creating a window or
+// opening the audio device, and runs the game for the requested number of frames.
+// A movie can be played back or recorded while running.
+func runHeadless(gamePath string, frames int, play, record string) {
+	l10n.Init(settings.Current.Language, settings.Current.LanguagesDirectory)
+
+	vid := &video.Video{}
+	core.Init(vid)
+
+	if len(state.CorePath) == 0 {
+		log.Fatalln("[Headless]: A core is required, use -L")
+	}
+	if err := core.Load(state.CorePath); err != nil {
+		log.Fatalln("[Headless]:", err)
+	}
+	if len(gamePath) > 0 {
+		if err := core.LoadGame(gamePath); err != nil {
+			log.Fatalln("[Headless]:", err)
+		}
+	}
+
+	var stop func() bool
+	if len(play) > 0 {
+		if err := movie.Play(play); err != nil {
+			log.Fatalln("[Headless]:", err)
+		}
+		// Stop when the end of the movie is reached
+		stop = func() bool { return !movie.Active() }
+	} else if len(record) > 0 {
+		if err := movie.Record(record, false); err != nil {
+			log.Fatalln("[Headless]:", err)
+		}
+	}
+
+	step := core.RunFrame
+	if movie.Active() {
+		step = movie.Frame
+	}
+
+	n := core.RunHeadless(frames, step, stop)
+	log.Printf("[Headless]: Ran %d frames, last frame CRC %08x\n", n, vid.FrameCRC())
+
+	movie.Stop()
+	core.Unload()
+}
+
+// startNetplay hosts or joins a netplay session if requested on the command line
+func startNetplay(host bool, join string) {
+	var err error
+	if host {
+		err = netplay.Host()
+	} else if len(join) > 0 {
+		err = netplay.Join(join)
+	}
+	if err != nil {
+		ntf.DisplayAndLog(ntf.Error, "Netplay", err.Error())
+	}
+}
+
+// startMovie plays back or records a movie if requested on the command line
+func startMovie(play, record string) {
+	var err error
+	if len(play) > 0 {
+		err = movie.Play(play)
+	} else if len(record) > 0 {
+		err = movie.Record(record, false)
+	}
+	if err != nil {
+		ntf.DisplayAndLog(ntf.Error, "Movie", err.Error())
+	}
+}
+
 func main() {
 	err := settings.Load()
 	if err != nil {
@@ -98,6 +186,12 @@ func main() {
 	flag.StringVar(&state.CorePath, "L", "", "Path to the libretro core")
 	flag.BoolVar(&state.Verbose, "v", false, "Verbose logs")
 	flag.BoolVar(&state.LudOS, "ludos", false, "Expose the features related to LudOS")
+	flag.BoolVar(&state.Headless, "headless", false, "Run the core without video, audio or input")
+	frames := flag.Int("frames", 0, "Number of frames to run in headless mode, 0 runs until the core shuts down")
+	netplayHost := flag.Bool("netplay-host", false, "Host a netplay session once the game is loaded")
+	netplayJoin := flag.String("netplay-join", "", "Join the netplay session hosted at this address once the game is loaded")
+	moviePlay := flag.String("movie", "", "Play back this input movie once the game is loaded")
+	movieRecord := flag.String("record", "", "Record an input movie to this path once the game is loaded")
 	flag.Parse()
 	args := flag.Args()
 
@@ -106,6 +200,11 @@ func main() {
 		gamePath = args[0]
 	}
 
+	if state.Headless {
+		runHeadless(gamePath, *frames, *moviePlay, *movieRecord)
+		return
+	}
+
 	if err := glfw.Init(); err != nil {
 		log.Fatalln("Failed to initialize glfw", err)
 	}
@@ -122,6 +221,12 @@ func main() {
 
 	favorites.Load()
 
+	coreinfo.Load()
+
+	// Only a few small files are hashed, and the results must be there before
+	// the first game or the firmware scene
+	firmware.Scan()
+
 	vid := video.Init(settings.Current.VideoFullscreen)
 
 	audio.Init()
@@ -143,6 +248,8 @@ func main() {
 					ntf.DisplayAndLog(ntf.Error, "Menu", err.Error())
 				} else {
 					m.WarpToQuickMenu()
+					startNetplay(*netplayHost, *netplayJoin)
+					startMovie(*moviePlay, *movieRecord)
 				}
 			}
 		} else {
@@ -155,6 +262,9 @@ func main() {
 
 	runLoop(vid, m)
 
+	netplay.Stop()
+	movie.Stop()
+
 	// Unload and deinit in the core.
 	core.Unload()
 }
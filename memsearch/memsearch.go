@@ -0,0 +1,208 @@
+// Package memsearch finds the addresses of values in the memory of the
+// emulated system, by comparing successive snapshots of the memory, and keeps
+// a list of addresses to watch while playing.
+package memsearch
+
+import (
+	"encoding/binary"
+	"unsafe"
+
+	"github.com/libretro/ludo/libretro"
+	"github.com/libretro/ludo/state"
+)
+
+// Region is a contiguous block of emulated memory
+type Region struct {
+	Start uintptr // address of the first byte in the emulated system
+	Data  []byte  // live view of the memory
+}
+
+// View describes how values are read from memory
+type View struct {
+	Size      int // in bytes, 1, 2 or 4
+	BigEndian bool
+}
+
+// Read reads a value at the start of b
+func (v View) Read(b []byte) uint32 {
+	switch v.Size {
+	case 2:
+		if v.BigEndian {
+			return uint32(binary.BigEndian.Uint16(b))
+		}
+		return uint32(binary.LittleEndian.Uint16(b))
+	case 4:
+		if v.BigEndian {
+			return binary.BigEndian.Uint32(b)
+		}
+		return binary.LittleEndian.Uint32(b)
+	}
+	return uint32(b[0])
+}
+
+// Op is a search filter
+type Op int
+
+const (
+	// OpEqual keeps the values equal to a given value
+	OpEqual Op = iota
+	// OpUnchanged keeps the values that didn't change since the last snapshot
+	OpUnchanged
+	// OpChanged keeps the values that changed since the last snapshot
+	OpChanged
+	// OpGreater keeps the values that increased since the last snapshot
+	OpGreater
+	// OpLess keeps the values that decreased since the last snapshot
+	OpLess
+)
+
+func (op Op) match(cur, prev, value uint32) bool {
+	switch op {
+	case OpEqual:
+		return cur == value
+	case OpUnchanged:
+		return cur == prev
+	case OpChanged:
+		return cur != prev
+	case OpGreater:
+		return cur > prev
+	case OpLess:
+		return cur < prev
+	}
+	return false
+}
+
+// Regions returns the memory that can be searched. It uses the writable
+// regions of the memory map of the core, or the system RAM if the core
+// doesn't provide a memory map.
+func Regions() []Region {
+	if state.Core == nil {
+		return nil
+	}
+
+	var regions []Region
+	seen := map[unsafe.Pointer]bool{}
+	for _, d := range state.Core.MemoryMap {
+		// Regions with a select mask or disconnected bits are not linear
+		if d.Ptr == nil || d.Len == 0 || d.Select != 0 || d.Disconnect != 0 || d.Flags&libretro.MemDescConst != 0 {
+			continue
+		}
+		ptr := unsafe.Add(d.Ptr, d.Offset)
+		// Skip mirrors
+		if seen[ptr] {
+			continue
+		}
+		seen[ptr] = true
+		regions = append(regions, Region{
+			Start: d.Start,
+			Data:  unsafe.Slice((*byte)(ptr), d.Len),
+		})
+	}
+	if len(regions) > 0 {
+		return regions
+	}
+
+	size := state.Core.GetMemorySize(libretro.MemorySystemRAM)
+	ptr := state.Core.GetMemoryData(libretro.MemorySystemRAM)
+	if ptr == nil || size == 0 {
+		return nil
+	}
+	return []Region{{Data: unsafe.Slice((*byte)(ptr), size)}}
+}
+
+// candidate is a matching value, identified by its region and its offset in
+// the region
+type candidate struct {
+	region int
+	offset int
+}
+
+// Search narrows down a set of addresses with successive filters
+type Search struct {
+	View View
+
+	regions    []Region
+	snapshots  [][]byte
+	candidates []candidate
+	all        bool // every address is still a candidate
+}
+
+// NewSearch starts a search over regions, every address being a candidate
+func NewSearch(regions []Region, view View) *Search {
+	s := &Search{View: view, regions: regions, all: true}
+	s.snapshot()
+	return s
+}
+
+func (s *Search) snapshot() {
+	s.snapshots = make([][]byte, len(s.regions))
+	for i, r := range s.regions {
+		s.snapshots[i] = append([]byte{}, r.Data...)
+	}
+}
+
+// Filter keeps the candidates matching op, and takes a new snapshot. value
+// is only used by OpEqual.
+func (s *Search) Filter(op Op, value uint32) {
+	var kept []candidate
+	check := func(c candidate) {
+		cur := s.View.Read(s.regions[c.region].Data[c.offset:])
+		prev := s.View.Read(s.snapshots[c.region][c.offset:])
+		if op.match(cur, prev, value) {
+			kept = append(kept, c)
+		}
+	}
+
+	if s.all {
+		for i, r := range s.regions {
+			for off := 0; off+s.View.Size <= len(r.Data); off++ {
+				check(candidate{i, off})
+			}
+		}
+		s.all = false
+	} else {
+		for _, c := range s.candidates {
+			check(c)
+		}
+	}
+
+	s.candidates = kept
+	s.snapshot()
+}
+
+// Count returns the number of candidates left
+func (s *Search) Count() int {
+	if !s.all {
+		return len(s.candidates)
+	}
+	n := 0
+	for _, r := range s.regions {
+		if len(r.Data) >= s.View.Size {
+			n += len(r.Data) - s.View.Size + 1
+		}
+	}
+	return n
+}
+
+// Result is a candidate address and its current value
+type Result struct {
+	Address uintptr
+	Value   uint32
+}
+
+// Results returns at most max candidates, or nil while every address is still
+// a candidate
+func (s *Search) Results(max int) []Result {
+	var results []Result
+	for _, c := range s.candidates {
+		if len(results) == max {
+			break
+		}
+		r := s.regions[c.region]
+		results = append(results, Result{
+			Address: r.Start + uintptr(c.offset),
+			Value:   s.View.Read(r.Data[c.offset:]),
+		})
+	}
+	return results
+}
@@ -0,0 +1,110 @@
+package memsearch
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/libretro/ludo/libretro"
+	"github.com/libretro/ludo/state"
+)
+
+func TestView_Read(t *testing.T) {
+	b := []byte{0x01, 0x02, 0x03, 0x04}
+	tests := []struct {
+		view View
+		want uint32
+	}{
+		{View{Size: 1}, 0x01},
+		{View{Size: 2}, 0x0201},
+		{View{Size: 2, BigEndian: true}, 0x0102},
+		{View{Size: 4}, 0x04030201},
+		{View{Size: 4, BigEndian: true}, 0x01020304},
+	}
+	for _, tt := range tests {
+		if got := tt.view.Read(b); got != tt.want {
+			t.Errorf("%+v got = %x, want %x", tt.view, got, tt.want)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	ram := make([]byte, 8)
+	s := NewSearch([]Region{{Start: 0x100, Data: ram}}, View{Size: 1})
+
+	if got := s.Count(); got != 8 {
+		t.Errorf("got = %v, want %v", got, 8)
+	}
+	if got := s.Results(10); got != nil {
+		t.Errorf("got = %v, want %v", got, nil)
+	}
+
+	ram[2] = 5
+	ram[6] = 3
+	s.Filter(OpChanged, 0)
+	if got := s.Count(); got != 2 {
+		t.Errorf("got = %v, want %v", got, 2)
+	}
+
+	ram[2] = 4
+	ram[6] = 4
+	s.Filter(OpLess, 0)
+	want := []Result{{Address: 0x102, Value: 4}}
+	if got := s.Results(10); !reflect.DeepEqual(got, want) {
+		t.Errorf("got = %v, want %v", got, want)
+	}
+
+	s.Filter(OpEqual, 3)
+	if got := s.Count(); got != 0 {
+		t.Errorf("got = %v, want %v", got, 0)
+	}
+}
+
+func TestSearch_wideValues(t *testing.T) {
+	ram := []byte{0, 0, 0x12, 0x34, 0}
+	s := NewSearch([]Region{{Data: ram}}, View{Size: 2, BigEndian: true})
+	if got := s.Count(); got != 4 {
+		t.Errorf("got = %v, want %v", got, 4)
+	}
+	s.Filter(OpEqual, 0x1234)
+	want := []Result{{Address: 2, Value: 0x1234}}
+	if got := s.Results(10); !reflect.DeepEqual(got, want) {
+		t.Errorf("got = %v, want %v", got, want)
+	}
+}
+
+func TestRegions(t *testing.T) {
+	wram := make([]byte, 32)
+	rom := make([]byte, 32)
+	state.Core = &libretro.Core{MemoryMap: []libretro.MemoryDescriptor{
+		{Ptr: unsafe.Pointer(&wram[0]), Start: 0x7e0000, Len: 32},
+		{Ptr: unsafe.Pointer(&wram[0]), Start: 0x000000, Len: 32}, // mirror
+		{Ptr: unsafe.Pointer(&rom[0]), Start: 0x800000, Len: 32, Flags: libretro.MemDescConst},
+	}}
+	defer func() { state.Core = nil }()
+
+	regions := Regions()
+	if len(regions) != 1 || regions[0].Start != 0x7e0000 || len(regions[0].Data) != 32 {
+		t.Errorf("got = %+v, want the work RAM only", regions)
+	}
+
+	wram[4] = 0x2a
+	w := Watch{Address: 0x7e0004, View: View{Size: 1}}
+	if v, ok := w.Value(); !ok || v != 0x2a {
+		t.Errorf("got = %v %v, want %v %v", v, ok, 0x2a, true)
+	}
+	w = Watch{Address: 0x900000, View: View{Size: 1}}
+	if _, ok := w.Value(); ok {
+		t.Errorf("got = %v, want %v", ok, false)
+	}
+
+	AddWatch(w)
+	AddWatch(w)
+	if len(Watches()) != 1 {
+		t.Errorf("got = %v, want %v", len(Watches()), 1)
+	}
+	RemoveWatch(0)
+	if len(Watches()) != 0 {
+		t.Errorf("got = %v, want %v", len(Watches()), 0)
+	}
+}
@@ -0,0 +1,70 @@
+package memsearch
+
+import (
+	"github.com/libretro/ludo/state"
+)
+
+// Watch is an address displayed over the game while playing
+type Watch struct {
+	Address uintptr
+	View    View
+}
+
+var (
+	current *Search
+	watches []Watch
+)
+
+// Current returns the search in progress, or nil
+func Current() *Search {
+	return current
+}
+
+// Start starts a new search over the memory of the running game
+func Start(view View) *Search {
+	current = NewSearch(Regions(), view)
+	return current
+}
+
+// Reset forgets the search and the watch list, when the game is unloaded
+func Reset() {
+	current = nil
+	watches = nil
+}
+
+// Watches returns the watch list
+func Watches() []Watch {
+	return watches
+}
+
+// AddWatch pins an address to the watch list
+func AddWatch(w Watch) {
+	for _, o := range watches {
+		if o == w {
+			return
+		}
+	}
+	watches = append(watches, w)
+}
+
+// RemoveWatch removes an address from the watch list
+func RemoveWatch(i int) {
+	watches = append(watches[:i], watches[i+1:]...)
+}
+
+// Value reads the current value of a watched address. It returns false if the
+// address isn't mapped.
+func (w Watch) Value() (uint32, bool) {
+	if state.Core == nil {
+		return 0, false
+	}
+	b := make([]byte, w.View.Size)
+	for i := range b {
+		ptr := state.Core.MemoryPointer(w.Address + uintptr(i))
+		if ptr == nil {
+			return 0, false
+		}
+		b[i] = *(*byte)(ptr)
+	}
+	return w.View.Read(b), true
+}
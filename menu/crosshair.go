@@ -0,0 +1,42 @@
+package menu
+
+import (
+	"github.com/libretro/ludo/input"
+	lr "github.com/libretro/ludo/libretro"
+	"github.com/libretro/ludo/state"
+)
+
+// aimingDevice tells if a lightgun or a pointer is plugged in one of the ports
+func aimingDevice() bool {
+	for port := uint(0); port < input.MaxPlayers; port++ {
+		switch input.Device(port) & lr.DeviceMask {
+		case lr.DeviceLightgun, lr.DevicePointer:
+			return true
+		}
+	}
+	return false
+}
+
+// RenderCrosshair draws a crosshair where the mouse aims on the game screen,
+// when a lightgun or a pointer is plugged
+func (m *Menu) RenderCrosshair() {
+	if !state.CoreRunning || state.MenuActive || !aimingDevice() {
+		return
+	}
+	x, y, offscreen := input.Cursor()
+	if offscreen {
+		return
+	}
+
+	fbw, fbh := m.GetFramebufferSize()
+	vx, vy, vw, vh := m.Viewport(fbw, fbh)
+	cx := vx + (float32(x)/0x7fff+1)/2*vw
+	cy := vy + (float32(y)/0x7fff+1)/2*vh
+
+	size := 24 * m.ratio
+	thickness := 2 * m.ratio
+	m.DrawRect(cx-size/2-thickness, cy-thickness*2, size+thickness*2, thickness*4, 0, black.Alpha(0.5))
+	m.DrawRect(cx-thickness*2, cy-size/2-thickness, thickness*4, size+thickness*2, 0, black.Alpha(0.5))
+	m.DrawRect(cx-size/2, cy-thickness/2, size, thickness, 0, white)
+	m.DrawRect(cx-thickness/2, cy-size/2, thickness, size, 0, white)
+}
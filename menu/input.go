@@ -163,6 +163,18 @@ func indexed(list *entry, offset int) int {
 
 var combo1, combo2 int
 
+// toggleGameFocus gives the keyboard to the core or takes it back
+func toggleGameFocus() {
+	state.GameFocus = !state.GameFocus
+	if state.GameFocus {
+		txtI18n := l10n.T9(&i18n.Message{ID: "GameFocusON", Other: "Game focus ON, press Scroll Lock to release the keyboard"})
+		ntf.DisplayAndLog(ntf.Info, "Menu", txtI18n)
+	} else {
+		txtI18n := l10n.T9(&i18n.Message{ID: "GameFocusOFF", Other: "Game focus OFF"})
+		ntf.DisplayAndLog(ntf.Info, "Menu", txtI18n)
+	}
+}
+
 // ProcessHotkeys checks if certain keys are pressed and perform corresponding actions
 func (m *Menu) ProcessHotkeys() {
 	tConfirmDialog := l10n.T9(&i18n.Message{ID: "ConfirmDialog", Other: "Confirm Dialog"})
@@ -227,6 +239,10 @@ func (m *Menu) ProcessHotkeys() {
 		}
 	}
 
+	if input.Pressed[0][input.ActionGameFocusToggle] == 1 && !state.MenuActive {
+		toggleGameFocus()
+	}
+
 	// Close if ActionShouldClose is pressed, but display a confirmation dialog
 	// in case a game is running
 	if input.Pressed[0][input.ActionShouldClose] == 1 {
@@ -2,6 +2,7 @@ package menu
 
 import (
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -233,3 +234,34 @@ func TestExtractTags(t *testing.T) {
 		})
 	}
 }
+
+func Test_matchesExtensions(t *testing.T) {
+	dir := t.TempDir()
+	exts := []string{".sfc", ".cue", ".tar.gz"}
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"Game.sfc", true},
+		{"GAME.SFC", true},
+		{"Track.CUE", true},
+		{"Games.tar.gz", true},
+		{"Game.gz", false},
+		{"Game.smc", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			if f, err := os.Create(path); err == nil {
+				f.Close()
+			}
+			fi, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := matchesExtensions(fi, exts); got != tt.want {
+				t.Errorf("got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
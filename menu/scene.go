@@ -218,6 +218,27 @@ func genericRender(list *entry) {
 	menu.ScissorEnd()
 }
 
+// genericDrawSubLabel renders the sub label of the selected entry, under its
+// label
+func genericDrawSubLabel(list *entry) {
+	if len(list.children) == 0 {
+		return
+	}
+	e := list.children[list.ptr]
+	if e.subLabel == "" {
+		return
+	}
+	_, h := menu.GetFramebufferSize()
+	fontOffset := 64 * 0.7 * menu.ratio * 0.3
+	menu.ScissorStart(int32(530*menu.ratio), 0, int32(1310*menu.ratio), int32(h))
+	menu.Font.SetColor(mediumGrey.Alpha(e.subLabelAlpha))
+	menu.Font.Printf(
+		670*menu.ratio,
+		float32(h)*e.yp+fontOffset+45*menu.ratio,
+		0.4*menu.ratio, e.subLabel)
+	menu.ScissorEnd()
+}
+
 // Displays a confirmation dialog before quitting
 func askQuitConfirmation(cb func()) {
 	if state.CoreRunning {
@@ -265,6 +286,25 @@ func askDeleteSavestateConfirmation(cb func()) {
 		}))
 }
 
+// Displays a confirmation dialog before restoring a save RAM backup
+func askRestoreSaveRAMConfirmation(cb func()) {
+	menu.Push(buildYesNoDialog(
+		"Confirm before restoring",
+		"The game will be reset to load the restored save.",
+		"The current save will be backed up first.", func() {
+			cb()
+		}))
+}
+
+// Displays a confirmation dialog before resuming a game from its auto
+// savestate, cbNo is called if the game is started from the beginning instead
+func askResumeConfirmation(cbYes, cbNo func()) {
+	menu.Push(buildYesNoCancelDialog(
+		"Resume the game",
+		"This game was saved automatically when you left it.",
+		"Do you want to resume from there?", cbYes, cbNo))
+}
+
 func genericDrawHintBar() {
 	w, h := menu.GetFramebufferSize()
 	menu.DrawRect(0, float32(h)-70*menu.ratio, float32(w), 70*menu.ratio, 0, lightGrey)
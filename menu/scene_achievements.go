@@ -0,0 +1,98 @@
+package menu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/libretro/ludo/achievements"
+
+	"github.com/libretro/ludo/l10n"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type sceneAchievements struct {
+	entry
+}
+
+func buildAchievements() Scene {
+	var list sceneAchievements
+	list.label = l10n.T9(&i18n.Message{ID: "Achievements", Other: "Achievements"})
+
+	all := achievements.List()
+	if len(all) == 0 {
+		list.children = append(list.children, entry{
+			label: l10n.T9(&i18n.Message{ID: "NoAchievements", Other: "No achievements for this game"}),
+			icon:  "subsetting",
+		})
+		list.segueMount()
+		return &list
+	}
+
+	unlocked, points, total := 0, 0, 0
+	for _, a := range all {
+		total += a.Points
+		if !a.Unlocked.IsZero() {
+			unlocked++
+			points += a.Points
+		}
+	}
+
+	tProgress := l10n.T9(&i18n.Message{ID: "AchievementsProgress", Other: "Progress"})
+	tPoints := l10n.T9(&i18n.Message{ID: "AchievementsPoints", Other: "%d/%d points"})
+	list.children = append(list.children, entry{
+		label: tProgress,
+		icon:  "subsetting",
+		stringValue: func() string {
+			return fmt.Sprintf("%d/%d, "+tPoints, unlocked, len(all), points, total)
+		},
+	})
+
+	tLocked := l10n.T9(&i18n.Message{ID: "AchievementLocked", Other: "Locked"})
+	for _, a := range all {
+		a := a
+		list.children = append(list.children, entry{
+			label: strings.Replace(a.Title, "%", "%%", -1),
+			icon:  "subsetting",
+			stringValue: func() string {
+				if a.Unlocked.IsZero() {
+					return tLocked
+				}
+				return a.Unlocked.Format("2006-01-02")
+			},
+		})
+	}
+
+	list.segueMount()
+
+	return &list
+}
+
+// Generic stuff
+
+func (s *sceneAchievements) Entry() *entry {
+	return &s.entry
+}
+
+func (s *sceneAchievements) segueMount() {
+	genericSegueMount(&s.entry)
+}
+
+func (s *sceneAchievements) segueNext() {
+	genericSegueNext(&s.entry)
+}
+
+func (s *sceneAchievements) segueBack() {
+	genericAnimate(&s.entry)
+}
+
+func (s *sceneAchievements) update(dt float32) {
+	genericInput(&s.entry, dt)
+}
+
+func (s *sceneAchievements) render() {
+	genericRender(&s.entry)
+}
+
+func (s *sceneAchievements) drawHintBar() {
+	genericDrawHintBar()
+}
@@ -0,0 +1,161 @@
+package menu
+
+import (
+	"github.com/libretro/ludo/cheats"
+	ntf "github.com/libretro/ludo/notifications"
+	"github.com/libretro/ludo/settings"
+	"github.com/libretro/ludo/state"
+
+	"github.com/libretro/ludo/l10n"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type sceneCheats struct {
+	entry
+}
+
+func buildCheats() Scene {
+	var list sceneCheats
+	list.label = l10n.T9(&i18n.Message{ID: "Cheats", Other: "Cheats"})
+
+	list.children = append(list.children, entry{
+		label: l10n.T9(&i18n.Message{ID: "CheatAddCode", Other: "Add Code"}),
+		icon:  "add",
+		callbackOK: func() {
+			list.segueNext()
+			menu.Push(buildKeyboard(
+				l10n.T9(&i18n.Message{ID: "CheatCode", Other: "Cheat code"}),
+				func(code string) {
+					if err := cheats.Add(code); err != nil {
+						ntf.DisplayAndLog(ntf.Error, "Menu", err.Error())
+						return
+					}
+					menu.stack[len(menu.stack)-2] = buildCheats()
+				},
+			))
+		},
+	})
+
+	list.children = append(list.children, entry{
+		label: l10n.T9(&i18n.Message{ID: "CheatLoadFile", Other: "Load Cheat File"}),
+		icon:  "folder",
+		callbackOK: func() {
+			depth := len(menu.stack)
+			list.segueNext()
+			menu.Push(buildExplorer(
+				settings.Current.CheatsDirectory,
+				[]string{".cht"},
+				func(path string) {
+					if err := cheats.LoadFile(path); err != nil {
+						ntf.DisplayAndLog(ntf.Error, "Menu", err.Error())
+						return
+					}
+					if err := cheats.Save(); err != nil {
+						ntf.DisplayAndLog(ntf.Error, "Menu", err.Error())
+					}
+					menu.stack = menu.stack[:depth]
+					menu.stack[depth-1] = buildCheats()
+					menu.tweens.FastForward()
+				},
+				nil,
+				nil,
+			))
+		},
+	})
+
+	tMemory := l10n.T9(&i18n.Message{ID: "CheatMemory", Other: "(memory)"})
+
+	for i, c := range cheats.List() {
+		i := i
+		label := c.Desc
+		if c.ActiveHandler() == cheats.HandlerMemory {
+			label += " " + tMemory
+		}
+		list.children = append(list.children, entry{
+			label: label,
+			icon:  "subsetting",
+			value: func() interface{} {
+				return cheats.List()[i].Enabled
+			},
+			widget: widgets["switch"],
+			callbackOK: func() {
+				if err := cheats.Toggle(i); err != nil {
+					ntf.DisplayAndLog(ntf.Error, "Menu", err.Error())
+				}
+			},
+			incr: func(int) {
+				if err := cheats.Toggle(i); err != nil {
+					ntf.DisplayAndLog(ntf.Error, "Menu", err.Error())
+				}
+			},
+			// Overrides the handler picked for the core
+			callbackX: func() {
+				if err := cheats.ToggleHandler(i); err != nil {
+					ntf.DisplayAndLog(ntf.Error, "Menu", err.Error())
+					return
+				}
+				scene := buildCheats()
+				scene.Entry().ptr = list.ptr
+				scene.segueMount()
+				menu.stack[len(menu.stack)-1] = scene
+				menu.tweens.FastForward()
+			},
+		})
+	}
+
+	list.segueMount()
+
+	return &list
+}
+
+// Generic stuff
+
+func (s *sceneCheats) Entry() *entry {
+	return &s.entry
+}
+
+func (s *sceneCheats) segueMount() {
+	genericSegueMount(&s.entry)
+}
+
+func (s *sceneCheats) segueNext() {
+	genericSegueNext(&s.entry)
+}
+
+func (s *sceneCheats) segueBack() {
+	genericAnimate(&s.entry)
+}
+
+func (s *sceneCheats) update(dt float32) {
+	genericInput(&s.entry, dt)
+}
+
+func (s *sceneCheats) render() {
+	genericRender(&s.entry)
+}
+
+func (s *sceneCheats) drawHintBar() {
+	w, h := menu.GetFramebufferSize()
+	menu.DrawRect(0, float32(h)-70*menu.ratio, float32(w), 70*menu.ratio, 0, lightGrey)
+
+	_, upDown, _, a, b, x, _, _, _, guide := hintIcons()
+
+	tHBarResume := l10n.T9(&i18n.Message{ID: "HBarResume", Other: "RESUME"})
+	tHBarNavigate := l10n.T9(&i18n.Message{ID: "HBarNavigate", Other: "NAVIGATE"})
+	tHBarBack := l10n.T9(&i18n.Message{ID: "HBarBack", Other: "BACK"})
+	tHBarOk := l10n.T9(&i18n.Message{ID: "HBarOk", Other: "OK"})
+	tHBarMemory := l10n.T9(&i18n.Message{ID: "HBarMemory", Other: "MEMORY"})
+
+	var stack float32
+	if state.CoreRunning {
+		stackHint(&stack, guide, tHBarResume, h)
+	}
+	stackHint(&stack, upDown, tHBarNavigate, h)
+	stackHint(&stack, b, tHBarBack, h)
+	stackHint(&stack, a, tHBarOk, h)
+
+	list := menu.stack[len(menu.stack)-1].Entry()
+	if list.children[list.ptr].callbackX != nil {
+		stackHint(&stack, x, tHBarMemory, h)
+	}
+}
@@ -0,0 +1,268 @@
+package menu
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/libretro/ludo/core"
+	"github.com/libretro/ludo/input"
+	lr "github.com/libretro/ludo/libretro"
+	ntf "github.com/libretro/ludo/notifications"
+	"github.com/libretro/ludo/rumble"
+	"github.com/libretro/ludo/settings"
+	"github.com/libretro/ludo/state"
+
+	"github.com/libretro/ludo/l10n"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type sceneControls struct {
+	entry
+}
+
+func buildControls() Scene {
+	var list sceneControls
+	list.label = l10n.T9(&i18n.Message{ID: "Controls", Other: "Controls"})
+
+	// Cores declaring their controllers tell how many ports they have
+	ports := uint(input.MaxPlayers)
+	if n := uint(len(state.Core.Controllers)); n > 0 && n < ports {
+		ports = n
+	}
+
+	for port := uint(0); port < ports; port++ {
+		port := port
+		txtI18n := l10n.T9(&i18n.Message{ID: "ControlsPort", Other: "Port %d Device"})
+		list.children = append(list.children, entry{
+			label: fmt.Sprintf(txtI18n, port+1),
+			icon:  "subsetting",
+			stringValue: func() string {
+				for _, d := range core.PortDevices(port) {
+					if d.ID == input.Device(port) {
+						return d.Desc
+					}
+				}
+				return fmt.Sprintf("%d", input.Device(port))
+			},
+			incr: func(direction int) {
+				devices := core.PortDevices(port)
+				i := 0
+				for j, d := range devices {
+					if d.ID == input.Device(port) {
+						i = j
+					}
+				}
+				i = (i + direction + len(devices)) % len(devices)
+				core.SetPortDevice(port, devices[i].ID)
+				if err := core.SavePorts(); err != nil {
+					ntf.DisplayAndLog(ntf.Error, "Menu", err.Error())
+				}
+			},
+		})
+	}
+
+	for port := uint(0); port < ports; port++ {
+		port := port
+		txtI18n := l10n.T9(&i18n.Message{ID: "ControlsPortButtons", Other: "Port %d Buttons"})
+		list.children = append(list.children, entry{
+			label: fmt.Sprintf(txtI18n, port+1),
+			icon:  "subsetting",
+			callbackOK: func() {
+				list.segueNext()
+				menu.Push(buildRemap(port))
+			},
+		})
+	}
+
+	for port := uint(0); port < ports; port++ {
+		port := port
+		txtI18n := l10n.T9(&i18n.Message{ID: "ControlsPortRumble", Other: "Port %d Rumble Strength"})
+		list.children = append(list.children, entry{
+			label: fmt.Sprintf(txtI18n, port+1),
+			icon:  "subsetting",
+			value: func() interface{} {
+				if int(port) >= len(settings.Current.RumbleStrength) {
+					return float32(1)
+				}
+				return settings.Current.RumbleStrength[port]
+			},
+			widget: widgets["range"],
+			incr: func(direction int) {
+				for len(settings.Current.RumbleStrength) <= int(port) {
+					settings.Current.RumbleStrength = append(settings.Current.RumbleStrength, 1)
+				}
+				v := settings.Current.RumbleStrength[port] + 0.1*float32(direction)
+				if v < 0 {
+					v = 0
+				}
+				if v > 1 {
+					v = 1
+				}
+				settings.Current.RumbleStrength[port] = v
+				rumble.Refresh()
+				settings.Save()
+			},
+		})
+	}
+
+	list.children = append(list.children, entry{
+		label: l10n.T9(&i18n.Message{ID: "RemapSaveCore", Other: "Save Remap for Core"}),
+		icon:  "menu_saving",
+		callbackOK: func() {
+			saveRemaps(false)
+		},
+	})
+
+	list.children = append(list.children, entry{
+		label: l10n.T9(&i18n.Message{ID: "RemapSaveGame", Other: "Save Remap for Game"}),
+		icon:  "menu_saving",
+		callbackOK: func() {
+			saveRemaps(true)
+		},
+	})
+
+	if core.GameRemapsExist() {
+		list.children = append(list.children, entry{
+			label: l10n.T9(&i18n.Message{ID: "RemapRemoveGame", Other: "Remove Game Remap"}),
+			icon:  "close",
+			callbackOK: func() {
+				if err := core.RemoveGameRemaps(); err != nil {
+					ntf.DisplayAndLog(ntf.Error, "Menu", err.Error())
+					return
+				}
+				txtI18n := l10n.T9(&i18n.Message{ID: "RemapRemoved", Other: "Game remap removed."})
+				ntf.DisplayAndLog(ntf.Success, "Menu", txtI18n)
+				refreshControls()
+			},
+		})
+	}
+
+	list.segueMount()
+
+	return &list
+}
+
+// saveRemaps persists the button remapping for the core or for the game
+func saveRemaps(forGame bool) {
+	if err := core.SaveRemaps(forGame); err != nil {
+		ntf.DisplayAndLog(ntf.Error, "Menu", err.Error())
+		return
+	}
+	txtI18n := l10n.T9(&i18n.Message{ID: "RemapSaved", Other: "Remap saved."})
+	ntf.DisplayAndLog(ntf.Success, "Menu", txtI18n)
+	refreshControls()
+}
+
+// refreshControls rebuilds the controls scene in place, keeping the cursor
+func refreshControls() {
+	list := menu.stack[len(menu.stack)-1]
+	scene := buildControls()
+	scene.Entry().ptr = list.Entry().ptr
+	scene.segueMount()
+	menu.stack[len(menu.stack)-1] = scene
+	menu.tweens.FastForward()
+}
+
+type sceneRemap struct {
+	entry
+}
+
+// buildRemap lists the buttons the core polls on a port, with the names the
+// core gave them, and lets the user choose which RetroPad button feeds them
+func buildRemap(port uint) Scene {
+	var list sceneRemap
+	txtI18n := l10n.T9(&i18n.Message{ID: "ControlsPortButtons", Other: "Port %d Buttons"})
+	list.label = fmt.Sprintf(txtI18n, port+1)
+
+	descs := map[uint32]string{}
+	for _, d := range state.Core.InputDescriptors {
+		if d.Port == port && d.Device&lr.DeviceMask == lr.DeviceJoypad && d.Index == 0 && d.ID < uint(input.ButtonsCount) {
+			descs[uint32(d.ID)] = d.Description
+		}
+	}
+
+	for id := uint32(0); id < input.ButtonsCount; id++ {
+		id := id
+		label := input.ButtonNames[id]
+		if desc, ok := descs[id]; ok {
+			label += ": " + desc
+		} else if len(descs) > 0 {
+			// The core told which buttons it uses, hide the others
+			continue
+		}
+
+		list.children = append(list.children, entry{
+			label: strings.Replace(label, "%", "%%", -1),
+			icon:  "subsetting",
+			stringValue: func() string {
+				return input.ButtonNames[input.Remapped(port, id)]
+			},
+			incr: func(direction int) {
+				button := (int(input.Remapped(port, id)) + direction + int(input.ButtonsCount)) % int(input.ButtonsCount)
+				input.SetRemap(port, id, uint32(button))
+			},
+		})
+	}
+
+	list.segueMount()
+
+	return &list
+}
+
+func (s *sceneRemap) Entry() *entry {
+	return &s.entry
+}
+
+func (s *sceneRemap) segueMount() {
+	genericSegueMount(&s.entry)
+}
+
+func (s *sceneRemap) segueNext() {
+	genericSegueNext(&s.entry)
+}
+
+func (s *sceneRemap) segueBack() {
+	genericAnimate(&s.entry)
+}
+
+func (s *sceneRemap) update(dt float32) {
+	genericInput(&s.entry, dt)
+}
+
+func (s *sceneRemap) render() {
+	genericRender(&s.entry)
+}
+
+func (s *sceneRemap) drawHintBar() {
+	genericDrawHintBar()
+}
+
+// Generic stuff
+
+func (s *sceneControls) Entry() *entry {
+	return &s.entry
+}
+
+func (s *sceneControls) segueMount() {
+	genericSegueMount(&s.entry)
+}
+
+func (s *sceneControls) segueNext() {
+	genericSegueNext(&s.entry)
+}
+
+func (s *sceneControls) segueBack() {
+	genericAnimate(&s.entry)
+}
+
+func (s *sceneControls) update(dt float32) {
+	genericInput(&s.entry, dt)
+}
+
+func (s *sceneControls) render() {
+	genericRender(&s.entry)
+}
+
+func (s *sceneControls) drawHintBar() {
+	genericDrawHintBar()
+}
@@ -2,7 +2,10 @@ package menu
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
 
+	"github.com/libretro/ludo/core"
 	ntf "github.com/libretro/ludo/notifications"
 	"github.com/libretro/ludo/state"
 
@@ -21,41 +24,104 @@ func buildCoreDiskControl() Scene {
 
 	list.label = tCoreDiskControl //"Core Disk Control"
 
-	for i := uint(0); i < state.Core.DiskControlCallback.GetNumImages(); i++ {
+	dcc := state.Core.DiskControlCallback
+
+	list.children = append(list.children, entry{
+		label: l10n.T9(&i18n.Message{ID: "DiskTrayOpen", Other: "Disk Tray Open"}),
+		icon:  "subsetting",
+		value: func() interface{} {
+			return dcc.GetEjectState()
+		},
+		widget: widgets["switch"],
+		incr: func(int) {
+			dcc.SetEjectState(!dcc.GetEjectState())
+		},
+		callbackOK: func() {
+			dcc.SetEjectState(!dcc.GetEjectState())
+		},
+	})
+
+	tActive := l10n.T9(&i18n.Message{ID: "DiskActive", Other: "Active"})
+
+	for i := uint(0); i < dcc.GetNumImages(); i++ {
 		index := i
+		label := core.DiskLabel(index)
+		if label == "" {
+			label = fmt.Sprintf("Disk %d", index+1)
+		}
+		var path string
+		if dcc.GetImagePath != nil {
+			path, _ = dcc.GetImagePath(index)
+		}
 		list.children = append(list.children, entry{
-			label: fmt.Sprintf("Disk %d", index+1),
-			icon:  "subsetting",
+			label:    label,
+			subLabel: strings.Replace(path, "%", "%%", -1),
+			icon:     "subsetting",
 			stringValue: func() string {
-				if index == state.Core.DiskControlCallback.GetImageIndex() {
-					return "Active"
+				if index == dcc.GetImageIndex() {
+					return tActive
 				}
 				return ""
 			},
 			callbackOK: func() {
-				if index == state.Core.DiskControlCallback.GetImageIndex() {
+				if index == dcc.GetImageIndex() {
 					return
 				}
-				state.Core.DiskControlCallback.SetEjectState(true)
-				state.Core.DiskControlCallback.SetImageIndex(index)
-				state.Core.DiskControlCallback.SetEjectState(false)
+				ejected := dcc.GetEjectState()
+				if err := core.SwitchDisk(index); err != nil {
+					ntf.DisplayAndLog(ntf.Error, "Menu", err.Error())
+				}
 
 				txtI18n := l10n.T9(&i18n.Message{ID: "Switched2Disk", Other: "Switched to disk %d."})
 				ntf.DisplayAndLog(ntf.Success, "Menu", txtI18n, index+1)
-				state.MenuActive = false
+				// With an open tray, let the user close it before resuming
+				if !ejected {
+					state.MenuActive = false
+				}
 			},
 		})
 	}
 
 	tNoDisk := l10n.T9(&i18n.Message{ID: "NoDisk", Other: "No disk"})
 
-	if len(list.children) == 0 {
+	if dcc.GetNumImages() == 0 {
 		list.children = append(list.children, entry{
 			label: tNoDisk, //"No disk",
 			icon:  "subsetting",
 		})
 	}
 
+	if dcc.AddImageIndex != nil && dcc.ReplaceImageIndex != nil {
+		list.children = append(list.children, entry{
+			label: l10n.T9(&i18n.Message{ID: "DiskAppend", Other: "Append Disk Image"}),
+			icon:  "folder",
+			callbackOK: func() {
+				depth := len(menu.stack)
+				list.segueNext()
+				menu.Push(buildExplorer(
+					filepath.Dir(state.GamePath),
+					nil,
+					func(path string) {
+						if err := core.AppendDisk(path); err != nil {
+							ntf.DisplayAndLog(ntf.Error, "Menu", err.Error())
+							return
+						}
+						txtI18n := l10n.T9(&i18n.Message{ID: "DiskAppended", Other: "Appended %s."})
+						ntf.DisplayAndLog(ntf.Success, "Menu", txtI18n, filepath.Base(path))
+						menu.stack = menu.stack[:depth]
+						scene := buildCoreDiskControl()
+						scene.Entry().ptr = len(scene.Entry().children) - 2
+						scene.segueMount()
+						menu.stack[depth-1] = scene
+						menu.tweens.FastForward()
+					},
+					nil,
+					nil,
+				))
+			},
+		})
+	}
+
 	list.segueMount()
 
 	return &list
@@ -83,6 +149,9 @@ func (s *sceneCoreDiskControl) update(dt float32) {
 
 func (s *sceneCoreDiskControl) render() {
 	genericRender(&s.entry)
+
+	// Path of the selected disk image
+	genericDrawSubLabel(&s.entry)
 }
 
 func (s *sceneCoreDiskControl) drawHintBar() {
@@ -5,6 +5,7 @@ import (
 
 	"github.com/libretro/ludo/core"
 	ntf "github.com/libretro/ludo/notifications"
+	"github.com/libretro/ludo/options"
 	"github.com/libretro/ludo/state"
 
 	"github.com/libretro/ludo/l10n"
@@ -13,14 +14,25 @@ import (
 
 type sceneCoreOptions struct {
 	entry
+	category string // key of the listed category, empty for the root
 }
 
 func buildCoreOptions() Scene {
-	var list sceneCoreOptions
-
 	tCoreOptions := l10n.T9(&i18n.Message{ID: "CoreOptions", Other: "Core Options"})
 
-	list.label = tCoreOptions //"Core Options"
+	if core.Options != nil && state.Core != nil && state.Core.OptionsUpdateDisplayCallback != nil {
+		state.Core.OptionsUpdateDisplayCallback()
+	}
+
+	return buildCoreOptionsCategory(tCoreOptions, "")
+}
+
+// buildCoreOptionsCategory lists the categories and the visible options of a
+// category. The root of the core options is the empty category.
+func buildCoreOptionsCategory(label, category string) Scene {
+	var list sceneCoreOptions
+	list.label = label
+	list.category = category
 
 	if core.Options == nil {
 		tNoOptions := l10n.T9(&i18n.Message{ID: "NoOptions", Other: "No options"})
@@ -33,14 +45,43 @@ func buildCoreOptions() Scene {
 		return &list
 	}
 
-	for _, v := range core.Options.Vars {
-		v := v
+	overridable := category == "" && state.CoreRunning
+	if overridable {
 		list.children = append(list.children, entry{
-			label: strings.Replace(v.Desc, "%", "%%", -1),
+			label: l10n.T9(&i18n.Message{ID: "CoreOptionsLayer", Other: "Options Saved For"}),
 			icon:  "subsetting",
 			stringValue: func() string {
-				val := v.Choices[v.Choice]
-				return strings.Replace(val, "%", "%%", -1)
+				return layerName(core.Options.Layer())
+			},
+		})
+	}
+
+	if category == "" {
+		for _, c := range core.Options.Categories {
+			c := c
+			if len(core.Options.Visible(c.Key)) == 0 {
+				continue
+			}
+			list.children = append(list.children, entry{
+				label:    strings.Replace(c.Desc, "%", "%%", -1),
+				subLabel: strings.Replace(c.Info, "%", "%%", -1),
+				icon:     "folder",
+				callbackOK: func() {
+					list.segueNext()
+					menu.Push(buildCoreOptionsCategory(c.Desc, c.Key))
+				},
+			})
+		}
+	}
+
+	for _, v := range core.Options.Visible(category) {
+		v := v
+		list.children = append(list.children, entry{
+			label:    strings.Replace(v.Desc, "%", "%%", -1),
+			subLabel: strings.Replace(v.Info, "%", "%%", -1),
+			icon:     "subsetting",
+			stringValue: func() string {
+				return strings.Replace(v.Label(), "%", "%%", -1)
 			},
 			incr: func(direction int) {
 				v.Choice += direction
@@ -54,8 +95,52 @@ func buildCoreOptions() Scene {
 				if err != nil {
 					ntf.DisplayAndLog(ntf.Error, "Core", "Error saving core options: %v", err.Error())
 				}
+				updateCoreOptionsDisplay()
+			},
+		})
+	}
+
+	if overridable {
+		list.children = append(list.children, entry{
+			label: l10n.T9(&i18n.Message{ID: "CoreOptionsSaveGame", Other: "Save for this Game"}),
+			icon:  "menu_saving",
+			callbackOK: func() {
+				saveCoreOptionsOverride(options.LayerGame)
+			},
+		})
+
+		list.children = append(list.children, entry{
+			label: l10n.T9(&i18n.Message{ID: "CoreOptionsSaveDirectory", Other: "Save for this Directory"}),
+			icon:  "menu_saving",
+			callbackOK: func() {
+				saveCoreOptionsOverride(options.LayerDirectory)
 			},
 		})
+
+		if core.Options.Layer() != options.LayerCore {
+			list.children = append(list.children, entry{
+				label: l10n.T9(&i18n.Message{ID: "CoreOptionsRemoveOverride", Other: "Remove Override"}),
+				icon:  "close",
+				callbackOK: func() {
+					if err := core.Options.RemoveOverride(); err != nil {
+						ntf.DisplayAndLog(ntf.Error, "Core", err.Error())
+						return
+					}
+					txtI18n := l10n.T9(&i18n.Message{ID: "CoreOptionsOverrideRemoved", Other: "Override removed."})
+					ntf.DisplayAndLog(ntf.Success, "Core", txtI18n)
+					refreshCoreOptions()
+				},
+			})
+		}
+	}
+
+	if len(list.children) == 0 {
+		tNoOptions := l10n.T9(&i18n.Message{ID: "NoOptions", Other: "No options"})
+
+		list.children = append(list.children, entry{
+			label: tNoOptions, //"No options",
+			icon:  "subsetting",
+		})
 	}
 
 	list.segueMount()
@@ -63,6 +148,59 @@ func buildCoreOptions() Scene {
 	return &list
 }
 
+// updateCoreOptionsDisplay lets the core show or hide options depending on the
+// new values, and rebuilds the current list in place if it did
+func updateCoreOptionsDisplay() {
+	if state.Core == nil || state.Core.OptionsUpdateDisplayCallback == nil {
+		return
+	}
+	if !state.Core.OptionsUpdateDisplayCallback() {
+		return
+	}
+	refreshCoreOptions()
+}
+
+// layerName returns the human readable name of a core options layer
+func layerName(l options.Layer) string {
+	switch l {
+	case options.LayerDirectory:
+		return l10n.T9(&i18n.Message{ID: "CoreOptionsLayerDirectory", Other: "Directory"})
+	case options.LayerGame:
+		return l10n.T9(&i18n.Message{ID: "CoreOptionsLayerGame", Other: "Game"})
+	}
+	return l10n.T9(&i18n.Message{ID: "CoreOptionsLayerCore", Other: "Core"})
+}
+
+// saveCoreOptionsOverride saves the core options for the current game or its
+// directory
+func saveCoreOptionsOverride(l options.Layer) {
+	if err := core.Options.SaveAs(l); err != nil {
+		ntf.DisplayAndLog(ntf.Error, "Core", err.Error())
+		return
+	}
+	txtI18n := l10n.T9(&i18n.Message{ID: "CoreOptionsOverrideSaved", Other: "Options saved for the %s."})
+	ntf.DisplayAndLog(ntf.Success, "Core", txtI18n, strings.ToLower(layerName(l)))
+	refreshCoreOptions()
+}
+
+// refreshCoreOptions rebuilds the current core options list in place, keeping
+// the cursor
+func refreshCoreOptions() {
+	list, ok := menu.stack[len(menu.stack)-1].(*sceneCoreOptions)
+	if !ok {
+		return
+	}
+	scene := buildCoreOptionsCategory(list.label, list.category)
+	ptr := list.ptr
+	if ptr >= len(scene.Entry().children) {
+		ptr = len(scene.Entry().children) - 1
+	}
+	scene.Entry().ptr = ptr
+	scene.segueMount()
+	menu.stack[len(menu.stack)-1] = scene
+	menu.tweens.FastForward()
+}
+
 func (s *sceneCoreOptions) Entry() *entry {
 	return &s.entry
 }
@@ -85,6 +223,9 @@ func (s *sceneCoreOptions) update(dt float32) {
 
 func (s *sceneCoreOptions) render() {
 	genericRender(&s.entry)
+
+	// Detailed description of the selected option
+	genericDrawSubLabel(&s.entry)
 }
 
 func (s *sceneCoreOptions) drawHintBar() {
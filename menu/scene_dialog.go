@@ -12,6 +12,7 @@ import (
 type sceneDialog struct {
 	entry
 	title, line1, line2 string
+	callbackCancel      func()
 }
 
 func buildYesNoDialog(title, line1, line2 string, callbackOK func()) Scene {
@@ -28,6 +29,14 @@ func buildYesNoDialog(title, line1, line2 string, callbackOK func()) Scene {
 	return &list
 }
 
+// buildYesNoCancelDialog is a yes/no dialog that also calls back when the user
+// answers no
+func buildYesNoCancelDialog(title, line1, line2 string, callbackOK, callbackCancel func()) Scene {
+	list := buildYesNoDialog(title, line1, line2, callbackOK).(*sceneDialog)
+	list.callbackCancel = callbackCancel
+	return list
+}
+
 func (s *sceneDialog) Entry() *entry {
 	return &s.entry
 }
@@ -55,6 +64,9 @@ func (s *sceneDialog) update(dt float32) {
 		audio.PlayEffect(audio.Effects["cancel"])
 		menu.stack[len(menu.stack)-2].segueBack()
 		menu.stack = menu.stack[:len(menu.stack)-1]
+		if s.callbackCancel != nil {
+			s.callbackCancel()
+		}
 	}
 }
 
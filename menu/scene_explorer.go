@@ -25,13 +25,13 @@ type sceneExplorer struct {
 // Prettifier processes a file name
 type Prettifier func(string) string
 
+// matchesExtensions tells if a file name ends with one of the extensions,
+// regardless of the case. Extensions can have more than one dot, like .tar.gz
 func matchesExtensions(f os.FileInfo, exts []string) bool {
-	if len(exts) > 0 {
-		var fileExtension = filepath.Ext(f.Name())
-		for _, ext := range exts {
-			if ext == fileExtension {
-				return true
-			}
+	name := strings.ToLower(f.Name())
+	for _, ext := range exts {
+		if strings.HasSuffix(name, strings.ToLower(ext)) {
+			return true
 		}
 	}
 	return false
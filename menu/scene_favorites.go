@@ -89,7 +89,7 @@ func loadFavoritesEntry(list Scene, game favorites.Game) {
 		list.segueNext()
 		menu.Push(buildQuickMenu())
 		menu.tweens.FastForward() // position the elements without animating
-		state.MenuActive = false
+		resumeGame(game.Path)
 	} else {
 		list.segueNext()
 		menu.Push(buildQuickMenu())
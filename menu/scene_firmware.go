@@ -0,0 +1,94 @@
+package menu
+
+import (
+	"strings"
+
+	"github.com/libretro/ludo/coreinfo"
+	"github.com/libretro/ludo/firmware"
+	"github.com/libretro/ludo/state"
+
+	"github.com/libretro/ludo/l10n"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type sceneFirmware struct {
+	entry
+}
+
+// firmwareStatus returns a human readable status of a firmware file
+func firmwareStatus(fw coreinfo.Firmware) string {
+	if s := firmware.StatusOf(fw); s != firmware.Missing || !fw.Optional {
+		return registryStatus(s)
+	}
+	return l10n.T9(&i18n.Message{ID: "FirmwareMissingOptional", Other: "Missing (Optional)"})
+}
+
+func buildFirmware() Scene {
+	var list sceneFirmware
+	list.label = l10n.T9(&i18n.Message{ID: "CoreFirmware", Other: "Core Firmware"})
+
+	info, ok := coreinfo.Get(state.CorePath)
+	for _, fw := range info.Firmware {
+		fw := fw
+		status := firmwareStatus(fw)
+		label := fw.Desc
+		if label == "" {
+			label = fw.Path
+		}
+		list.children = append(list.children, entry{
+			label:    label,
+			subLabel: strings.Replace(fw.Path, "%", "%%", -1),
+			icon:     "subsetting",
+			stringValue: func() string {
+				return status
+			},
+		})
+	}
+
+	if !ok {
+		list.children = append(list.children, entry{
+			label: l10n.T9(&i18n.Message{ID: "NoCoreInfo", Other: "No info file for this core"}),
+			icon:  "subsetting",
+		})
+	} else if len(list.children) == 0 {
+		list.children = append(list.children, entry{
+			label: l10n.T9(&i18n.Message{ID: "NoFirmware", Other: "No firmware needed"}),
+			icon:  "subsetting",
+		})
+	}
+
+	list.segueMount()
+
+	return &list
+}
+
+func (s *sceneFirmware) Entry() *entry {
+	return &s.entry
+}
+
+func (s *sceneFirmware) segueMount() {
+	genericSegueMount(&s.entry)
+}
+
+func (s *sceneFirmware) segueNext() {
+	genericSegueNext(&s.entry)
+}
+
+func (s *sceneFirmware) segueBack() {
+	genericAnimate(&s.entry)
+}
+
+func (s *sceneFirmware) update(dt float32) {
+	genericInput(&s.entry, dt)
+}
+
+func (s *sceneFirmware) render() {
+	genericRender(&s.entry)
+
+	// Path of the selected file in the system directory
+	genericDrawSubLabel(&s.entry)
+}
+
+func (s *sceneFirmware) drawHintBar() {
+	genericDrawHintBar()
+}
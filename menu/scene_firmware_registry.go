@@ -0,0 +1,108 @@
+package menu
+
+import (
+	"sort"
+
+	"github.com/libretro/ludo/firmware"
+	ntf "github.com/libretro/ludo/notifications"
+
+	"github.com/libretro/ludo/l10n"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type sceneFirmwareRegistry struct {
+	entry
+}
+
+// registryStatus returns a human readable status of a firmware file
+func registryStatus(s firmware.Status) string {
+	switch s {
+	case firmware.Present:
+		return l10n.T9(&i18n.Message{ID: "FirmwareFound", Other: "Found"})
+	case firmware.BadHash:
+		return l10n.T9(&i18n.Message{ID: "FirmwareMismatch", Other: "Bad Checksum"})
+	}
+	return l10n.T9(&i18n.Message{ID: "FirmwareMissing", Other: "Missing"})
+}
+
+func buildFirmwareRegistry() Scene {
+	var list sceneFirmwareRegistry
+	list.label = l10n.T9(&i18n.Message{ID: "Firmware", Other: "Firmware"})
+
+	list.children = append(list.children, entry{
+		label: l10n.T9(&i18n.Message{ID: "FirmwareRescan", Other: "Scan System Directory"}),
+		icon:  "scan",
+		callbackOK: func() {
+			firmware.Scan()
+			scene := buildFirmwareRegistry()
+			scene.segueMount()
+			menu.stack[len(menu.stack)-1] = scene
+			menu.tweens.FastForward()
+			txtI18n := l10n.T9(&i18n.Message{ID: "FirmwareScanned", Other: "System directory scanned."})
+			ntf.DisplayAndLog(ntf.Success, "Menu", txtI18n)
+		},
+	})
+
+	// One entry per file, grouped by system
+	files := map[string]string{}
+	for _, f := range firmware.Registry {
+		files[f.Name] = f.System
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if files[names[i]] != files[names[j]] {
+			return files[names[i]] < files[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	for _, name := range names {
+		status := registryStatus(firmware.Result(name))
+		list.children = append(list.children, entry{
+			label:    name,
+			subLabel: files[name],
+			icon:     "subsetting",
+			stringValue: func() string {
+				return status
+			},
+		})
+	}
+
+	list.segueMount()
+
+	return &list
+}
+
+func (s *sceneFirmwareRegistry) Entry() *entry {
+	return &s.entry
+}
+
+func (s *sceneFirmwareRegistry) segueMount() {
+	genericSegueMount(&s.entry)
+}
+
+func (s *sceneFirmwareRegistry) segueNext() {
+	genericSegueNext(&s.entry)
+}
+
+func (s *sceneFirmwareRegistry) segueBack() {
+	genericAnimate(&s.entry)
+}
+
+func (s *sceneFirmwareRegistry) update(dt float32) {
+	genericInput(&s.entry, dt)
+}
+
+func (s *sceneFirmwareRegistry) render() {
+	genericRender(&s.entry)
+
+	// System of the selected file
+	genericDrawSubLabel(&s.entry)
+}
+
+func (s *sceneFirmwareRegistry) drawHintBar() {
+	genericDrawHintBar()
+}
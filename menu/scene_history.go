@@ -88,7 +88,7 @@ func loadHistoryEntry(list Scene, game history.Game) {
 		list.segueNext()
 		menu.Push(buildQuickMenu())
 		menu.tweens.FastForward() // position the elements without animating
-		state.MenuActive = false
+		resumeGame(game.Path)
 	} else {
 		list.segueNext()
 		menu.Push(buildQuickMenu())
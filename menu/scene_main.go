@@ -4,13 +4,16 @@ import (
 	"os/exec"
 	"os/user"
 	"path/filepath"
+	"strings"
 
 	"github.com/libretro/ludo/core"
+	"github.com/libretro/ludo/coreinfo"
 	"github.com/libretro/ludo/history"
 	ntf "github.com/libretro/ludo/notifications"
 	"github.com/libretro/ludo/settings"
 	"github.com/libretro/ludo/state"
 	"github.com/libretro/ludo/utils"
+	"github.com/libretro/ludo/vfs"
 
 	"github.com/libretro/ludo/l10n"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
@@ -20,48 +23,27 @@ type sceneMain struct {
 	entry
 }
 
-var prettyCoreNames = map[string]string{
-	"atari800_libretro":            "Atari - 5200 (Atari800)",
-	"bluemsx_libretro":             "MSX/SVI/ColecoVision/SG-1000 (blueMSX)",
-	"fbneo_libretro":               "Arcade (FinalBurn Neo)",
-	"fceumm_libretro":              "Nintendo - NES / Famicom (FCEUmm)",
-	"gambatte_libretro":            "Nintendo - Game Boy / Color (Gambatte)",
-	"gearsystem_libretro":          "Sega - MS/GG/SG-1000 (Gearsystem)",
-	"genesis_plus_gx_libretro":     "Sega - MS/GG/MD/CD (Genesis Plus GX)",
-	"handy_libretro":               "Atari - Lynx (Handy)",
-	"lutro_libretro":               "Lua Engine (Lutro)",
-	"mednafen_ngp_libretro":        "SNK - Neo Geo Pocket / Color (Beetle NeoPop)",
-	"mednafen_pce_fast_libretro":   "NEC - PC Engine / CD (Beetle PCE FAST)",
-	"mednafen_pce_libretro":        "NEC - PC Engine / SuperGrafx / CD (Beetle PCE)",
-	"mednafen_pcfx_libretro":       "NEC - PC-FX (Beetle PC-FX)",
-	"mednafen_psx_libretro":        "Sony - PlayStation (Beetle PSX)",
-	"mednafen_saturn_libretro":     "Sega - Saturn (Beetle Saturn)",
-	"mednafen_supergrafx_libretro": "NEC - PC Engine SuperGrafx (Beetle SuperGrafx)",
-	"mednafen_vb_libretro":         "Nintendo - Virtual Boy (Beetle VB)",
-	"mednafen_wswan_libretro":      "Bandai - WonderSwan/Color (Beetle Cygne)",
-	"melonds_libretro":             "Nintendo - DS (melonDS)",
-	"mgba_libretro":                "Nintendo - Game Boy Advance (mGBA)",
-	"mupen64plus_next_libretro":    "Nintendo - Nintendo 64 (Mupen64Plus-Next)",
-	"np2kai_libretro":              "NEC - PC-98 (Neko Project II Kai)",
-	"o2em_libretro":                "Magnavox - Odyssey2 / Phillips Videopac+ (O2EM)",
-	"pcsx_rearmed_libretro":        "Sony - PlayStation (PCSX ReARMed)",
-	"picodrive_libretro":           "Sega - MS/GG/MD/CD/32X (PicoDrive)",
-	"pokemini_libretro":            "Nintendo - Pokemon Mini (PokeMini)",
-	"prosystem_libretro":           "Atari - 7800 (ProSystem)",
-	"sameboy_libretro":             "Nintendo - Game Boy / Color (SameBoy)",
-	"snes9x_libretro":              "Nintendo - SNES / SFC (Snes9x - Current)",
-	"stella2014_libretro":          "Atari - 2600 (Stella 2014)",
-	"swanstation_libretro":         "Sony - PlayStation (SwanStation)",
-	"vecx_libretro":                "GCE - Vectrex (vecx)",
-	"virtualjaguar_libretro":       "Atari - Jaguar (Virtual Jaguar)",
+func prettifyCoreName(in string) string {
+	return coreinfo.DisplayName(in)
 }
 
-func prettifyCoreName(in string) string {
-	name, ok := prettyCoreNames[in]
-	if ok {
-		return name
+// gameExtensions lists the extensions of the games the current core can load,
+// from its info file or from the core itself. Archives and .zst files are
+// extracted by Ludo, so they are always listed. A nil list means any file.
+func gameExtensions() []string {
+	var exts []string
+	if info, ok := coreinfo.Get(state.CorePath); ok && len(info.Extensions) > 0 {
+		exts = info.ExtensionsWithDot()
+	} else if si := state.Core.GetSystemInfo(); si.ValidExtensions != "" {
+		for _, ext := range strings.Split(si.ValidExtensions, "|") {
+			exts = append(exts, "."+ext)
+		}
+	}
+	if len(exts) == 0 {
+		return nil
 	}
-	return in
+	exts = append(exts, vfs.ArchiveExtensions...)
+	return append(exts, ".zst")
 }
 
 func buildMainMenu() Scene {
@@ -113,7 +95,7 @@ func buildMainMenu() Scene {
 				list.segueNext()
 				menu.Push(buildExplorer(
 					usr.HomeDir,
-					nil,
+					gameExtensions(),
 					gameExplorerCb,
 					nil,
 					nil,
@@ -129,6 +111,30 @@ func buildMainMenu() Scene {
 		},
 	})
 
+	if state.Core != nil && len(state.Core.Subsystems) > 0 {
+		tLoadSubsystem := l10n.T9(&i18n.Message{ID: "LoadSubsystem", Other: "Load Subsystem"})
+
+		list.children = append(list.children, entry{
+			label: tLoadSubsystem, //"Load Subsystem",
+			icon:  "subsetting",
+			callbackOK: func() {
+				list.segueNext()
+				menu.Push(buildSubsystem())
+			},
+		})
+	}
+
+	if state.Core != nil {
+		list.children = append(list.children, entry{
+			label: l10n.T9(&i18n.Message{ID: "CoreFirmware", Other: "Core Firmware"}),
+			icon:  "subsetting",
+			callbackOK: func() {
+				list.segueNext()
+				menu.Push(buildFirmware())
+			},
+		})
+	}
+
 	if state.LudOS {
 		tUpdater := l10n.T9(&i18n.Message{ID: "Updater", Other: "Updater"})
 
@@ -0,0 +1,280 @@
+package menu
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/libretro/ludo/memsearch"
+	ntf "github.com/libretro/ludo/notifications"
+	"github.com/libretro/ludo/state"
+
+	"github.com/libretro/ludo/l10n"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// maxSearchResults is the number of results listed in the menu
+const maxSearchResults = 100
+
+// memSearchView is how the values are read, kept between two visits of the
+// scene
+var memSearchView = memsearch.View{Size: 1}
+
+type sceneMemSearch struct {
+	entry
+}
+
+func buildMemSearch() Scene {
+	var list sceneMemSearch
+	list.label = l10n.T9(&i18n.Message{ID: "RAMSearch", Other: "RAM Search"})
+
+	// Rebuild the scene in place to refresh the entries after a change
+	refresh := func() {
+		scene := buildMemSearch()
+		scene.Entry().ptr = list.ptr
+		scene.segueMount()
+		menu.stack[len(menu.stack)-1] = scene
+		menu.tweens.FastForward()
+	}
+
+	list.children = append(list.children, entry{
+		label: l10n.T9(&i18n.Message{ID: "RAMSearchSize", Other: "Value Size"}),
+		icon:  "subsetting",
+		stringValue: func() string {
+			return fmt.Sprintf("%d-bit", memSearchView.Size*8)
+		},
+		incr: func(direction int) {
+			sizes := []int{1, 2, 4}
+			i := 0
+			for j, s := range sizes {
+				if s == memSearchView.Size {
+					i = j
+				}
+			}
+			i = (i + direction + len(sizes)) % len(sizes)
+			memSearchView.Size = sizes[i]
+			if memsearch.Current() != nil {
+				memsearch.Start(memSearchView)
+			}
+		},
+	})
+
+	list.children = append(list.children, entry{
+		label: l10n.T9(&i18n.Message{ID: "RAMSearchBigEndian", Other: "Big Endian"}),
+		icon:  "subsetting",
+		value: func() interface{} {
+			return memSearchView.BigEndian
+		},
+		widget: widgets["switch"],
+		incr: func(int) {
+			memSearchView.BigEndian = !memSearchView.BigEndian
+			if memsearch.Current() != nil {
+				memsearch.Start(memSearchView)
+			}
+		},
+	})
+
+	list.children = append(list.children, entry{
+		label: l10n.T9(&i18n.Message{ID: "RAMSearchNew", Other: "New Search"}),
+		icon:  "scan",
+		callbackOK: func() {
+			if len(memsearch.Regions()) == 0 {
+				txtI18n := l10n.T9(&i18n.Message{ID: "RAMSearchNoMemory", Other: "The core doesn't expose its memory."})
+				ntf.DisplayAndLog(ntf.Error, "Menu", txtI18n)
+				return
+			}
+			memsearch.Start(memSearchView)
+			refresh()
+		},
+	})
+
+	if memsearch.Current() != nil {
+		list.children = append(list.children, entry{
+			label: l10n.T9(&i18n.Message{ID: "RAMSearchEqual", Other: "Equal To"}),
+			icon:  "subsetting",
+			callbackOK: func() {
+				list.segueNext()
+				menu.Push(buildKeyboard(
+					l10n.T9(&i18n.Message{ID: "RAMSearchValue", Other: "Value"}),
+					func(s string) {
+						v, err := strconv.ParseUint(s, 0, 32)
+						if err != nil {
+							ntf.DisplayAndLog(ntf.Error, "Menu", err.Error())
+							return
+						}
+						memsearch.Current().Filter(memsearch.OpEqual, uint32(v))
+						menu.stack[len(menu.stack)-2] = buildMemSearch()
+					},
+				))
+			},
+		})
+
+		filters := []struct {
+			label string
+			op    memsearch.Op
+		}{
+			{l10n.T9(&i18n.Message{ID: "RAMSearchUnchanged", Other: "Unchanged"}), memsearch.OpUnchanged},
+			{l10n.T9(&i18n.Message{ID: "RAMSearchChanged", Other: "Changed"}), memsearch.OpChanged},
+			{l10n.T9(&i18n.Message{ID: "RAMSearchGreater", Other: "Greater"}), memsearch.OpGreater},
+			{l10n.T9(&i18n.Message{ID: "RAMSearchLess", Other: "Less"}), memsearch.OpLess},
+		}
+		for _, f := range filters {
+			f := f
+			list.children = append(list.children, entry{
+				label: f.label,
+				icon:  "subsetting",
+				callbackOK: func() {
+					memsearch.Current().Filter(f.op, 0)
+					refresh()
+				},
+			})
+		}
+
+		list.children = append(list.children, entry{
+			label: l10n.T9(&i18n.Message{ID: "RAMSearchResults", Other: "Results"}),
+			icon:  "subsetting",
+			stringValue: func() string {
+				return strconv.Itoa(memsearch.Current().Count())
+			},
+			callbackOK: func() {
+				list.segueNext()
+				menu.Push(buildMemResults())
+			},
+		})
+	}
+
+	list.children = append(list.children, entry{
+		label: l10n.T9(&i18n.Message{ID: "RAMWatchList", Other: "Watch List"}),
+		icon:  "subsetting",
+		stringValue: func() string {
+			return strconv.Itoa(len(memsearch.Watches()))
+		},
+		callbackOK: func() {
+			list.segueNext()
+			menu.Push(buildMemWatches())
+		},
+	})
+
+	list.segueMount()
+
+	return &list
+}
+
+func buildMemResults() Scene {
+	var list sceneMemSearch
+	list.label = l10n.T9(&i18n.Message{ID: "RAMSearchResults", Other: "Results"})
+
+	search := memsearch.Current()
+	results := search.Results(maxSearchResults)
+	if len(results) == 0 {
+		list.children = append(list.children, entry{
+			label: l10n.T9(&i18n.Message{ID: "RAMSearchNoResults", Other: "Too many or no results"}),
+			icon:  "subsetting",
+		})
+	}
+
+	for _, r := range results {
+		w := memsearch.Watch{Address: r.Address, View: search.View}
+		list.children = append(list.children, entry{
+			label: fmt.Sprintf("%06X", r.Address),
+			icon:  "subsetting",
+			stringValue: func() string {
+				v, _ := w.Value()
+				return strconv.FormatUint(uint64(v), 10)
+			},
+			callbackOK: func() {
+				memsearch.AddWatch(w)
+				txtI18n := l10n.T9(&i18n.Message{ID: "RAMWatchAdded", Other: "%06X added to the watch list."})
+				ntf.DisplayAndLog(ntf.Success, "Menu", txtI18n, w.Address)
+			},
+		})
+	}
+
+	list.segueMount()
+
+	return &list
+}
+
+func buildMemWatches() Scene {
+	var list sceneMemSearch
+	list.label = l10n.T9(&i18n.Message{ID: "RAMWatchList", Other: "Watch List"})
+
+	if len(memsearch.Watches()) == 0 {
+		list.children = append(list.children, entry{
+			label: l10n.T9(&i18n.Message{ID: "RAMWatchEmpty", Other: "Empty"}),
+			icon:  "subsetting",
+		})
+	}
+
+	for i, w := range memsearch.Watches() {
+		i, w := i, w
+		list.children = append(list.children, entry{
+			label: fmt.Sprintf("%06X", w.Address),
+			icon:  "subsetting",
+			stringValue: func() string {
+				v, _ := w.Value()
+				return strconv.FormatUint(uint64(v), 10)
+			},
+			callbackX: func() {
+				memsearch.RemoveWatch(i)
+				menu.stack[len(menu.stack)-1] = buildMemWatches()
+				menu.tweens.FastForward()
+			},
+		})
+	}
+
+	list.segueMount()
+
+	return &list
+}
+
+// Generic stuff
+
+func (s *sceneMemSearch) Entry() *entry {
+	return &s.entry
+}
+
+func (s *sceneMemSearch) segueMount() {
+	genericSegueMount(&s.entry)
+}
+
+func (s *sceneMemSearch) segueNext() {
+	genericSegueNext(&s.entry)
+}
+
+func (s *sceneMemSearch) segueBack() {
+	genericAnimate(&s.entry)
+}
+
+func (s *sceneMemSearch) update(dt float32) {
+	genericInput(&s.entry, dt)
+}
+
+func (s *sceneMemSearch) render() {
+	genericRender(&s.entry)
+}
+
+func (s *sceneMemSearch) drawHintBar() {
+	w, h := menu.GetFramebufferSize()
+	menu.DrawRect(0, float32(h)-70*menu.ratio, float32(w), 70*menu.ratio, 0, lightGrey)
+
+	_, upDown, _, a, b, x, _, _, _, guide := hintIcons()
+
+	tHBarResume := l10n.T9(&i18n.Message{ID: "HBarResume", Other: "RESUME"})
+	tHBarNavigate := l10n.T9(&i18n.Message{ID: "HBarNavigate", Other: "NAVIGATE"})
+	tHBarBack := l10n.T9(&i18n.Message{ID: "HBarBack", Other: "BACK"})
+	tHBarOk := l10n.T9(&i18n.Message{ID: "HBarOk", Other: "OK"})
+	tHBarDelete := l10n.T9(&i18n.Message{ID: "HBarDelete", Other: "DELETE"})
+
+	var stack float32
+	if state.CoreRunning {
+		stackHint(&stack, guide, tHBarResume, h)
+	}
+	stackHint(&stack, upDown, tHBarNavigate, h)
+	stackHint(&stack, b, tHBarBack, h)
+	stackHint(&stack, a, tHBarOk, h)
+
+	list := menu.stack[len(menu.stack)-1].Entry()
+	if list.children[list.ptr].callbackX != nil {
+		stackHint(&stack, x, tHBarDelete, h)
+	}
+}
@@ -0,0 +1,132 @@
+package menu
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/libretro/ludo/movie"
+	ntf "github.com/libretro/ludo/notifications"
+	"github.com/libretro/ludo/settings"
+	"github.com/libretro/ludo/state"
+	"github.com/libretro/ludo/utils"
+
+	"github.com/libretro/ludo/l10n"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type sceneMovie struct {
+	entry
+}
+
+func buildMovie() Scene {
+	var list sceneMovie
+	list.label = l10n.T9(&i18n.Message{ID: "Movie", Other: "Movie"})
+
+	if movie.Active() {
+		list.children = append(list.children, entry{
+			label: l10n.T9(&i18n.Message{ID: "MovieStop", Other: "Stop"}),
+			icon:  "close",
+			callbackOK: func() {
+				recording := movie.Recording()
+				movie.Stop()
+				if recording {
+					txtI18n := l10n.T9(&i18n.Message{ID: "MovieSaved", Other: "Movie saved."})
+					ntf.DisplayAndLog(ntf.Success, "Menu", txtI18n)
+				}
+				menu.stack[len(menu.stack)-2].segueBack()
+				menu.stack = menu.stack[:len(menu.stack)-1]
+			},
+		})
+		list.segueMount()
+		return &list
+	}
+
+	list.children = append(list.children, entry{
+		label: l10n.T9(&i18n.Message{ID: "MovieRecordPowerOn", Other: "Record From Power On"}),
+		icon:  "savestate",
+		callbackOK: func() {
+			recordMovie(false)
+		},
+	})
+
+	list.children = append(list.children, entry{
+		label: l10n.T9(&i18n.Message{ID: "MovieRecordHere", Other: "Record From Here"}),
+		icon:  "savestate",
+		callbackOK: func() {
+			recordMovie(true)
+		},
+	})
+
+	gameName := utils.FileName(state.GamePath)
+	gameName = strings.Replace(gameName, "[", "\\[", -1)
+	gameName = strings.Replace(gameName, "]", "\\]", -1)
+	paths, _ := filepath.Glob(settings.Current.MoviesDirectory + "/" + gameName + "@*.lmv")
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+	for _, path := range paths {
+		path := path
+		date := strings.Replace(utils.FileName(path), gameName+"@", "", 1)
+		list.children = append(list.children, entry{
+			label: l10n.T9(&i18n.Message{ID: "MoviePlay", Other: "Play"}) + " " + date,
+			icon:  "loadstate",
+			callbackOK: func() {
+				if err := movie.Play(path); err != nil {
+					ntf.DisplayAndLog(ntf.Error, "Menu", err.Error())
+					return
+				}
+				state.MenuActive = false
+				state.FastForward = false
+			},
+		})
+	}
+
+	list.segueMount()
+
+	return &list
+}
+
+// recordMovie starts recording a movie named after the game and the date
+func recordMovie(fromSavestate bool) {
+	if err := os.MkdirAll(settings.Current.MoviesDirectory, os.ModePerm); err != nil {
+		ntf.DisplayAndLog(ntf.Error, "Menu", err.Error())
+		return
+	}
+	path := filepath.Join(settings.Current.MoviesDirectory, utils.DatedName(state.GamePath)+".lmv")
+	if err := movie.Record(path, fromSavestate); err != nil {
+		ntf.DisplayAndLog(ntf.Error, "Menu", err.Error())
+		return
+	}
+	state.MenuActive = false
+	state.FastForward = false
+}
+
+// Generic stuff
+
+func (s *sceneMovie) Entry() *entry {
+	return &s.entry
+}
+
+func (s *sceneMovie) segueMount() {
+	genericSegueMount(&s.entry)
+}
+
+func (s *sceneMovie) segueNext() {
+	genericSegueNext(&s.entry)
+}
+
+func (s *sceneMovie) segueBack() {
+	genericAnimate(&s.entry)
+}
+
+func (s *sceneMovie) update(dt float32) {
+	genericInput(&s.entry, dt)
+}
+
+func (s *sceneMovie) render() {
+	genericRender(&s.entry)
+}
+
+func (s *sceneMovie) drawHintBar() {
+	genericDrawHintBar()
+}
@@ -0,0 +1,123 @@
+package menu
+
+import (
+	"fmt"
+
+	"github.com/libretro/ludo/netplay"
+	ntf "github.com/libretro/ludo/notifications"
+	"github.com/libretro/ludo/settings"
+	"github.com/libretro/ludo/state"
+
+	"github.com/libretro/ludo/l10n"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type sceneNetplay struct {
+	entry
+}
+
+func buildNetplay() Scene {
+	var list sceneNetplay
+	list.label = l10n.T9(&i18n.Message{ID: "Netplay", Other: "Netplay"})
+
+	if netplay.Active() {
+		list.children = append(list.children, entry{
+			label: l10n.T9(&i18n.Message{ID: "NetplayDisconnect", Other: "Disconnect"}),
+			icon:  "close",
+			callbackOK: func() {
+				netplay.Stop()
+				menu.stack[len(menu.stack)-2].segueBack()
+				menu.stack = menu.stack[:len(menu.stack)-1]
+			},
+		})
+	} else {
+		list.children = append(list.children, entry{
+			label: l10n.T9(&i18n.Message{ID: "NetplayHost", Other: "Host"}),
+			icon:  "menu_network",
+			stringValue: func() string {
+				return fmt.Sprintf(":%d", settings.Current.NetplayPort)
+			},
+			callbackOK: func() {
+				if err := netplay.Host(); err != nil {
+					ntf.DisplayAndLog(ntf.Error, "Netplay", err.Error())
+					return
+				}
+				state.MenuActive = false
+				state.FastForward = false
+			},
+		})
+
+		if settings.Current.NetplayHost != "" {
+			list.children = append(list.children, entry{
+				label: l10n.T9(&i18n.Message{ID: "NetplayJoinLast", Other: "Join Last Host"}),
+				icon:  "menu_network",
+				stringValue: func() string {
+					return settings.Current.NetplayHost
+				},
+				callbackOK: func() {
+					joinNetplay(settings.Current.NetplayHost)
+				},
+			})
+		}
+
+		list.children = append(list.children, entry{
+			label: l10n.T9(&i18n.Message{ID: "NetplayJoin", Other: "Join"}),
+			icon:  "menu_network",
+			callbackOK: func() {
+				list.segueNext()
+				menu.Push(buildKeyboard(
+					l10n.T9(&i18n.Message{ID: "NetplayHostAddress", Other: "Host address"}),
+					joinNetplay,
+				))
+			},
+		})
+	}
+
+	list.segueMount()
+
+	return &list
+}
+
+// joinNetplay joins a host and remembers its address for next time
+func joinNetplay(addr string) {
+	if err := netplay.Join(addr); err != nil {
+		ntf.DisplayAndLog(ntf.Error, "Netplay", err.Error())
+		return
+	}
+	settings.Current.NetplayHost = addr
+	if err := settings.Save(); err != nil {
+		ntf.DisplayAndLog(ntf.Error, "Netplay", err.Error())
+	}
+	state.MenuActive = false
+	state.FastForward = false
+}
+
+// Generic stuff
+
+func (s *sceneNetplay) Entry() *entry {
+	return &s.entry
+}
+
+func (s *sceneNetplay) segueMount() {
+	genericSegueMount(&s.entry)
+}
+
+func (s *sceneNetplay) segueNext() {
+	genericSegueNext(&s.entry)
+}
+
+func (s *sceneNetplay) segueBack() {
+	genericAnimate(&s.entry)
+}
+
+func (s *sceneNetplay) update(dt float32) {
+	genericInput(&s.entry, dt)
+}
+
+func (s *sceneNetplay) render() {
+	genericRender(&s.entry)
+}
+
+func (s *sceneNetplay) drawHintBar() {
+	genericDrawHintBar()
+}
@@ -130,7 +130,7 @@ func loadPlaylistEntry(list *scenePlaylist, playlist string, game playlists.Game
 		list.segueNext()
 		menu.Push(buildQuickMenu())
 		menu.tweens.FastForward() // position the elements without animating
-		state.MenuActive = false
+		resumeGame(game.Path)
 	} else {
 		list.segueNext()
 		menu.Push(buildQuickMenu())
@@ -88,6 +88,15 @@ func buildQuickMenu() Scene {
 		},
 	})
 
+	list.children = append(list.children, entry{
+		label: l10n.T9(&i18n.Message{ID: "SaveRAMBackups", Other: "Save RAM Backups"}),
+		icon:  "states",
+		callbackOK: func() {
+			list.segueNext()
+			menu.Push(buildSaveRAMBackups())
+		},
+	})
+
 	tTakeScreenshot := l10n.T9(&i18n.Message{ID: "TakeScreenshot", Other: "Take Screenshot"})
 
 	list.children = append(list.children, entry{
@@ -116,6 +125,89 @@ func buildQuickMenu() Scene {
 		},
 	})
 
+	tControls := l10n.T9(&i18n.Message{ID: "Controls", Other: "Controls"})
+
+	list.children = append(list.children, entry{
+		label: tControls,
+		icon:  "core-input-remapping-options",
+		callbackOK: func() {
+			list.segueNext()
+			menu.Push(buildControls())
+		},
+	})
+
+	tGameFocus := l10n.T9(&i18n.Message{ID: "GameFocus", Other: "Game Focus"})
+
+	list.children = append(list.children, entry{
+		label: tGameFocus,
+		icon:  "subsetting",
+		value: func() interface{} {
+			return state.GameFocus
+		},
+		widget: widgets["switch"],
+		callbackOK: func() {
+			toggleGameFocus()
+		},
+		incr: func(int) {
+			toggleGameFocus()
+		},
+	})
+
+	tNetplay := l10n.T9(&i18n.Message{ID: "Netplay", Other: "Netplay"})
+
+	list.children = append(list.children, entry{
+		label: tNetplay,
+		icon:  "menu_network",
+		callbackOK: func() {
+			list.segueNext()
+			menu.Push(buildNetplay())
+		},
+	})
+
+	tMovie := l10n.T9(&i18n.Message{ID: "Movie", Other: "Movie"})
+
+	list.children = append(list.children, entry{
+		label: tMovie,
+		icon:  "menu_saving",
+		callbackOK: func() {
+			list.segueNext()
+			menu.Push(buildMovie())
+		},
+	})
+
+	tCheats := l10n.T9(&i18n.Message{ID: "Cheats", Other: "Cheats"})
+
+	list.children = append(list.children, entry{
+		label: tCheats,
+		icon:  "core-cheat-options",
+		callbackOK: func() {
+			list.segueNext()
+			menu.Push(buildCheats())
+		},
+	})
+
+	tAchievements := l10n.T9(&i18n.Message{ID: "Achievements", Other: "Achievements"})
+
+	list.children = append(list.children, entry{
+		label: tAchievements,
+		icon:  "subsetting",
+		callbackOK: func() {
+			list.segueNext()
+			menu.Push(buildAchievements())
+		},
+	})
+
+	tRAMSearch := l10n.T9(&i18n.Message{ID: "RAMSearch", Other: "RAM Search"})
+
+	list.children = append(list.children, entry{
+		label: tRAMSearch,
+		icon:  "scan",
+		callbackOK: func() {
+			list.segueNext()
+			menu.Push(buildMemSearch())
+		},
+	})
+
 	tDiskControl := l10n.T9(&i18n.Message{ID: "DiskControl", Other: "Disk Control"})
 
 	if state.Core != nil && state.Core.DiskControlCallback != nil {
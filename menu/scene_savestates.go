@@ -1,11 +1,15 @@
 package menu
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/libretro/ludo/core"
+	"github.com/libretro/ludo/history"
 	ntf "github.com/libretro/ludo/notifications"
 	"github.com/libretro/ludo/savestates"
 	"github.com/libretro/ludo/settings"
@@ -59,9 +63,10 @@ func buildSavestates() Scene {
 		path := path
 		date := strings.Replace(utils.FileName(path), gameName+"@", "", 1)
 		list.children = append(list.children, entry{
-			label: "Load " + date, // TODO: !Локализовать!
-			icon:  "loadstate",
-			path:  path,
+			label:    "Load " + date, // TODO: !Локализовать!
+			subLabel: savestateInfo(path),
+			icon:     "loadstate",
+			path:     path,
 			callbackOK: func() {
 				err := savestates.Load(path)
 				if err != nil {
@@ -82,6 +87,63 @@ func buildSavestates() Scene {
 	return &list
 }
 
+// autoSavestate returns the auto savestate of a game, empty if there is none.
+// The one recorded in the history is preferred.
+func autoSavestate(gamePath string) string {
+	paths := []string{savestates.AutoPath(gamePath)}
+	if g, ok := history.Find(gamePath); ok && g.Savestate != "" {
+		paths = append([]string{g.Savestate}, paths...)
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// resumeGame closes the menu on a freshly loaded game, after loading its auto
+// savestate or asking to, depending on the settings
+func resumeGame(gamePath string) {
+	path := autoSavestate(gamePath)
+	if path == "" || settings.Current.SavestateResume == savestates.ResumeNever {
+		state.MenuActive = false
+		return
+	}
+
+	// Some cores reject or mis-apply the states loaded before their first
+	// frame, so a frame is run first, without output
+	resume := func() {
+		core.Step(false)
+		if err := savestates.Load(path); err != nil {
+			ntf.DisplayAndLog(ntf.Error, "Menu", err.Error())
+		} else {
+			txtI18n := l10n.T9(&i18n.Message{ID: "GameResumed", Other: "Resumed from the auto savestate."})
+			ntf.DisplayAndLog(ntf.Success, "Menu", txtI18n)
+		}
+		state.MenuActive = false
+	}
+
+	if settings.Current.SavestateResume == savestates.ResumeAlways {
+		resume()
+		return
+	}
+	askResumeConfirmation(resume, func() { state.MenuActive = false })
+}
+
+// savestateInfo describes a savestate from its metadata: the core it was made
+// with, and the play time
+func savestateInfo(path string) string {
+	m, err := savestates.ReadMetadata(path)
+	if err != nil {
+		return ""
+	}
+	played := time.Duration(m.PlayTime) * time.Second
+	txtI18n := l10n.T9(&i18n.Message{ID: "SavestateInfo", Other: "%s %s, played %dh%02d"})
+	info := fmt.Sprintf(txtI18n, m.CoreName, m.CoreVersion, int(played.Hours()), int(played.Minutes())%60)
+	return strings.Replace(info, "%", "%%", -1)
+}
+
 func (s *sceneSavestates) Entry() *entry {
 	return &s.entry
 }
@@ -114,7 +176,7 @@ func removeSavestateEntry(s []entry, path string) []entry {
 }
 
 func deleteSavestateEntry(list *sceneSavestates, path string) {
-	err := os.Remove(path)
+	err := savestates.Delete(path)
 	if err != nil {
 		txtI18n := l10n.T9(&i18n.Message{ID: "CouldNotDelSavState", Other: "Could not delete savestate: %s"})
 		ntf.DisplayAndLog(ntf.Error, "Menu", txtI18n, err.Error())
@@ -169,6 +231,14 @@ func (s *sceneSavestates) render() {
 				840*menu.ratio,
 				float32(h)*e.yp+fontOffset,
 				0.5*menu.ratio, e.label)
+
+			if e.subLabel != "" {
+				menu.Font.SetColor(mediumGrey.Alpha(e.subLabelAlpha))
+				menu.Font.Printf(
+					840*menu.ratio,
+					float32(h)*e.yp+fontOffset+45*menu.ratio,
+					0.4*menu.ratio, e.subLabel)
+			}
 		}
 	}
 }
@@ -9,8 +9,13 @@ import (
 	"github.com/go-gl/glfw/v3.3/glfw"
 
 	"github.com/libretro/ludo/audio"
+	"github.com/libretro/ludo/coreinfo"
+	"github.com/libretro/ludo/firmware"
 	"github.com/libretro/ludo/ludos"
 	ntf "github.com/libretro/ludo/notifications"
+	"github.com/libretro/ludo/rewind"
+	"github.com/libretro/ludo/rumble"
+	"github.com/libretro/ludo/savestates"
 	"github.com/libretro/ludo/settings"
 	"github.com/libretro/ludo/state"
 	"github.com/libretro/ludo/utils"
@@ -49,6 +54,29 @@ func buildSettings() Scene {
 		})
 	}
 
+	list.children = append(list.children, entry{
+		label: l10n.T9(&i18n.Message{ID: "Firmware", Other: "Firmware"}),
+		icon:  "subsetting",
+		callbackOK: func() {
+			list.segueNext()
+			menu.Push(buildFirmwareRegistry())
+		},
+	})
+
+	list.children = append(list.children, entry{
+		label: l10n.T9(&i18n.Message{ID: "CompressSavestates", Other: "Compress Existing Savestates"}),
+		icon:  "states",
+		callbackOK: func() {
+			count, saved, err := savestates.CompressAll()
+			if err != nil {
+				ntf.DisplayAndLog(ntf.Error, "Settings", err.Error())
+				return
+			}
+			txtI18n := l10n.T9(&i18n.Message{ID: "SavestatesCompressed", Other: "Compressed %d savestates, saved %.1f MB."})
+			ntf.DisplayAndLog(ntf.Success, "Settings", txtI18n, count, float64(saved)/1024/1024)
+		},
+	})
+
 	tSelectDir := l10n.T9(&i18n.Message{ID: "SelectDir", Other: "<Select this directory>"})
 
 	fields := structs.Fields(&settings.Current)
@@ -124,6 +152,12 @@ func dirExplorerCb(path string, f *structs.Field) {
 		return
 	}
 	f.Set(path)
+	switch f.Tag("toml") {
+	case "system_dir":
+		firmware.Scan()
+	case "info_dir":
+		coreinfo.Load()
+	}
 	txtI18n := l10n.T9(&i18n.Message{ID: "SetTo", Other: "%s set to %s"})
 	ntf.DisplayAndLog(ntf.Success, "Settings", txtI18n, settings.SettingLabel(f.Tag("toml")), f.Value().(string))
 	err = settings.Save()
@@ -205,6 +239,40 @@ var incrCallbacks = map[string]callbackIncrement{
 		menu.UpdateFilter(filters[i])
 		settings.Save()
 	},
+	"SavestateCompression": func(f *structs.Field, direction int) {
+		methods := savestates.Compressions
+		v := f.Value().(string)
+		i := utils.IndexOfString(v, methods)
+		i += direction
+		if i < 0 {
+			i = len(methods) - 1
+		}
+		if i > len(methods)-1 {
+			i = 0
+		}
+		f.Set(methods[i])
+		settings.Save()
+	},
+	"SavestateAuto": func(f *structs.Field, direction int) {
+		v := f.Value().(bool)
+		v = !v
+		f.Set(v)
+		settings.Save()
+	},
+	"SavestateResume": func(f *structs.Field, direction int) {
+		modes := savestates.Resumes
+		v := f.Value().(string)
+		i := utils.IndexOfString(v, modes)
+		i += direction
+		if i < 0 {
+			i = len(modes) - 1
+		}
+		if i > len(modes)-1 {
+			i = 0
+		}
+		f.Set(modes[i])
+		settings.Save()
+	},
 	"Language": func(f *structs.Field, direction int) {
 		filters := []string{"en", "ru"}
 		v := f.Value().(string)
@@ -239,6 +307,62 @@ var incrCallbacks = map[string]callbackIncrement{
 		f.Set(v)
 		settings.Save()
 	},
+	"RewindEnabled": func(f *structs.Field, direction int) {
+		v := f.Value().(bool)
+		v = !v
+		f.Set(v)
+		rewind.Reset()
+		settings.Save()
+	},
+	"RewindBufferSize": func(f *structs.Field, direction int) {
+		v := f.Value().(int)
+		v += 16 * direction
+		if v < 16 {
+			v = 16
+		}
+		if v > 1024 {
+			v = 1024
+		}
+		f.Set(v)
+		settings.Save()
+	},
+	"RewindGranularity": func(f *structs.Field, direction int) {
+		v := f.Value().(int)
+		v += direction
+		if v < 1 {
+			v = 1
+		}
+		if v > 60 {
+			v = 60
+		}
+		f.Set(v)
+		rewind.Reset()
+		settings.Save()
+	},
+	"RunAheadFrames": func(f *structs.Field, direction int) {
+		v := f.Value().(int)
+		v += direction
+		if v < 0 {
+			v = 0
+		}
+		if v > 4 {
+			v = 4
+		}
+		f.Set(v)
+		settings.Save()
+	},
+	"SaveRAMBackups": func(f *structs.Field, direction int) {
+		v := f.Value().(int)
+		v += direction
+		if v < 0 {
+			v = 0
+		}
+		if v > 20 {
+			v = 20
+		}
+		f.Set(v)
+		settings.Save()
+	},
 	"AudioVolume": func(f *structs.Field, direction int) {
 		v := f.Value().(float32)
 		v += 0.1 * float32(direction)
@@ -265,6 +389,13 @@ var incrCallbacks = map[string]callbackIncrement{
 		audio.SetEffectsVolume(v)
 		settings.Save()
 	},
+	"RumbleEnabled": func(f *structs.Field, direction int) {
+		v := f.Value().(bool)
+		v = !v
+		f.Set(v)
+		rumble.Refresh()
+		settings.Save()
+	},
 	"ShowHiddenFiles": func(f *structs.Field, direction int) {
 		v := f.Value().(bool)
 		v = !v
@@ -0,0 +1,78 @@
+package menu
+
+import (
+	ntf "github.com/libretro/ludo/notifications"
+	"github.com/libretro/ludo/savefiles"
+	"github.com/libretro/ludo/state"
+
+	"github.com/libretro/ludo/l10n"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type sceneSaveRAMBackups struct {
+	entry
+}
+
+func buildSaveRAMBackups() Scene {
+	var list sceneSaveRAMBackups
+	list.label = l10n.T9(&i18n.Message{ID: "SaveRAMBackups", Other: "Save RAM Backups"})
+
+	for _, b := range savefiles.Backups() {
+		b := b
+		list.children = append(list.children, entry{
+			label: b.Date.Format("2006-01-02 15:04:05"),
+			icon:  "states",
+			callbackOK: func() {
+				askRestoreSaveRAMConfirmation(func() {
+					if err := savefiles.Restore(b); err != nil {
+						ntf.DisplayAndLog(ntf.Error, "Menu", err.Error())
+						return
+					}
+					state.Core.Reset()
+					txtI18n := l10n.T9(&i18n.Message{ID: "SaveRAMRestored", Other: "Save RAM restored."})
+					ntf.DisplayAndLog(ntf.Success, "Menu", txtI18n)
+					state.MenuActive = false
+				})
+			},
+		})
+	}
+
+	if len(list.children) == 0 {
+		list.children = append(list.children, entry{
+			label: l10n.T9(&i18n.Message{ID: "NoSaveRAMBackups", Other: "No backup yet"}),
+			icon:  "subsetting",
+		})
+	}
+
+	list.segueMount()
+
+	return &list
+}
+
+func (s *sceneSaveRAMBackups) Entry() *entry {
+	return &s.entry
+}
+
+func (s *sceneSaveRAMBackups) segueMount() {
+	genericSegueMount(&s.entry)
+}
+
+func (s *sceneSaveRAMBackups) segueNext() {
+	genericSegueNext(&s.entry)
+}
+
+func (s *sceneSaveRAMBackups) segueBack() {
+	genericAnimate(&s.entry)
+}
+
+func (s *sceneSaveRAMBackups) update(dt float32) {
+	genericInput(&s.entry, dt)
+}
+
+func (s *sceneSaveRAMBackups) render() {
+	genericRender(&s.entry)
+}
+
+func (s *sceneSaveRAMBackups) drawHintBar() {
+	genericDrawHintBar()
+}
@@ -0,0 +1,140 @@
+package menu
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/libretro/ludo/core"
+	"github.com/libretro/ludo/libretro"
+	ntf "github.com/libretro/ludo/notifications"
+	"github.com/libretro/ludo/state"
+
+	"github.com/libretro/ludo/l10n"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type sceneSubsystem struct {
+	entry
+}
+
+func buildSubsystem() Scene {
+	var list sceneSubsystem
+	list.label = l10n.T9(&i18n.Message{ID: "LoadSubsystem", Other: "Load Subsystem"})
+
+	for _, sub := range state.Core.Subsystems {
+		sub := sub
+		list.children = append(list.children, entry{
+			label: sub.Desc,
+			icon:  "subsetting",
+			callbackOK: func() {
+				if len(sub.ROMs) == 0 {
+					return
+				}
+				list.segueNext()
+				usr, _ := user.Current()
+				pickSubsystemROM(sub, nil, usr.HomeDir)
+			},
+		})
+	}
+
+	if len(state.Core.Subsystems) == 0 {
+		list.children = append(list.children, entry{
+			label: l10n.T9(&i18n.Message{ID: "Empty", Other: "Empty"}),
+			icon:  "subsetting",
+		})
+	}
+
+	list.segueMount()
+
+	return &list
+}
+
+// subsystemExtensions converts the valid extensions of a subsystem ROM to the
+// format expected by the explorer
+func subsystemExtensions(rom libretro.SubsystemROMInfo) []string {
+	if rom.ValidExtensions == "" {
+		return nil
+	}
+	var exts []string
+	for _, ext := range strings.Split(rom.ValidExtensions, "|") {
+		exts = append(exts, "."+ext)
+	}
+	return exts
+}
+
+// pickSubsystemROM opens the explorer for the next ROM required by the
+// subsystem, and loads the game once every ROM has been picked.
+func pickSubsystemROM(sub libretro.SubsystemInfo, paths []string, dir string) {
+	rom := sub.ROMs[len(paths)]
+
+	txtI18n := l10n.T9(&i18n.Message{ID: "SubsystemSelectROM", Other: "Select %s"})
+	ntf.DisplayAndLog(ntf.Info, "Menu", txtI18n, rom.Desc)
+
+	// Optional ROMs can be skipped from any directory of the explorer
+	var skip *entry
+	if !rom.Required {
+		skip = &entry{
+			label: l10n.T9(&i18n.Message{ID: "SubsystemSkipROM", Other: "<Skip>"}),
+			icon:  "scan",
+		}
+	}
+
+	cb := func(path string) {
+		// Copy the picked paths, the user can still go back to this explorer
+		picked := append([]string{}, paths...)
+		next := dir
+		if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+			picked = append(picked, "")
+		} else {
+			picked = append(picked, path)
+			next = filepath.Dir(path)
+		}
+
+		if len(picked) < len(sub.ROMs) {
+			menu.stack[len(menu.stack)-1].segueNext()
+			pickSubsystemROM(sub, picked, next)
+			return
+		}
+
+		if err := core.LoadGameSpecial(sub, picked); err != nil {
+			ntf.DisplayAndLog(ntf.Error, "Core", err.Error())
+			return
+		}
+		menu.WarpToQuickMenu()
+		state.MenuActive = false
+	}
+
+	menu.Push(buildExplorer(dir, subsystemExtensions(rom), cb, skip, nil))
+}
+
+// Generic stuff
+
+func (s *sceneSubsystem) Entry() *entry {
+	return &s.entry
+}
+
+func (s *sceneSubsystem) segueMount() {
+	genericSegueMount(&s.entry)
+}
+
+func (s *sceneSubsystem) segueNext() {
+	genericSegueNext(&s.entry)
+}
+
+func (s *sceneSubsystem) segueBack() {
+	genericAnimate(&s.entry)
+}
+
+func (s *sceneSubsystem) update(dt float32) {
+	genericInput(&s.entry, dt)
+}
+
+func (s *sceneSubsystem) render() {
+	genericRender(&s.entry)
+}
+
+func (s *sceneSubsystem) drawHintBar() {
+	genericDrawHintBar()
+}
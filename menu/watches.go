@@ -0,0 +1,47 @@
+package menu
+
+import (
+	"fmt"
+
+	"github.com/libretro/ludo/memsearch"
+)
+
+// RenderWatches draws the values of the watched addresses over the game
+func (m *Menu) RenderWatches() {
+	watches := memsearch.Watches()
+	if len(watches) == 0 {
+		return
+	}
+
+	fbw, fbh := m.GetFramebufferSize()
+	m.Font.UpdateResolution(fbw, fbh)
+
+	var h float32 = 40
+	lines := make([]string, len(watches))
+	var lw float32
+	for i, w := range watches {
+		v, ok := w.Value()
+		if ok {
+			lines[i] = fmt.Sprintf("%06X: %d", w.Address, v)
+		} else {
+			lines[i] = fmt.Sprintf("%06X: ?", w.Address)
+		}
+		if width := m.Font.Width(0.4*m.ratio, lines[i]); width > lw {
+			lw = width
+		}
+	}
+
+	x := float32(fbw) - lw - 45*m.ratio
+	m.DrawRect(
+		x-20*m.ratio,
+		25*m.ratio,
+		lw+40*m.ratio,
+		(h*float32(len(lines))+20)*m.ratio,
+		0.1,
+		black.Alpha(0.7),
+	)
+	m.Font.SetColor(white)
+	for i, line := range lines {
+		m.Font.Printf(x, (25+h*float32(i+1))*m.ratio, 0.4*m.ratio, line)
+	}
+}
@@ -0,0 +1,222 @@
+package movie
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/libretro/ludo/input"
+)
+
+// magic identifies movie files, the last byte is the format version
+var magic = []byte("LUDOMOV1")
+
+// Bounds of the values read from movie files, so a corrupt file can't make us
+// allocate gigabytes
+const (
+	maxString    = 1024      // core name and version
+	maxSavestate = 256 << 20 // bigger than the states of any core
+	maxDevices   = 7         // the libretro device types, from none to pointer
+	maxIDs       = 512       // the keyboard has the most IDs, one per key code
+	maxInputs    = input.MaxPlayers * maxDevices * maxIDs
+)
+
+var errCorrupt = errors.New("corrupt movie file")
+
+// Header describes the context a movie was recorded in
+type Header struct {
+	CoreName    string
+	CoreVersion string
+	ContentCRC  uint32
+	Savestate   []byte // state to start from, nil to start from power on
+}
+
+// Input is an answer given to the input state callback of the core
+type Input struct {
+	Port   uint
+	Device uint32
+	Index  uint
+	ID     uint
+	Value  int16
+}
+
+// Writer writes a movie file. The header is stored as is, and the frames are
+// compressed with gzip.
+type Writer struct {
+	f   *os.File
+	z   *gzip.Writer
+	buf []byte
+}
+
+// Create creates a movie file and writes its header
+func Create(path string, h Header) (*Writer, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	b := append([]byte{}, magic...)
+	b = appendBytes(b, []byte(h.CoreName))
+	b = appendBytes(b, []byte(h.CoreVersion))
+	b = binary.LittleEndian.AppendUint32(b, h.ContentCRC)
+	b = appendBytes(b, h.Savestate)
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &Writer{f: f, z: gzip.NewWriter(f)}, nil
+}
+
+// WriteFrame writes the inputs of a frame
+func (w *Writer) WriteFrame(inputs []Input) error {
+	b := w.buf[:0]
+	b = binary.AppendUvarint(b, uint64(len(inputs)))
+	for _, in := range inputs {
+		b = binary.AppendUvarint(b, uint64(in.Port))
+		b = binary.AppendUvarint(b, uint64(in.Device))
+		b = binary.AppendUvarint(b, uint64(in.Index))
+		b = binary.AppendUvarint(b, uint64(in.ID))
+		b = binary.AppendVarint(b, int64(in.Value))
+	}
+	w.buf = b
+	_, err := w.z.Write(b)
+	return err
+}
+
+// Close flushes the frames and closes the file
+func (w *Writer) Close() error {
+	if err := w.z.Close(); err != nil {
+		w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// Reader reads a movie file frame by frame
+type Reader struct {
+	Header Header
+
+	f *os.File
+	r *bufio.Reader
+}
+
+// Open opens a movie file and reads its header
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := newReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r.f = f
+	return r, nil
+}
+
+func newReader(f io.Reader) (*Reader, error) {
+	br := bufio.NewReader(f)
+
+	m := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, m); err != nil || string(m) != string(magic) {
+		return nil, errors.New("not a movie file")
+	}
+
+	r := &Reader{}
+	name, err := readBytes(br, maxString)
+	if err != nil {
+		return nil, err
+	}
+	version, err := readBytes(br, maxString)
+	if err != nil {
+		return nil, err
+	}
+	crc := make([]byte, 4)
+	if _, err := io.ReadFull(br, crc); err != nil {
+		return nil, err
+	}
+	savestate, err := readBytes(br, maxSavestate)
+	if err != nil {
+		return nil, err
+	}
+	r.Header = Header{
+		CoreName:    string(name),
+		CoreVersion: string(version),
+		ContentCRC:  binary.LittleEndian.Uint32(crc),
+	}
+	if len(savestate) > 0 {
+		r.Header.Savestate = savestate
+	}
+
+	z, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, err
+	}
+	r.r = bufio.NewReader(z)
+	return r, nil
+}
+
+// ReadFrame reads the inputs of the next frame. It returns io.EOF at the end
+// of the movie.
+func (r *Reader) ReadFrame() ([]Input, error) {
+	n, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return nil, err
+	}
+	if n > maxInputs {
+		return nil, errCorrupt
+	}
+	inputs := make([]Input, n)
+	for i := range inputs {
+		var v [4]uint64
+		for j := range v {
+			if v[j], err = binary.ReadUvarint(r.r); err != nil {
+				return nil, io.ErrUnexpectedEOF
+			}
+		}
+		value, err := binary.ReadVarint(r.r)
+		if err != nil {
+			return nil, io.ErrUnexpectedEOF
+		}
+		inputs[i] = Input{Port: uint(v[0]), Device: uint32(v[1]), Index: uint(v[2]), ID: uint(v[3]), Value: int16(value)}
+	}
+	return inputs, nil
+}
+
+// Close closes the file
+func (r *Reader) Close() error {
+	return r.f.Close()
+}
+
+func appendBytes(b []byte, s []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// readBytes reads a length prefixed byte string of at most max bytes. The
+// buffer grows as the bytes are read, so a truncated file can't make us
+// allocate the announced length.
+func readBytes(r *bufio.Reader, max uint64) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > max {
+		return nil, errCorrupt
+	}
+	var b bytes.Buffer
+	if _, err := io.CopyN(&b, r, int64(n)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
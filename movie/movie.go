@@ -0,0 +1,212 @@
+// Package movie records the inputs given to the core to a file, and plays them
+// back to reproduce a session frame by frame. Movies are useful to reproduce
+// bugs, and to check the output of a core against known frame hashes.
+package movie
+
+import (
+	"errors"
+	"io"
+
+	"github.com/libretro/ludo/core"
+	"github.com/libretro/ludo/input"
+	ntf "github.com/libretro/ludo/notifications"
+	"github.com/libretro/ludo/state"
+	"github.com/libretro/ludo/utils"
+
+	"github.com/libretro/ludo/l10n"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+type key struct {
+	port   uint
+	device uint32
+	index  uint
+	id     uint
+}
+
+// playback is the input Source used while playing a movie back. It answers
+// with the inputs recorded for the current frame.
+type playback map[key]int16
+
+func (p playback) State(port uint, device uint32, index uint, id uint) int16 {
+	return p[key{port, device, index, id}]
+}
+
+var (
+	game    string // path of the game the movie belongs to
+	writer  *Writer
+	reader  *Reader
+	inputs  []Input  // inputs of the current frame while recording
+	current playback // inputs of the current frame while playing back
+)
+
+// header describes the running core and game
+func header() (Header, error) {
+	crc, err := utils.CRC32File(state.GamePath)
+	if err != nil {
+		return Header{}, err
+	}
+	si := state.Core.GetSystemInfo()
+	return Header{
+		CoreName:    si.LibraryName,
+		CoreVersion: si.LibraryVersion,
+		ContentCRC:  crc,
+	}, nil
+}
+
+// Record starts recording the inputs to a movie file. If fromSavestate is true
+// the movie starts from the current state, otherwise the game is reset.
+func Record(path string, fromSavestate bool) error {
+	Stop()
+
+	h, err := header()
+	if err != nil {
+		return err
+	}
+	if fromSavestate {
+		h.Savestate, err = state.Core.Serialize(state.Core.SerializeSize())
+		if err != nil {
+			return err
+		}
+	}
+
+	writer, err = Create(path, h)
+	if err != nil {
+		return err
+	}
+
+	if !fromSavestate {
+		state.Core.Reset()
+	}
+	game = state.GamePath
+
+	input.SetObserver(func(port uint, device uint32, index uint, id uint, value int16) {
+		// Only the pressed buttons are stored, playback answers 0 otherwise
+		if value != 0 {
+			inputs = append(inputs, Input{port, device, index, id, value})
+		}
+	})
+	return nil
+}
+
+// Play starts playing a movie back. The movie must have been recorded with the
+// same core and game.
+func Play(path string) error {
+	Stop()
+
+	r, err := Open(path)
+	if err != nil {
+		return err
+	}
+
+	h, err := header()
+	if err != nil {
+		r.Close()
+		return err
+	}
+	if r.Header.CoreName != h.CoreName || r.Header.ContentCRC != h.ContentCRC {
+		r.Close()
+		txtI18n := l10n.T9(&i18n.Message{ID: "MovieMismatch", Other: "the movie was recorded with another core or game"})
+		return errors.New(txtI18n)
+	}
+	if r.Header.CoreVersion != h.CoreVersion {
+		txtI18n := l10n.T9(&i18n.Message{ID: "MovieVersionMismatch", Other: "The movie was recorded with %s %s, it may desync."})
+		ntf.DisplayAndLog(ntf.Warning, "Movie", txtI18n, r.Header.CoreName, r.Header.CoreVersion)
+	}
+
+	if r.Header.Savestate != nil {
+		s := state.Core.SerializeSize()
+		if uint(len(r.Header.Savestate)) != s {
+			r.Close()
+			txtI18n := l10n.T9(&i18n.Message{ID: "MovieStateMismatch", Other: "the savestate of the movie doesn't fit this core"})
+			return errors.New(txtI18n)
+		}
+		if err := state.Core.Unserialize(r.Header.Savestate, s); err != nil {
+			r.Close()
+			return err
+		}
+	} else {
+		state.Core.Reset()
+	}
+
+	game = state.GamePath
+	reader = r
+	current = playback{}
+	input.SetSource(current)
+	return nil
+}
+
+// Recording returns true while a movie is being recorded
+func Recording() bool {
+	return writer != nil
+}
+
+// Playing returns true while a movie is being played back
+func Playing() bool {
+	return reader != nil
+}
+
+// Active returns true while a movie is being recorded or played back
+func Active() bool {
+	return Recording() || Playing()
+}
+
+// Stop stops recording or playing back, and gives the input back to the local
+// devices
+func Stop() {
+	input.SetObserver(nil)
+	input.SetSource(nil)
+	if writer != nil {
+		if err := writer.Close(); err != nil {
+			ntf.DisplayAndLog(ntf.Error, "Movie", err.Error())
+		}
+		writer = nil
+	}
+	if reader != nil {
+		reader.Close()
+		reader = nil
+	}
+	game = ""
+	inputs = nil
+	current = nil
+}
+
+// Frame runs the core for one frame while recording or playing back. It should
+// be called instead of running the core while a movie is active.
+func Frame() {
+	// Another game has been loaded since the movie started
+	if state.GamePath != game {
+		Stop()
+		core.RunFrame()
+		return
+	}
+
+	if writer != nil {
+		inputs = inputs[:0]
+		core.Step(true)
+		if err := writer.WriteFrame(inputs); err != nil {
+			ntf.DisplayAndLog(ntf.Error, "Movie", err.Error())
+			Stop()
+		}
+		return
+	}
+
+	frame, err := reader.ReadFrame()
+	if err != nil {
+		if err == io.EOF {
+			txtI18n := l10n.T9(&i18n.Message{ID: "MovieEnded", Other: "Movie ended"})
+			ntf.DisplayAndLog(ntf.Info, "Movie", txtI18n)
+		} else {
+			ntf.DisplayAndLog(ntf.Error, "Movie", err.Error())
+		}
+		Stop()
+		return
+	}
+	for k := range current {
+		delete(current, k)
+	}
+	for _, in := range frame {
+		current[key{in.Port, in.Device, in.Index, in.ID}] = in.Value
+	}
+	core.Step(true)
+}
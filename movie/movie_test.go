@@ -0,0 +1,174 @@
+package movie
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/libretro/ludo/core"
+	"github.com/libretro/ludo/input"
+	"github.com/libretro/ludo/state"
+	"github.com/libretro/ludo/utils"
+	"github.com/libretro/ludo/video"
+)
+
+func Test_file(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lmv")
+
+	h := Header{
+		CoreName:    "VecX",
+		CoreVersion: "1.2",
+		ContentCRC:  0xdeadbeef,
+		Savestate:   []byte{1, 2, 3},
+	}
+	frames := [][]Input{
+		{},
+		{{Port: 0, Device: 1, Index: 0, ID: 8, Value: 1}},
+		{{Port: 1, Device: 5, Index: 0, ID: 1, Value: -32768}, {Port: 0, Device: 1, Index: 0, ID: 256, Value: 0x0fff}},
+	}
+
+	w, err := Create(path, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range frames {
+		if err := w.WriteFrame(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	t.Run("Reads the header back", func(t *testing.T) {
+		if !reflect.DeepEqual(r.Header, h) {
+			t.Errorf("got = %v, want %v", r.Header, h)
+		}
+	})
+
+	t.Run("Reads the frames back", func(t *testing.T) {
+		for i, want := range frames {
+			got, err := r.ReadFrame()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("frame %d got = %v, want %v", i, got, want)
+			}
+		}
+		if _, err := r.ReadFrame(); err != io.EOF {
+			t.Errorf("got = %v, want %v", err, io.EOF)
+		}
+	})
+
+	t.Run("Rejects other files", func(t *testing.T) {
+		if _, err := Open("../core/testdata/Polar Rescue (USA).vec"); err == nil {
+			t.Errorf("got = %v, want an error", err)
+		}
+	})
+}
+
+func Test_corrupt(t *testing.T) {
+	header := func(savestateLen uint64) []byte {
+		b := append([]byte{}, magic...)
+		b = appendBytes(b, []byte("VecX"))
+		b = appendBytes(b, []byte("1.2"))
+		b = binary.LittleEndian.AppendUint32(b, 0xdeadbeef)
+		return binary.AppendUvarint(b, savestateLen)
+	}
+
+	t.Run("Rejects huge byte lengths", func(t *testing.T) {
+		b := append([]byte{}, magic...)
+		b = binary.AppendUvarint(b, 1<<40)
+		if _, err := newReader(bytes.NewReader(b)); err != errCorrupt {
+			t.Errorf("got = %v, want %v", err, errCorrupt)
+		}
+		if _, err := newReader(bytes.NewReader(header(1 << 40))); err != errCorrupt {
+			t.Errorf("got = %v, want %v", err, errCorrupt)
+		}
+	})
+
+	t.Run("Rejects truncated savestates", func(t *testing.T) {
+		b := append(header(1<<20), 1, 2, 3)
+		if _, err := newReader(bytes.NewReader(b)); err != io.ErrUnexpectedEOF {
+			t.Errorf("got = %v, want %v", err, io.ErrUnexpectedEOF)
+		}
+	})
+
+	t.Run("Rejects huge input counts", func(t *testing.T) {
+		var buf bytes.Buffer
+		buf.Write(header(0))
+		z := gzip.NewWriter(&buf)
+		z.Write(binary.AppendUvarint(nil, 1<<40))
+		z.Close()
+		r, err := newReader(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.ReadFrame(); err != errCorrupt {
+			t.Errorf("got = %v, want %v", err, errCorrupt)
+		}
+	})
+}
+
+func Test_RecordAndPlay(t *testing.T) {
+	state.Headless = true
+
+	vid := &video.Video{}
+	core.Init(vid)
+	core.Load("../core/testdata/vecx_libretro" + utils.CoreExt())
+	utils.CaptureOutput(func() { core.LoadGame("../core/testdata/Polar Rescue (USA).vec") })
+
+	path := filepath.Join(t.TempDir(), "test.lmv")
+
+	if err := Record(path, false); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1500; i++ {
+		// Mash the buttons and the pad to make the game move
+		input.Inject(0, uint16(i/10%2)*0xffff)
+		Frame()
+	}
+	input.Release(0)
+	recorded := vid.FrameCRC()
+	Stop()
+
+	// Run a bit more to make sure playback doesn't start from where we are
+	core.RunHeadless(60, nil, nil)
+
+	if err := Play(path); err != nil {
+		t.Fatal(err)
+	}
+	utils.CaptureOutput(func() {
+		core.RunHeadless(0, Frame, func() bool { return !Active() })
+	})
+
+	t.Run("Playback gives the same frame", func(t *testing.T) {
+		if recorded == 0 {
+			t.Fatal("no frame was recorded")
+		}
+		if got := vid.FrameCRC(); got != recorded {
+			t.Errorf("got = %08x, want %08x", got, recorded)
+		}
+	})
+
+	t.Run("Playback stops at the end of the movie", func(t *testing.T) {
+		if Active() {
+			t.Errorf("got = %v, want %v", Active(), false)
+		}
+	})
+
+	core.UnloadGame()
+	core.Unload()
+	state.Headless = false
+}
@@ -0,0 +1,41 @@
+package netplay
+
+import (
+	"hash/crc32"
+	"unsafe"
+
+	"github.com/libretro/ludo/core"
+	"github.com/libretro/ludo/input"
+	"github.com/libretro/ludo/libretro"
+	"github.com/libretro/ludo/state"
+)
+
+// coreEmulator is the Emulator backed by the loaded libretro core
+type coreEmulator struct{}
+
+func (coreEmulator) Reset() {
+	state.Core.Reset()
+}
+
+func (coreEmulator) Serialize() ([]byte, error) {
+	return state.Core.Serialize(state.Core.SerializeSize())
+}
+
+func (coreEmulator) Unserialize(bytes []byte) error {
+	return state.Core.Unserialize(bytes, uint(len(bytes)))
+}
+
+func (coreEmulator) Run(port0, port1 uint16, output bool) {
+	input.Inject(0, port0)
+	input.Inject(1, port1)
+	core.Step(output)
+}
+
+func (coreEmulator) Hash() uint32 {
+	size := state.Core.GetMemorySize(libretro.MemorySystemRAM)
+	ptr := state.Core.GetMemoryData(libretro.MemorySystemRAM)
+	if ptr == nil || size == 0 {
+		return 0
+	}
+	return crc32.ChecksumIEEE(unsafe.Slice((*byte)(ptr), size))
+}
@@ -0,0 +1,120 @@
+// Package netplay implements rollback netplay between two instances of Ludo
+// over UDP. Each player runs the game locally, and the inputs of ports 0 and 1
+// are exchanged every frame. When the input of the other player is late, it is
+// predicted, and the emulation is rolled back and replayed once it arrives.
+package netplay
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/libretro/ludo/input"
+	ntf "github.com/libretro/ludo/notifications"
+	"github.com/libretro/ludo/settings"
+	"github.com/libretro/ludo/state"
+	"github.com/libretro/ludo/utils"
+
+	"github.com/libretro/ludo/l10n"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+var (
+	session   *Session
+	connected bool // used to notify only once
+	desynced  bool // used to notify only once
+)
+
+// contentID identifies the core and the game, both players need the same
+func contentID() (string, error) {
+	crc, err := utils.CRC32File(state.GamePath)
+	if err != nil {
+		return "", err
+	}
+	si := state.Core.GetSystemInfo()
+	return fmt.Sprintf("%s %s %08x", si.LibraryName, si.LibraryVersion, crc), nil
+}
+
+// Host waits for another player to join on the netplay port
+func Host() error {
+	Stop()
+	id, err := contentID()
+	if err != nil {
+		return err
+	}
+	session, err = NewHost(":"+strconv.Itoa(settings.Current.NetplayPort), coreEmulator{}, id)
+	if err != nil {
+		return err
+	}
+	txtI18n := l10n.T9(&i18n.Message{ID: "NetplayWaiting", Other: "Waiting for a player on port %d"})
+	ntf.DisplayAndLog(ntf.Info, "Netplay", txtI18n, settings.Current.NetplayPort)
+	return nil
+}
+
+// Join joins a player hosting a game. The port is optional in addr.
+func Join(addr string) error {
+	Stop()
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, strconv.Itoa(settings.Current.NetplayPort))
+	}
+	id, err := contentID()
+	if err != nil {
+		return err
+	}
+	session, err = NewClient(addr, coreEmulator{}, id)
+	if err != nil {
+		return err
+	}
+	txtI18n := l10n.T9(&i18n.Message{ID: "NetplayJoining", Other: "Joining %s"})
+	ntf.DisplayAndLog(ntf.Info, "Netplay", txtI18n, addr)
+	return nil
+}
+
+// Active returns true if a netplay session is running
+func Active() bool {
+	return session != nil
+}
+
+// Stop leaves the current session and gives the ports back to the local players
+func Stop() {
+	if session == nil {
+		return
+	}
+	session.Close()
+	session = nil
+	connected = false
+	desynced = false
+	input.Release(0)
+	input.Release(1)
+}
+
+// Frame runs the next netplay frame with the input of the first local player.
+// It should be called instead of running the core while a session is active.
+func Frame() {
+	session.Advance(input.Buttons(0))
+
+	switch {
+	case session.Rejected != "":
+		txtI18n := l10n.T9(&i18n.Message{ID: "NetplayRejected", Other: "The host runs a different core or game: %s"})
+		ntf.DisplayAndLog(ntf.Error, "Netplay", txtI18n, session.Rejected)
+		Stop()
+		return
+	case session.Closed:
+		txtI18n := l10n.T9(&i18n.Message{ID: "NetplayDisconnected", Other: "The other player left"})
+		ntf.DisplayAndLog(ntf.Warning, "Netplay", txtI18n)
+		Stop()
+		return
+	}
+
+	if session.Connected && !connected {
+		connected = true
+		txtI18n := l10n.T9(&i18n.Message{ID: "NetplayConnected", Other: "Netplay started"})
+		ntf.DisplayAndLog(ntf.Success, "Netplay", txtI18n)
+	}
+
+	if session.Desynced && !desynced {
+		desynced = true
+		txtI18n := l10n.T9(&i18n.Message{ID: "NetplayDesynced", Other: "Desync detected at frame %d"})
+		ntf.DisplayAndLog(ntf.Error, "Netplay", txtI18n, session.DesyncAt)
+	}
+}
@@ -0,0 +1,154 @@
+package netplay
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeEmulator is a deterministic emulator whose state depends on all the
+// inputs it ran with
+type fakeEmulator struct {
+	x       uint32
+	bug     int // frame at which the emulation diverges, if not 0
+	frames  int
+	restore int // number of rollbacks
+}
+
+func (e *fakeEmulator) Reset() { e.x = 0; e.frames = 0 }
+
+func (e *fakeEmulator) Serialize() ([]byte, error) {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint32(b, e.x)
+	binary.LittleEndian.PutUint32(b[4:], uint32(e.frames))
+	return b, nil
+}
+
+func (e *fakeEmulator) Unserialize(b []byte) error {
+	e.x = binary.LittleEndian.Uint32(b)
+	e.frames = int(binary.LittleEndian.Uint32(b[4:]))
+	e.restore++
+	return nil
+}
+
+func (e *fakeEmulator) Run(port0, port1 uint16, output bool) {
+	e.x = e.x*31 + uint32(port0)*7 + uint32(port1)*13 + 1
+	e.frames++
+	if e.bug != 0 && e.frames == e.bug {
+		e.x++
+	}
+}
+
+func (e *fakeEmulator) Hash() uint32 { return e.x }
+
+func newPair(t *testing.T, hostEmu, clientEmu Emulator, hostID, clientID string) (*Session, *Session) {
+	host, err := NewHost("127.0.0.1:0", hostEmu, hostID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(host.LocalAddr().String(), clientEmu, clientID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return host, client
+}
+
+// play advances both sessions until they reach the given frame. The host
+// advances more often than the client to cause mispredictions.
+func play(host, client *Session, frames int) {
+	deadline := time.Now().Add(10 * time.Second)
+	for i := 0; (host.Frame() < frames || client.Frame() < frames) && client.Rejected == "" && time.Now().Before(deadline); i++ {
+		if host.Frame() < frames {
+			host.Advance(uint16(host.Frame() / 7 % 4))
+		}
+		if i%3 == 0 && client.Frame() < frames {
+			client.Advance(uint16(client.Frame() / 5 % 3))
+		}
+		time.Sleep(200 * time.Microsecond)
+	}
+}
+
+func Test_packet(t *testing.T) {
+	t.Run("Encodes and decodes input packets", func(t *testing.T) {
+		want := &packet{kind: packetInput, ack: 12, checkFrame: noFrame, start: 10, inputs: []uint16{1, 2, 0xffff}}
+		got, err := decode(want.encode())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Refuses unknown datagrams", func(t *testing.T) {
+		if _, err := decode([]byte("hello")); err == nil {
+			t.Errorf("got = %v, want an error", err)
+		}
+	})
+}
+
+func Test_Session(t *testing.T) {
+	t.Run("Both peers end up in the same state", func(t *testing.T) {
+		hostEmu, clientEmu := &fakeEmulator{}, &fakeEmulator{}
+		host, client := newPair(t, hostEmu, clientEmu, "game", "game")
+		defer host.Close()
+		defer client.Close()
+
+		play(host, client, 300)
+
+		if host.Desynced || client.Desynced {
+			t.Errorf("got a desync at frame %d/%d", host.DesyncAt, client.DesyncAt)
+		}
+		if host.checkFrame < 180 || client.checkFrame < 180 {
+			t.Fatalf("not enough confirmed frames: %d/%d", host.checkFrame, client.checkFrame)
+		}
+		if host.localHashes[180] != client.localHashes[180] {
+			t.Errorf("got = %v, want %v", client.localHashes[180], host.localHashes[180])
+		}
+		if hostEmu.restore == 0 {
+			t.Errorf("expected the host to roll back at least once")
+		}
+	})
+
+	t.Run("Detects desyncs", func(t *testing.T) {
+		host, client := newPair(t, &fakeEmulator{}, &fakeEmulator{bug: 100}, "game", "game")
+		defer host.Close()
+		defer client.Close()
+
+		play(host, client, 300)
+
+		if !host.Desynced && !client.Desynced {
+			t.Errorf("got = %v, want %v", false, true)
+		}
+	})
+
+	t.Run("The host rejects a different game", func(t *testing.T) {
+		host, client := newPair(t, &fakeEmulator{}, &fakeEmulator{}, "game1", "game2")
+		defer host.Close()
+		defer client.Close()
+
+		play(host, client, 10)
+
+		if client.Rejected != "game1" {
+			t.Errorf("got = %v, want %v", client.Rejected, "game1")
+		}
+		if client.Connected {
+			t.Errorf("got = %v, want %v", client.Connected, false)
+		}
+	})
+
+	t.Run("Notices when the peer leaves", func(t *testing.T) {
+		host, client := newPair(t, &fakeEmulator{}, &fakeEmulator{}, "game", "game")
+		defer host.Close()
+
+		play(host, client, 20)
+		client.Close()
+		time.Sleep(10 * time.Millisecond)
+		host.Advance(0)
+
+		if !host.Closed {
+			t.Errorf("got = %v, want %v", host.Closed, true)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package netplay
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var magic = []byte("LUDN")
+
+// Packet types
+const (
+	packetHello   byte = iota + 1 // sent by the client until the host answers
+	packetWelcome                 // the host accepted the client
+	packetReject                  // the host refused the client
+	packetInput                   // inputs of the sender, and desync check
+	packetBye                     // the sender left the session
+)
+
+// noFrame is used in packets when a frame number is not known yet
+const noFrame = ^uint32(0)
+
+// maxInputsPerPacket bounds the number of redundant inputs sent per packet
+const maxInputsPerPacket = 64
+
+// packet is the decoded form of a datagram exchanged between two peers.
+type packet struct {
+	kind byte
+
+	// Hello, Welcome and Reject
+	id string // identifies the core and the game, must match on both sides
+
+	// Input
+	ack        uint32   // last frame of the receiver the sender got
+	checkFrame uint32   // frame of the checksum, or noFrame
+	checkHash  uint32   // checksum of the system RAM after checkFrame
+	start      uint32   // frame of the first input
+	inputs     []uint16 // inputs of the sender, from start
+}
+
+func (p *packet) encode() []byte {
+	b := append([]byte{}, magic...)
+	b = append(b, p.kind)
+	switch p.kind {
+	case packetHello, packetWelcome, packetReject:
+		b = append(b, []byte(p.id)...)
+	case packetInput:
+		b = binary.BigEndian.AppendUint32(b, p.ack)
+		b = binary.BigEndian.AppendUint32(b, p.checkFrame)
+		b = binary.BigEndian.AppendUint32(b, p.checkHash)
+		b = binary.BigEndian.AppendUint32(b, p.start)
+		b = append(b, byte(len(p.inputs)))
+		for _, in := range p.inputs {
+			b = binary.BigEndian.AppendUint16(b, in)
+		}
+	}
+	return b
+}
+
+func decode(b []byte) (*packet, error) {
+	if len(b) < len(magic)+1 || string(b[:len(magic)]) != string(magic) {
+		return nil, errors.New("not a netplay packet")
+	}
+	p := &packet{kind: b[len(magic)]}
+	b = b[len(magic)+1:]
+	switch p.kind {
+	case packetHello, packetWelcome, packetReject:
+		p.id = string(b)
+	case packetInput:
+		if len(b) < 17 {
+			return nil, errors.New("truncated input packet")
+		}
+		p.ack = binary.BigEndian.Uint32(b[0:])
+		p.checkFrame = binary.BigEndian.Uint32(b[4:])
+		p.checkHash = binary.BigEndian.Uint32(b[8:])
+		p.start = binary.BigEndian.Uint32(b[12:])
+		n := int(b[16])
+		b = b[17:]
+		if len(b) < n*2 {
+			return nil, errors.New("truncated input packet")
+		}
+		p.inputs = make([]uint16, n)
+		for i := range p.inputs {
+			p.inputs[i] = binary.BigEndian.Uint16(b[i*2:])
+		}
+	case packetBye:
+	default:
+		return nil, errors.New("unknown netplay packet")
+	}
+	return p, nil
+}
@@ -0,0 +1,424 @@
+package netplay
+
+import (
+	"net"
+	"time"
+)
+
+const (
+	// historySize is the number of frames of inputs and states we keep
+	historySize = 128
+	// maxRollback is how many frames we can run ahead of the last input
+	// received from the peer before waiting for it
+	maxRollback = 8
+	// checkInterval is the number of frames between two desync checks
+	checkInterval = 60
+	// timeout after which the peer is considered gone
+	timeout = 5 * time.Second
+	// helloInterval is how often the client says hello until the host answers
+	helloInterval = 500 * time.Millisecond
+)
+
+// Emulator is what a session needs to drive the emulation
+type Emulator interface {
+	// Reset restarts the game, so both peers start from the same state
+	Reset()
+	// Serialize returns the current state
+	Serialize() ([]byte, error)
+	// Unserialize restores a state returned by Serialize
+	Unserialize([]byte) error
+	// Run runs one frame with the given joypad states for port 0 and 1.
+	// Output is false when replaying frames after a misprediction.
+	Run(port0, port1 uint16, output bool)
+	// Hash returns a checksum of the system RAM, used to detect desyncs
+	Hash() uint32
+}
+
+// Session is a rollback netplay session between two peers. Each peer runs the
+// emulation locally and sends its inputs to the other one. Missing remote inputs
+// are predicted, and when a prediction turns out to be wrong, the session rolls
+// back to the mispredicted frame and replays the frames with the right inputs.
+type Session struct {
+	conn *net.UDPConn
+	peer *net.UDPAddr
+	emu  Emulator
+	id   string
+	host bool
+
+	packets chan received
+
+	Connected bool   // the peer joined and the game started
+	Rejected  string // id of the host if it refused us because of a mismatch
+	Desynced  bool   // both peers ended up in a different state
+	DesyncAt  int    // first frame where the states differed
+	Closed    bool   // the peer left or timed out
+
+	frame        int // next frame to run
+	remoteFrame  int // last frame for which we have the remote input
+	peerAck      int // last local frame acknowledged by the peer
+	rollbackFrom int // first mispredicted frame, or -1
+	checkFrame   int // last frame of which we know the final hash
+
+	local  [historySize]uint16 // local inputs
+	remote [historySize]uint16 // remote inputs, valid up to remoteFrame
+	used   [historySize]uint16 // remote inputs used to run each frame
+	states [historySize][]byte // states before each frame
+	hashes [historySize]uint32 // hashes after each frame
+
+	localHashes  map[int]uint32
+	remoteHashes map[int]uint32
+
+	lastRecv  time.Time
+	lastHello time.Time
+}
+
+type received struct {
+	p    *packet
+	addr *net.UDPAddr
+}
+
+func newSession(conn *net.UDPConn, emu Emulator, id string, host bool) *Session {
+	s := &Session{
+		conn:         conn,
+		emu:          emu,
+		id:           id,
+		host:         host,
+		packets:      make(chan received, 256),
+		remoteFrame:  -1,
+		peerAck:      -1,
+		rollbackFrom: -1,
+		checkFrame:   -1,
+		localHashes:  map[int]uint32{},
+		remoteHashes: map[int]uint32{},
+		lastRecv:     time.Now(),
+	}
+	go s.recvLoop()
+	return s
+}
+
+// NewHost listens for a client on the given UDP address, like ":55435".
+func NewHost(addr string, emu Emulator, id string) (*Session, error) {
+	laddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", laddr)
+	if err != nil {
+		return nil, err
+	}
+	return newSession(conn, emu, id, true), nil
+}
+
+// NewClient joins the host listening on the given UDP address.
+func NewClient(addr string, emu Emulator, id string) (*Session, error) {
+	raddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return nil, err
+	}
+	s := newSession(conn, emu, id, false)
+	s.peer = raddr
+	return s, nil
+}
+
+// LocalAddr returns the address the session is listening on
+func (s *Session) LocalAddr() net.Addr {
+	return s.conn.LocalAddr()
+}
+
+// Frame returns the number of frames run since the game started
+func (s *Session) Frame() int {
+	return s.frame
+}
+
+// Close leaves the session and notifies the peer
+func (s *Session) Close() {
+	if s.peer != nil && !s.Closed {
+		s.send(&packet{kind: packetBye})
+	}
+	s.Closed = true
+	s.conn.Close()
+}
+
+// recvLoop reads the datagrams and passes them to Advance through a channel.
+// Datagrams are dropped when the channel is full, as Advance isn't called while
+// the menu is open, and a peer flooding us must not block the loop: it has to
+// see the error of the closed socket to end. Lost inputs are sent again.
+func (s *Session) recvLoop() {
+	buf := make([]byte, 2048)
+	for {
+		n, addr, err := s.conn.ReadFromUDP(buf)
+		if err != nil {
+			close(s.packets)
+			return
+		}
+		p, err := decode(buf[:n])
+		if err != nil {
+			continue
+		}
+		select {
+		case s.packets <- received{p, addr}:
+		default:
+		}
+	}
+}
+
+func (s *Session) send(p *packet) {
+	if s.peer == nil {
+		return
+	}
+	s.conn.WriteToUDP(p.encode(), s.peer)
+}
+
+// Advance runs the next frame with the given local input, rolling back first if
+// a misprediction was detected. It returns false if no frame was run, because
+// the peer didn't join yet or because we are too far ahead of it.
+func (s *Session) Advance(input uint16) bool {
+	if s.Closed {
+		return false
+	}
+
+	s.receive()
+	if s.Closed || s.Rejected != "" {
+		return false
+	}
+
+	if !s.Connected {
+		if !s.host && time.Since(s.lastHello) > helloInterval {
+			s.send(&packet{kind: packetHello, id: s.id})
+			s.lastHello = time.Now()
+		}
+		return false
+	}
+
+	if time.Since(s.lastRecv) > timeout {
+		s.Closed = true
+		return false
+	}
+
+	if s.rollbackFrom >= 0 {
+		s.rollback()
+	}
+
+	// Wait for the peer if we are too far ahead
+	if s.frame-s.remoteFrame > maxRollback {
+		s.sendInputs(s.frame - 1)
+		return false
+	}
+
+	f := s.frame
+	s.local[f%historySize] = input
+	s.sendInputs(f)
+
+	st, err := s.emu.Serialize()
+	if err != nil {
+		return false
+	}
+	s.states[f%historySize] = st
+	s.run(f, true)
+	s.frame++
+
+	s.checkDesync()
+	return true
+}
+
+// receive handles the packets received since the last frame
+func (s *Session) receive() {
+	for {
+		select {
+		case r, ok := <-s.packets:
+			if !ok {
+				s.Closed = true
+				return
+			}
+			s.handle(r.p, r.addr)
+		default:
+			return
+		}
+	}
+}
+
+func (s *Session) handle(p *packet, addr *net.UDPAddr) {
+	switch p.kind {
+	case packetHello:
+		if !s.host || (s.peer != nil && s.peer.String() != addr.String()) {
+			return
+		}
+		s.peer = addr
+		if p.id != s.id {
+			s.send(&packet{kind: packetReject, id: s.id})
+			s.peer = nil
+			return
+		}
+		s.send(&packet{kind: packetWelcome, id: s.id})
+		s.start()
+	case packetWelcome:
+		if s.host || s.Connected {
+			return
+		}
+		s.start()
+	case packetReject:
+		if !s.host {
+			s.Rejected = p.id
+		}
+	case packetInput:
+		if !s.Connected || addr.String() != s.peer.String() {
+			return
+		}
+		s.lastRecv = time.Now()
+		s.handleInputs(p)
+	case packetBye:
+		if s.peer != nil && addr.String() == s.peer.String() {
+			s.Closed = true
+		}
+	}
+}
+
+// start resets the game so both peers start from the same state
+func (s *Session) start() {
+	if s.Connected {
+		return
+	}
+	s.Connected = true
+	s.lastRecv = time.Now()
+	s.emu.Reset()
+}
+
+func (s *Session) handleInputs(p *packet) {
+	if p.ack != noFrame && int(p.ack) > s.peerAck {
+		s.peerAck = int(p.ack)
+	}
+
+	for i, in := range p.inputs {
+		f := int(p.start) + i
+		if f != s.remoteFrame+1 {
+			// Already received, or a gap that will be filled by later packets
+			continue
+		}
+		s.remote[f%historySize] = in
+		s.remoteFrame = f
+		if f < s.frame && s.used[f%historySize] != in && (s.rollbackFrom < 0 || f < s.rollbackFrom) {
+			s.rollbackFrom = f
+		}
+	}
+
+	if p.checkFrame != noFrame {
+		s.remoteHashes[int(p.checkFrame)] = p.checkHash
+		s.compareHashes(int(p.checkFrame))
+	}
+}
+
+// sendInputs sends the local inputs up to the given frame that the peer
+// didn't acknowledge yet
+func (s *Session) sendInputs(last int) {
+	start := s.peerAck + 1
+	if last-start+1 > maxInputsPerPacket {
+		start = last - maxInputsPerPacket + 1
+	}
+	inputs := []uint16{}
+	for f := start; f <= last; f++ {
+		inputs = append(inputs, s.local[f%historySize])
+	}
+
+	p := &packet{
+		kind:       packetInput,
+		ack:        noFrame,
+		checkFrame: noFrame,
+		start:      uint32(start),
+		inputs:     inputs,
+	}
+	if s.remoteFrame >= 0 {
+		p.ack = uint32(s.remoteFrame)
+	}
+	if s.checkFrame >= 0 {
+		p.checkFrame = uint32(s.checkFrame)
+		p.checkHash = s.localHashes[s.checkFrame]
+	}
+	s.send(p)
+}
+
+// remoteInput returns the input of the peer for a frame. If we didn't receive
+// it yet, we predict that the peer is still holding the same buttons.
+func (s *Session) remoteInput(f int) uint16 {
+	if f <= s.remoteFrame {
+		return s.remote[f%historySize]
+	}
+	if s.remoteFrame >= 0 {
+		return s.remote[s.remoteFrame%historySize]
+	}
+	return 0
+}
+
+// run runs a frame, the host is always on port 0 and the client on port 1
+func (s *Session) run(f int, output bool) {
+	r := s.remoteInput(f)
+	s.used[f%historySize] = r
+	l := s.local[f%historySize]
+	if s.host {
+		s.emu.Run(l, r, output)
+	} else {
+		s.emu.Run(r, l, output)
+	}
+	s.hashes[f%historySize] = s.emu.Hash()
+}
+
+// rollback restores the state before the first mispredicted frame, and replays
+// the frames up to the current one without output
+func (s *Session) rollback() {
+	from := s.rollbackFrom
+	s.rollbackFrom = -1
+
+	if err := s.emu.Unserialize(s.states[from%historySize]); err != nil {
+		return
+	}
+	for f := from; f < s.frame; f++ {
+		if f > from {
+			st, err := s.emu.Serialize()
+			if err != nil {
+				return
+			}
+			s.states[f%historySize] = st
+		}
+		s.run(f, false)
+	}
+}
+
+// checkDesync records the hashes of the frames that can't be rolled back
+// anymore, because we know the inputs of both peers for them
+func (s *Session) checkDesync() {
+	for {
+		next := (s.checkFrame/checkInterval + 1) * checkInterval
+		if next > s.remoteFrame || next >= s.frame || next < s.frame-historySize || s.rollbackFrom >= 0 {
+			return
+		}
+		s.localHashes[next] = s.hashes[next%historySize]
+		s.checkFrame = next
+		s.compareHashes(next)
+	}
+}
+
+// compareHashes flags the session as desynced if the peers disagree on the
+// hash of a frame
+func (s *Session) compareHashes(f int) {
+	l, lok := s.localHashes[f]
+	r, rok := s.remoteHashes[f]
+	if lok && rok && l != r && !s.Desynced {
+		s.Desynced = true
+		s.DesyncAt = f
+	}
+
+	// Forget old hashes
+	for k := range s.localHashes {
+		if k < f-10*checkInterval {
+			delete(s.localHashes, k)
+		}
+	}
+	for k := range s.remoteHashes {
+		if k < f-10*checkInterval {
+			delete(s.remoteHashes, k)
+		}
+	}
+}
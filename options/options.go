@@ -1,10 +1,14 @@
 // Package options deals with configuration at the libretro core level. Each
 // core exports a list of variables that can take different values. This package
-// can list them, load them, and save them.
+// can list them, load them, and save them, for a core or as overrides for a
+// directory of games or a single game.
 package options
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"hash/crc32"
 	"io"
 	"io/ioutil"
 	"os"
@@ -22,18 +26,39 @@ import (
 // values. The possibilities are stored in v.Choices. The current value
 // can be accessed with v.Choices[v.Choice]
 type Variable struct {
-	Key     string   // unique id of the variable
-	Desc    string   // human readable name of the variable
-	Choices []string // available values
-	Choice  int      // index of the current value
-	Default string
+	Key      string   // unique id of the variable
+	Desc     string   // human readable name of the variable
+	Info     string   // detailed description of the variable
+	Choices  []string // available values
+	Labels   []string // human readable labels of the values, empty to display the value
+	Choice   int      // index of the current value
+	Default  string
+	Category string // key of the category of the variable
+	Hidden   bool   // the core asked not to display the variable
+}
+
+// Label returns the human readable label of the current value
+func (v *Variable) Label() string {
+	if v.Choice < len(v.Labels) && v.Labels[v.Choice] != "" {
+		return v.Labels[v.Choice]
+	}
+	return v.Choices[v.Choice]
+}
+
+// Category groups variables in the menu
+type Category struct {
+	Key  string // unique id of the category
+	Desc string // human readable name of the category
+	Info string // detailed description of the category
 }
 
 // Options is a container type for core options internals
 type Options struct {
-	Vars    []*Variable // the variables exposed by the core
-	Updated bool        // notify the core that values have been updated
+	Vars       []*Variable // the variables exposed by the core
+	Categories []Category  // the categories of variables, since options v2
+	Updated    bool        // notify the core that values have been updated
 
+	layer Layer // the layer the options are saved to
 	sync.Mutex
 }
 
@@ -45,42 +70,159 @@ type VariableInterface interface {
 	DefaultValue() string
 }
 
+// The optional details of the v1 and v2 options
+type (
+	informer    interface{ Info() string }
+	labeler     interface{ Labels() []string }
+	categorizer interface{ Category() string }
+)
+
 // New instantiate a core options manager
-func New(vars []VariableInterface) (*Options, error) {
-	o := &Options{}
+func New(vars []VariableInterface, categories []Category) (*Options, error) {
+	o := &Options{Categories: categories}
 
 	// Cache core options
 	for _, v := range vars {
 		v := v
-		o.Vars = append(o.Vars, &Variable{
+		variable := &Variable{
 			Key:     v.Key(),
 			Desc:    v.Desc(),
 			Choices: v.Choices(),
 			Default: v.DefaultValue(),
 			Choice:  utils.IndexOfString(v.DefaultValue(), v.Choices()),
-		})
+		}
+		if i, ok := v.(informer); ok {
+			variable.Info = i.Info()
+		}
+		if l, ok := v.(labeler); ok {
+			variable.Labels = l.Labels()
+		}
+		if c, ok := v.(categorizer); ok {
+			variable.Category = c.Category()
+		}
+		o.Vars = append(o.Vars, variable)
 	}
 	o.Updated = true
 	err := o.load()
 	return o, err
 }
 
-// Save core options to a file
-func (o *Options) Save() error {
+// SetVisible shows or hides a variable in the menu
+func (o *Options) SetVisible(key string, visible bool) {
 	o.Lock()
 	defer o.Unlock()
 
-	m := make(map[string]string)
 	for _, v := range o.Vars {
-		m[strings.Replace(v.Key, ".", "___", 1)] = v.Choices[v.Choice]
+		if v.Key == key {
+			v.Hidden = !visible
+		}
+	}
+}
+
+// Visible returns the variables of a category that the core wants displayed.
+// The variables outside of any category are returned for an empty key.
+func (o *Options) Visible(category string) []*Variable {
+	o.Lock()
+	defer o.Unlock()
+
+	var vars []*Variable
+	for _, v := range o.Vars {
+		if !v.Hidden && o.categoryOf(v) == category {
+			vars = append(vars, v)
+		}
+	}
+	return vars
+}
+
+// categoryOf returns the category of a variable, or an empty key if the core
+// didn't declare it
+func (o *Options) categoryOf(v *Variable) string {
+	for _, c := range o.Categories {
+		if c.Key == v.Category {
+			return c.Key
+		}
+	}
+	return ""
+}
+
+// Layer is a level of core options files. The options of a game are the
+// options of its core, overridden by the options of its directory, overridden
+// by the options of the game itself.
+type Layer int
+
+// Layers, from the lowest to the highest
+const (
+	LayerCore Layer = iota
+	LayerDirectory
+	LayerGame
+)
+
+// game is the path of the game being played, used to find its overrides
+var game string
+
+// SetGame sets the game whose overrides are merged when loading options
+func SetGame(path string) {
+	game = path
+}
+
+// dirName returns the name of the override file of a directory. The base name
+// keeps it readable, and the checksum of the absolute path tells apart the
+// directories of the same name, like /roms/snes/USA and /roms/psx/USA.
+func dirName(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return fmt.Sprintf("%s-%08x", filepath.Base(dir), crc32.ChecksumIEEE([]byte(dir)))
+}
+
+// layerPath returns the path of the options file of a layer for the current
+// core and game
+func layerPath(l Layer) string {
+	name := utils.FileName(state.CorePath)
+	switch l {
+	case LayerDirectory:
+		return filepath.Join(xdg.ConfigHome, "ludo", "options", name, "directories", dirName(filepath.Dir(game))+".toml")
+	case LayerGame:
+		return filepath.Join(xdg.ConfigHome, "ludo", "options", name, "games", utils.FileName(game)+".toml")
+	}
+	return filepath.Join(xdg.ConfigHome, "ludo", name+".toml")
+}
+
+// readLayer reads the values stored in an options file
+func readLayer(l Layer) (map[string]string, error) {
+	b, err := ioutil.ReadFile(layerPath(l))
+	if err != nil {
+		return nil, err
+	}
+
+	var opts map[string]string
+	if err := toml.Unmarshal(b, &opts); err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	for k, v := range opts {
+		values[strings.Replace(k, "___", ".", 1)] = v
+	}
+	return values, nil
+}
+
+// writeLayer writes values to an options file
+func writeLayer(l Layer, values map[string]string) error {
+	m := make(map[string]string)
+	for k, v := range values {
+		m[strings.Replace(k, ".", "___", 1)] = v
 	}
 	b, err := toml.Marshal(m)
 	if err != nil {
 		return err
 	}
 
-	name := utils.FileName(state.CorePath)
-	fd, err := os.Create(filepath.Join(xdg.ConfigHome, "ludo", name+".toml"))
+	path := layerPath(l)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	fd, err := os.Create(path)
 	if err != nil {
 		return err
 	}
@@ -94,34 +236,132 @@ func (o *Options) Save() error {
 	return fd.Sync()
 }
 
-// Load core options from a file
-func (o *Options) load() error {
+// layers returns the layers that apply to the current game, from the lowest
+func layers() []Layer {
+	if game == "" {
+		return []Layer{LayerCore}
+	}
+	return []Layer{LayerCore, LayerDirectory, LayerGame}
+}
+
+// merged returns the values of the variables as set by the layers below l
+func (o *Options) merged(below Layer) map[string]string {
+	values := map[string]string{}
+	for _, v := range o.Vars {
+		values[v.Key] = v.Default
+	}
+	for _, l := range layers() {
+		if l >= below {
+			break
+		}
+		layer, _ := readLayer(l)
+		for k, v := range layer {
+			values[k] = v
+		}
+	}
+	return values
+}
+
+// Layer returns the layer the options are saved to, the highest layer with a
+// file for the current game
+func (o *Options) Layer() Layer {
 	o.Lock()
 	defer o.Unlock()
+	return o.layer
+}
 
-	name := utils.FileName(state.CorePath)
-	b, err := ioutil.ReadFile(filepath.Join(xdg.ConfigHome, "ludo", name+".toml"))
-	if err != nil {
+// Save core options to the file of the active layer
+func (o *Options) Save() error {
+	return o.SaveAs(o.Layer())
+}
+
+// SaveAs saves the core options to the file of a layer, which becomes the
+// active layer. Overrides only store the values that differ from the layers
+// below.
+func (o *Options) SaveAs(l Layer) error {
+	if l != LayerCore && game == "" {
+		return errors.New("no game loaded")
+	}
+
+	lower := o.merged(l)
+
+	o.Lock()
+	defer o.Unlock()
+
+	values := map[string]string{}
+	for _, v := range o.Vars {
+		if l == LayerCore || v.Choices[v.Choice] != lower[v.Key] {
+			values[v.Key] = v.Choices[v.Choice]
+		}
+	}
+	if err := writeLayer(l, values); err != nil {
 		return err
 	}
+	if l > o.layer {
+		o.layer = l
+	}
+	return nil
+}
 
-	var opts map[string]string
-	err = toml.Unmarshal(b, &opts)
-	if err != nil {
+// RemoveOverride deletes the file of the active layer, unless it is the core
+// layer, and goes back to the values of the layers below
+func (o *Options) RemoveOverride() error {
+	l := o.Layer()
+	if l == LayerCore {
+		return nil
+	}
+	if err := os.Remove(layerPath(l)); err != nil {
 		return err
 	}
+	return o.Reload()
+}
 
-	for optk, optv := range opts {
-		for _, variable := range o.Vars {
-			if variable.Key == strings.Replace(optk, "___", ".", 1) {
-				for j, c := range variable.Choices {
-					if c == optv {
-						variable.Choice = j
+// Reload merges the layers of the current game again, for example after
+// changing game
+func (o *Options) Reload() error {
+	err := o.load()
+	o.Updated = true
+	return err
+}
+
+// Load core options from the files of the layers, merging the overrides of the
+// directory and the game over the options of the core
+func (o *Options) load() error {
+	o.Lock()
+	defer o.Unlock()
+
+	for _, v := range o.Vars {
+		v.Choice = utils.IndexOfString(v.Default, v.Choices)
+	}
+	o.layer = LayerCore
+
+	var errs []string
+	for _, l := range layers() {
+		values, err := readLayer(l)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		o.layer = l
+
+		for key, value := range values {
+			for _, variable := range o.Vars {
+				if variable.Key == key {
+					for j, c := range variable.Choices {
+						if c == value {
+							variable.Choice = j
+						}
 					}
 				}
 			}
 		}
 	}
 
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
 	return nil
 }
@@ -0,0 +1,166 @@
+package options
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/adrg/xdg"
+	"github.com/libretro/ludo/state"
+)
+
+type fakeVariable struct {
+	key, category string
+	choices       []string
+	labels        []string
+}
+
+func (v fakeVariable) Key() string          { return v.key }
+func (v fakeVariable) Desc() string         { return v.key }
+func (v fakeVariable) Info() string         { return "About " + v.key }
+func (v fakeVariable) Choices() []string    { return v.choices }
+func (v fakeVariable) Labels() []string     { return v.labels }
+func (v fakeVariable) Category() string     { return v.category }
+func (v fakeVariable) DefaultValue() string { return v.choices[0] }
+
+func Test_New(t *testing.T) {
+	xdg.ConfigHome = t.TempDir()
+
+	o, _ := New([]VariableInterface{
+		fakeVariable{key: "region", choices: []string{"auto", "ntsc"}, labels: []string{"Automatic", ""}},
+		fakeVariable{key: "renderer", category: "video", choices: []string{"sw", "hw"}},
+		fakeVariable{key: "scaling", category: "video", choices: []string{"1x", "2x"}},
+		fakeVariable{key: "volume", category: "unknown", choices: []string{"100"}},
+	}, []Category{{Key: "video", Desc: "Video"}})
+
+	t.Run("Reads the details of the variables", func(t *testing.T) {
+		v := o.Vars[0]
+		if v.Info != "About region" || v.Label() != "Automatic" {
+			t.Errorf("got = %v, %v", v.Info, v.Label())
+		}
+		v.Choice = 1
+		if v.Label() != "ntsc" {
+			t.Errorf("got = %v, want %v", v.Label(), "ntsc")
+		}
+	})
+
+	keys := func(vars []*Variable) []string {
+		var k []string
+		for _, v := range vars {
+			k = append(k, v.Key)
+		}
+		return k
+	}
+
+	t.Run("Groups the variables by category", func(t *testing.T) {
+		if got := keys(o.Visible("")); !reflect.DeepEqual(got, []string{"region", "volume"}) {
+			t.Errorf("got = %v", got)
+		}
+		if got := keys(o.Visible("video")); !reflect.DeepEqual(got, []string{"renderer", "scaling"}) {
+			t.Errorf("got = %v", got)
+		}
+	})
+
+	t.Run("Hides the variables the core asks to hide", func(t *testing.T) {
+		o.SetVisible("scaling", false)
+		if got := keys(o.Visible("video")); !reflect.DeepEqual(got, []string{"renderer"}) {
+			t.Errorf("got = %v", got)
+		}
+		o.SetVisible("scaling", true)
+		if got := keys(o.Visible("video")); !reflect.DeepEqual(got, []string{"renderer", "scaling"}) {
+			t.Errorf("got = %v", got)
+		}
+	})
+}
+
+func Test_Layers(t *testing.T) {
+	xdg.ConfigHome = t.TempDir()
+	state.CorePath = "/cores/fake_libretro.so"
+	defer func() {
+		state.CorePath = ""
+		SetGame("")
+	}()
+
+	vars := []VariableInterface{
+		fakeVariable{key: "region", choices: []string{"auto", "ntsc", "pal"}},
+		fakeVariable{key: "hack", choices: []string{"off", "on"}},
+	}
+	values := func(o *Options) []string {
+		return []string{o.Vars[0].Choices[o.Vars[0].Choice], o.Vars[1].Choices[o.Vars[1].Choice]}
+	}
+
+	SetGame("/roms/snes/Game A.sfc")
+	o, _ := New(vars, nil)
+	o.Vars[0].Choice = 1
+	if err := o.Save(); err != nil {
+		t.Fatal(err)
+	}
+	o.Vars[1].Choice = 1
+	if err := o.SaveAs(LayerGame); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("The game override only stores what differs from the core", func(t *testing.T) {
+		got, _ := readLayer(LayerGame)
+		if !reflect.DeepEqual(got, map[string]string{"hack": "on"}) {
+			t.Errorf("got = %v", got)
+		}
+	})
+
+	t.Run("Other games of the directory only get the core options", func(t *testing.T) {
+		SetGame("/roms/snes/Game B.sfc")
+		o.Reload()
+		if got := values(o); !reflect.DeepEqual(got, []string{"ntsc", "off"}) {
+			t.Errorf("got = %v", got)
+		}
+		if o.Layer() != LayerCore {
+			t.Errorf("got = %v, want %v", o.Layer(), LayerCore)
+		}
+	})
+
+	t.Run("The directory override applies between the core and the game", func(t *testing.T) {
+		o.Vars[0].Choice = 2
+		if err := o.SaveAs(LayerDirectory); err != nil {
+			t.Fatal(err)
+		}
+		SetGame("/roms/snes/Game A.sfc")
+		o2, _ := New(vars, nil)
+		if got := values(o2); !reflect.DeepEqual(got, []string{"pal", "on"}) {
+			t.Errorf("got = %v", got)
+		}
+		if o2.Layer() != LayerGame {
+			t.Errorf("got = %v, want %v", o2.Layer(), LayerGame)
+		}
+
+		if err := o2.RemoveOverride(); err != nil {
+			t.Fatal(err)
+		}
+		if got := values(o2); !reflect.DeepEqual(got, []string{"pal", "off"}) {
+			t.Errorf("got = %v", got)
+		}
+		if o2.Layer() != LayerDirectory {
+			t.Errorf("got = %v, want %v", o2.Layer(), LayerDirectory)
+		}
+	})
+}
+
+func Test_layerPath(t *testing.T) {
+	state.CorePath = "/cores/fake_libretro.so"
+	defer func() {
+		state.CorePath = ""
+		SetGame("")
+	}()
+
+	SetGame("/roms/snes/USA/Game.sfc")
+	snes := layerPath(LayerDirectory)
+	SetGame("/roms/psx/USA/Game.cue")
+	psx := layerPath(LayerDirectory)
+	SetGame("/roms/snes/USA/Other Game.sfc")
+	snes2 := layerPath(LayerDirectory)
+
+	if snes == psx {
+		t.Errorf("directories of the same name share %v", snes)
+	}
+	if snes != snes2 {
+		t.Errorf("got = %v, want %v", snes2, snes)
+	}
+}
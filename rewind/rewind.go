@@ -0,0 +1,182 @@
+// Package rewind keeps a buffer of recent savestates in memory so the player
+// can step back in time. Snapshots are stored as deltas against the next
+// snapshot to fit in the memory budget set in the settings.
+package rewind
+
+import (
+	"encoding/binary"
+	"log"
+	"sync"
+
+	"github.com/libretro/ludo/settings"
+	"github.com/libretro/ludo/state"
+)
+
+// buffer holds the most recent snapshot in full, and a list of deltas to walk
+// back from it. Deltas are stored oldest first, and the oldest ones are dropped
+// when the budget is exceeded.
+type buffer struct {
+	current []byte   // the most recent snapshot
+	deltas  [][]byte // deltas to step back from current, oldest first
+	used    int      // bytes used by the deltas
+	budget  int      // maximum number of bytes used by the deltas
+}
+
+var (
+	buf   buffer
+	frame int
+	mutex sync.Mutex
+)
+
+// push adds a new snapshot to the buffer
+func (b *buffer) push(snapshot []byte) {
+	if len(b.current) != len(snapshot) {
+		// The state size changed, older deltas can't be applied anymore
+		b.reset()
+		b.current = snapshot
+		return
+	}
+
+	delta := encode(snapshot, b.current)
+	b.deltas = append(b.deltas, delta)
+	b.used += len(delta)
+	b.current = snapshot
+
+	for b.used > b.budget && len(b.deltas) > 0 {
+		b.used -= len(b.deltas[0])
+		b.deltas[0] = nil
+		b.deltas = b.deltas[1:]
+	}
+}
+
+// pop steps back to the previous snapshot and returns it. It returns nil if
+// there is no older snapshot in the buffer.
+func (b *buffer) pop() []byte {
+	if len(b.deltas) == 0 {
+		return nil
+	}
+	last := len(b.deltas) - 1
+	delta := b.deltas[last]
+	b.deltas[last] = nil
+	b.deltas = b.deltas[:last]
+	b.used -= len(delta)
+	decode(delta, b.current)
+	return b.current
+}
+
+// reset drops all the snapshots
+func (b *buffer) reset() {
+	b.current = nil
+	b.deltas = nil
+	b.used = 0
+}
+
+// encode returns a delta that turns a into b. Both slices must have the same
+// length. The delta is a sequence of runs, each made of the number of identical
+// bytes to skip, the number of differing bytes, and those bytes XORed.
+func encode(a, b []byte) []byte {
+	out := []byte{}
+	tmp := make([]byte, binary.MaxVarintLen64)
+	i := 0
+	for i < len(a) {
+		start := i
+		for i < len(a) && a[i] == b[i] {
+			i++
+		}
+		same := i - start
+
+		start = i
+		for i < len(a) && a[i] != b[i] {
+			i++
+		}
+		diff := i - start
+
+		n := binary.PutUvarint(tmp, uint64(same))
+		out = append(out, tmp[:n]...)
+		n = binary.PutUvarint(tmp, uint64(diff))
+		out = append(out, tmp[:n]...)
+		for j := start; j < i; j++ {
+			out = append(out, a[j]^b[j])
+		}
+	}
+	return out
+}
+
+// decode applies a delta produced by encode to dst, in place.
+func decode(delta []byte, dst []byte) {
+	pos := 0
+	for len(delta) > 0 {
+		same, n := binary.Uvarint(delta)
+		delta = delta[n:]
+		diff, n := binary.Uvarint(delta)
+		delta = delta[n:]
+		pos += int(same)
+		for j := 0; j < int(diff); j++ {
+			dst[pos+j] ^= delta[j]
+		}
+		delta = delta[diff:]
+		pos += int(diff)
+	}
+}
+
+// Reset drops all the snapshots. It should be called when a game is loaded or
+// unloaded.
+func Reset() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	buf.reset()
+	frame = 0
+}
+
+// Push captures the state of the core every RewindGranularity frames. It
+// should be called once per frame, after running the core.
+func Push() {
+	if !settings.Current.RewindEnabled || !state.CoreRunning {
+		return
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	frame++
+	if settings.Current.RewindGranularity > 1 && frame%settings.Current.RewindGranularity != 0 {
+		return
+	}
+
+	s := state.Core.SerializeSize()
+	if s == 0 {
+		return
+	}
+	bytes, err := state.Core.Serialize(s)
+	if err != nil {
+		log.Println("[Rewind]:", err)
+		return
+	}
+
+	buf.budget = settings.Current.RewindBufferSize * 1024 * 1024
+	buf.push(bytes)
+}
+
+// Pop restores the previous snapshot in the core. It returns false if the
+// buffer is empty or if the state couldn't be restored.
+func Pop() bool {
+	if !settings.Current.RewindEnabled || !state.CoreRunning {
+		return false
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	bytes := buf.pop()
+	if bytes == nil {
+		return false
+	}
+
+	err := state.Core.Unserialize(bytes, uint(len(bytes)))
+	if err != nil {
+		log.Println("[Rewind]:", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+package rewind
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_encode(t *testing.T) {
+	t.Run("Identical states give a tiny delta", func(t *testing.T) {
+		a := make([]byte, 4096)
+		b := make([]byte, 4096)
+		got := encode(a, b)
+		if len(got) > 4 {
+			t.Errorf("len(encode()) = %v, want <= %v", len(got), 4)
+		}
+	})
+
+	t.Run("Decoding a delta restores the state", func(t *testing.T) {
+		a := []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+		b := []byte{0, 1, 9, 9, 4, 5, 6, 7, 8, 0}
+		delta := encode(a, b)
+		decode(delta, b)
+		if !bytes.Equal(a, b) {
+			t.Errorf("got = %v, want %v", b, a)
+		}
+	})
+}
+
+func Test_buffer(t *testing.T) {
+	snapshots := [][]byte{
+		{1, 1, 1, 1, 1, 1, 1, 1},
+		{1, 2, 1, 1, 1, 1, 1, 1},
+		{1, 2, 3, 1, 1, 1, 1, 1},
+		{1, 2, 3, 4, 1, 1, 1, 1},
+	}
+
+	t.Run("Pops the snapshots in reverse order", func(t *testing.T) {
+		b := buffer{budget: 1024}
+		for _, s := range snapshots {
+			b.push(append([]byte{}, s...))
+		}
+		for i := len(snapshots) - 2; i >= 0; i-- {
+			got := b.pop()
+			if !bytes.Equal(got, snapshots[i]) {
+				t.Errorf("got = %v, want %v", got, snapshots[i])
+			}
+		}
+		if got := b.pop(); got != nil {
+			t.Errorf("got = %v, want %v", got, nil)
+		}
+	})
+
+	t.Run("Drops the oldest snapshots when over budget", func(t *testing.T) {
+		b := buffer{budget: 10}
+		for _, s := range snapshots {
+			b.push(append([]byte{}, s...))
+		}
+		if b.used > b.budget {
+			t.Errorf("used = %v, want <= %v", b.used, b.budget)
+		}
+		if len(b.deltas) != 2 {
+			t.Errorf("len(deltas) = %v, want %v", len(b.deltas), 2)
+		}
+		got := b.pop()
+		if !bytes.Equal(got, snapshots[2]) {
+			t.Errorf("got = %v, want %v", got, snapshots[2])
+		}
+	})
+
+	t.Run("Resets when the state size changes", func(t *testing.T) {
+		b := buffer{budget: 1024}
+		b.push([]byte{1, 2, 3})
+		b.push([]byte{1, 2, 4})
+		b.push([]byte{1, 2, 3, 4})
+		if got := b.pop(); got != nil {
+			t.Errorf("got = %v, want %v", got, nil)
+		}
+	})
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package rumble
+
+// none is used on the platforms where we can't drive the motors yet
+type none struct{}
+
+var defaultBackend Backend = none{}
+
+func (none) Open() ([]Motor, error) {
+	return nil, nil
+}
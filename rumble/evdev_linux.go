@@ -0,0 +1,142 @@
+//go:build linux
+// +build linux
+
+package rumble
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"unsafe"
+)
+
+// Linux input constants, see linux/input.h and linux/input-event-codes.h
+const (
+	evFF     = 0x15
+	ffRumble = 0x50
+	ffMax    = 0x7f
+
+	iocWrite = 1
+	iocRead  = 2
+)
+
+// ffEffect mirrors struct ff_effect for a rumble effect. The union is as large
+// as its biggest member, which holds a pointer.
+type ffEffect struct {
+	effectType uint16
+	id         int16
+	direction  uint16
+	trigger    [2]uint16
+	replay     [2]uint16 // length and delay in ms
+	_          [2]byte
+	strong     uint16
+	weak       uint16
+	_          [28 - 4 + 4*(^uintptr(0)>>63)]byte
+}
+
+// inputEvent mirrors struct input_event
+type inputEvent struct {
+	time  syscall.Timeval
+	typ   uint16
+	code  uint16
+	value int32
+}
+
+func ioc(dir, nr, size uintptr) uintptr {
+	return dir<<30 | size<<16 | 'E'<<8 | nr
+}
+
+func ioctl(fd uintptr, req uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// evdevMotor is a gamepad driven through its /dev/input/event* node
+type evdevMotor struct {
+	file   *os.File
+	name   string
+	effect ffEffect
+}
+
+func (m *evdevMotor) Name() string {
+	return m.name
+}
+
+// Rumble uploads a rumble effect with the new magnitudes and plays it. The
+// replay length is zero, so the effect lasts until the next call.
+func (m *evdevMotor) Rumble(strong, weak uint16) error {
+	if strong == 0 && weak == 0 {
+		if m.effect.id < 0 {
+			return nil
+		}
+		return m.play(0)
+	}
+	m.effect.strong = strong
+	m.effect.weak = weak
+	if err := ioctl(m.file.Fd(), ioc(iocWrite, 0x80, unsafe.Sizeof(m.effect)), unsafe.Pointer(&m.effect)); err != nil {
+		return err
+	}
+	return m.play(1)
+}
+
+// play starts or stops the uploaded effect
+func (m *evdevMotor) play(value int32) error {
+	ev := inputEvent{typ: evFF, code: uint16(m.effect.id), value: value}
+	_, err := m.file.Write((*[unsafe.Sizeof(ev)]byte)(unsafe.Pointer(&ev))[:])
+	return err
+}
+
+func (m *evdevMotor) Close() error {
+	return m.file.Close()
+}
+
+// evdev finds the gamepads supporting FF_RUMBLE in /dev/input
+type evdev struct{}
+
+var defaultBackend Backend = evdev{}
+
+func (evdev) Open() ([]Motor, error) {
+	paths, err := filepath.Glob("/dev/input/event*")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var motors []Motor
+	for _, path := range paths {
+		if m := openEvdev(path); m != nil {
+			motors = append(motors, m)
+		}
+	}
+	return motors, nil
+}
+
+// openEvdev opens an event node if it can rumble
+func openEvdev(path string) *evdevMotor {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil
+	}
+
+	var bits [ffMax/8 + 1]byte
+	err = ioctl(f.Fd(), ioc(iocRead, 0x20+evFF, unsafe.Sizeof(bits)), unsafe.Pointer(&bits))
+	if err != nil || bits[ffRumble/8]&(1<<(ffRumble%8)) == 0 {
+		f.Close()
+		return nil
+	}
+
+	var name [256]byte
+	if err := ioctl(f.Fd(), ioc(iocRead, 0x06, unsafe.Sizeof(name)), unsafe.Pointer(&name)); err != nil {
+		f.Close()
+		return nil
+	}
+
+	m := &evdevMotor{file: f, name: string(bytes.TrimRight(name[:], "\x00"))}
+	m.effect.effectType = ffRumble
+	m.effect.id = -1
+	return m
+}
@@ -0,0 +1,142 @@
+// Package rumble forwards the force feedback requested by the cores to the
+// motors of the physical gamepads.
+package rumble
+
+import (
+	"log"
+	"sync"
+
+	"github.com/libretro/ludo/input"
+	"github.com/libretro/ludo/libretro"
+	"github.com/libretro/ludo/settings"
+)
+
+// Motor drives the force feedback of a physical gamepad
+type Motor interface {
+	// Name is the name of the gamepad, as reported by the input package
+	Name() string
+	// Rumble sets the magnitude of the strong and weak motors, zero stops them
+	Rumble(strong, weak uint16) error
+	Close() error
+}
+
+// Backend finds the gamepads able to rumble
+type Backend interface {
+	Open() ([]Motor, error)
+}
+
+var (
+	backend = defaultBackend
+	motors  [input.MaxPlayers]Motor
+	states  [input.MaxPlayers][2]uint16 // strong and weak magnitudes per port
+	mutex   sync.Mutex
+)
+
+// SetBackend replaces the backend used to find the gamepads
+func SetBackend(b Backend) {
+	backend = b
+}
+
+// Open assigns a gamepad able to rumble to each port, matching the names of
+// the gamepads the input package reads
+func Open(names []string) error {
+	Close()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	available, err := backend.Open()
+	if err != nil {
+		return err
+	}
+
+	used := make([]bool, len(available))
+	for port, name := range names {
+		if port >= input.MaxPlayers {
+			break
+		}
+		for i, m := range available {
+			if !used[i] && m.Name() == name {
+				motors[port] = m
+				used[i] = true
+				break
+			}
+		}
+	}
+
+	// Close the gamepads we don't drive
+	for i, m := range available {
+		if !used[i] {
+			m.Close()
+		}
+	}
+	return nil
+}
+
+// Close stops the motors and releases the gamepads
+func Close() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for port, m := range motors {
+		if m == nil {
+			continue
+		}
+		m.Rumble(0, 0)
+		m.Close()
+		motors[port] = nil
+	}
+	states = [input.MaxPlayers][2]uint16{}
+}
+
+// strength returns the strength setting of a player, between 0 and 1
+func strength(port uint) float32 {
+	if int(port) >= len(settings.Current.RumbleStrength) {
+		return 1
+	}
+	return settings.Current.RumbleStrength[port]
+}
+
+// apply sends the state of a port to its motor
+func apply(port uint) {
+	m := motors[port]
+	if m == nil {
+		return
+	}
+	var strong, weak uint16
+	if settings.Current.RumbleEnabled {
+		s := strength(port)
+		strong = uint16(float32(states[port][0]) * s)
+		weak = uint16(float32(states[port][1]) * s)
+	}
+	if err := m.Rumble(strong, weak); err != nil {
+		log.Println("[Rumble]:", err)
+	}
+}
+
+// SetState is the set_rumble_state callback of the rumble interface. It
+// returns false when no gamepad can rumble on this port.
+func SetState(port uint, effect uint32, magnitude uint16) bool {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if port >= input.MaxPlayers || effect > libretro.RumbleWeak || motors[port] == nil {
+		return false
+	}
+	if states[port][effect] == magnitude {
+		return true
+	}
+	states[port][effect] = magnitude
+	apply(port)
+	return true
+}
+
+// Refresh applies the rumble settings to the motors currently running
+func Refresh() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	for port := range motors {
+		apply(uint(port))
+	}
+}
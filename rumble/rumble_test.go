@@ -0,0 +1,82 @@
+package rumble
+
+import (
+	"testing"
+
+	"github.com/libretro/ludo/libretro"
+	"github.com/libretro/ludo/settings"
+)
+
+type fakeMotor struct {
+	name   string
+	strong uint16
+	weak   uint16
+	closed bool
+}
+
+func (m *fakeMotor) Name() string { return m.name }
+func (m *fakeMotor) Close() error { m.closed = true; return nil }
+func (m *fakeMotor) Rumble(strong, weak uint16) error {
+	m.strong, m.weak = strong, weak
+	return nil
+}
+
+type fakeBackend []*fakeMotor
+
+func (b fakeBackend) Open() ([]Motor, error) {
+	var motors []Motor
+	for _, m := range b {
+		motors = append(motors, m)
+	}
+	return motors, nil
+}
+
+func Test_SetState(t *testing.T) {
+	pad := &fakeMotor{name: "Xbox Controller"}
+	other := &fakeMotor{name: "Keyboard"}
+	SetBackend(fakeBackend{other, pad})
+	defer SetBackend(defaultBackend)
+
+	settings.Current.RumbleEnabled = true
+	settings.Current.RumbleStrength = []float32{0.5}
+
+	if err := Open([]string{"Xbox Controller"}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Gamepads are matched by name and the others are released", func(t *testing.T) {
+		if !other.closed || pad.closed {
+			t.Errorf("got = %v %v, want %v %v", other.closed, pad.closed, true, false)
+		}
+	})
+
+	t.Run("Magnitudes are scaled by the strength of the player", func(t *testing.T) {
+		if !SetState(0, libretro.RumbleStrong, 0xffff) {
+			t.Errorf("got = %v, want %v", false, true)
+		}
+		SetState(0, libretro.RumbleWeak, 0x8000)
+		if pad.strong != 0x7fff || pad.weak != 0x4000 {
+			t.Errorf("got = %x %x, want %x %x", pad.strong, pad.weak, 0x7fff, 0x4000)
+		}
+	})
+
+	t.Run("Ports without a gamepad can't rumble", func(t *testing.T) {
+		if SetState(1, libretro.RumbleStrong, 0xffff) {
+			t.Errorf("got = %v, want %v", true, false)
+		}
+	})
+
+	t.Run("Disabling rumble stops the motors", func(t *testing.T) {
+		settings.Current.RumbleEnabled = false
+		Refresh()
+		if pad.strong != 0 || pad.weak != 0 {
+			t.Errorf("got = %x %x, want 0 0", pad.strong, pad.weak)
+		}
+	})
+
+	Close()
+	if !pad.closed {
+		t.Errorf("got = %v, want %v", pad.closed, true)
+	}
+	settings.Current = settings.Defaults
+}
@@ -0,0 +1,146 @@
+package savefiles
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/libretro/ludo/libretro"
+	"github.com/libretro/ludo/settings"
+	"github.com/libretro/ludo/state"
+	"github.com/libretro/ludo/utils"
+
+	"github.com/libretro/ludo/l10n"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+)
+
+// backupInterval is the minimum time between two backups of a save file, as
+// the SRAM is saved every few seconds
+const backupInterval = 10 * time.Minute
+
+// backupDateFormat is the date in the name of the backups, like in the
+// screenshots and savestates names
+const backupDateFormat = "2006-01-02-15-04-05"
+
+// Backup is a copy of a save file taken before overwriting it
+type Backup struct {
+	Path string
+	Date time.Time
+}
+
+// backupDir returns the directory of the save file backups
+func backupDir() string {
+	return filepath.Join(settings.Current.SavefilesDirectory, "backups")
+}
+
+// backupName splits the name of a save file around the place of the date in
+// the names of its backups
+func backupName(path string) (prefix, suffix string) {
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "@", ext
+}
+
+// backups lists the backups of a save file, newest first
+func backups(path string) []Backup {
+	files, err := ioutil.ReadDir(backupDir())
+	if err != nil {
+		return nil
+	}
+	prefix, suffix := backupName(path)
+	var list []Backup
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, suffix) {
+			continue
+		}
+		date, err := time.ParseInLocation(backupDateFormat,
+			strings.TrimSuffix(strings.TrimPrefix(name, prefix), suffix), time.Local)
+		if err != nil {
+			continue
+		}
+		list = append(list, Backup{filepath.Join(backupDir(), name), date})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Date.After(list[j].Date)
+	})
+	return list
+}
+
+// backup copies a save file to the backups directory before it gets
+// overwritten, unless the last backup is recent. The oldest backups are
+// removed to keep settings.Current.SaveRAMBackups of them.
+func backup(path string, force bool) error {
+	if settings.Current.SaveRAMBackups <= 0 {
+		return nil
+	}
+
+	bytes, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	list := backups(path)
+	now := time.Now()
+	if !force && len(list) > 0 && now.Sub(list[0].Date) < backupInterval {
+		return nil
+	}
+
+	if err := os.MkdirAll(backupDir(), os.ModePerm); err != nil {
+		return err
+	}
+	prefix, suffix := backupName(path)
+	dest := filepath.Join(backupDir(), prefix+now.Format(backupDateFormat)+suffix)
+	if err := utils.WriteFileAtomic(dest, bytes, 0644); err != nil {
+		return err
+	}
+
+	list = backups(path)
+	for i := settings.Current.SaveRAMBackups; i < len(list); i++ {
+		os.Remove(list[i].Path)
+	}
+	return nil
+}
+
+// Backups lists the backups of the SRAM of the current game, newest first
+func Backups() []Backup {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return backups(path())
+}
+
+// Restore replaces the SRAM of the current game with a backup. The current
+// SRAM is backed up first, so restoring can be undone. Most games only read
+// their SRAM when booting, so the core should be reset after this.
+func Restore(b Backup) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if !state.CoreRunning {
+		txtI18n := l10n.T9(&i18n.Message{ID: "CoreNotRunning", Other: "core not running"})
+		return errors.New(txtI18n)
+	}
+
+	bytes, err := ioutil.ReadFile(b.Path)
+	if err != nil {
+		return err
+	}
+
+	if err := backup(path(), true); err != nil {
+		return err
+	}
+	if err := utils.WriteFileAtomic(path(), bytes, 0644); err != nil {
+		return err
+	}
+	delete(hashes, path())
+
+	return loadMemory(libretro.MemorySaveRAM, path())
+}
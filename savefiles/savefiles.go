@@ -3,6 +3,7 @@ package savefiles
 
 import (
 	"C"
+	"crypto/sha1"
 	"errors"
 	"io/ioutil"
 	"os"
@@ -21,6 +22,38 @@ import (
 
 var mutex sync.Mutex
 
+// hashes of the memories as last read or written, indexed by file path, to
+// skip writing unchanged memories
+var hashes = map[string][sha1.Size]byte{}
+
+// subsystemROM is a ROM loaded through a subsystem, along with the persistent
+// memories the core exposes for it
+type subsystemROM struct {
+	path   string
+	memory []libretro.SubsystemMemoryInfo
+}
+
+// subsystem holds the ROMs of the game when it has been loaded through a
+// subsystem, nil otherwise
+var subsystem []subsystemROM
+
+// SetSubsystem tells where to save the memories of a game loaded through a
+// subsystem. Each ROM gets one file per memory descriptor, named after the
+// ROM and the extension of the descriptor. Skipped ROMs have an empty path.
+// Passing nil roms restores the regular .srm file.
+func SetSubsystem(roms []libretro.SubsystemROMInfo, paths []string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	subsystem = nil
+	for i, rom := range roms {
+		if i >= len(paths) || paths[i] == "" {
+			continue
+		}
+		subsystem = append(subsystem, subsystemROM{paths[i], rom.Memory})
+	}
+}
+
 // path returns the path of the SRAM file for the current core
 func path() string {
 	return filepath.Join(
@@ -28,6 +61,26 @@ func path() string {
 		utils.FileName(state.GamePath)+".srm")
 }
 
+// rtcPath returns the path of the real time clock file for the current core
+func rtcPath() string {
+	return filepath.Join(
+		settings.Current.SavefilesDirectory,
+		utils.FileName(state.GamePath)+".rtc")
+}
+
+// hasRTC tells if the core exposes a real time clock to persist
+func hasRTC() bool {
+	return state.Core.GetMemorySize(libretro.MemoryRTC) > 0 &&
+		state.Core.GetMemoryData(libretro.MemoryRTC) != nil
+}
+
+// subsystemPath returns the path of a memory file for a subsystem ROM
+func subsystemPath(romPath string, mem libretro.SubsystemMemoryInfo) string {
+	return filepath.Join(
+		settings.Current.SavefilesDirectory,
+		utils.FileName(romPath)+"."+mem.Extension)
+}
+
 // SaveSRAM saves the game SRAM to the filesystem
 func SaveSRAM() error {
 	mutex.Lock()
@@ -38,8 +91,32 @@ func SaveSRAM() error {
 		return errors.New(txtI18n)
 	}
 
-	len := state.Core.GetMemorySize(libretro.MemorySaveRAM)
-	ptr := state.Core.GetMemoryData(libretro.MemorySaveRAM)
+	if subsystem != nil {
+		var err error
+		for _, rom := range subsystem {
+			for _, mem := range rom.memory {
+				if e := saveMemory(mem.Type, subsystemPath(rom.path, mem)); e != nil {
+					err = e
+				}
+			}
+		}
+		return err
+	}
+
+	err := saveMemory(libretro.MemorySaveRAM, path())
+	if hasRTC() {
+		if e := saveMemory(libretro.MemoryRTC, rtcPath()); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// saveMemory writes a memory region of the core to a file, if it changed
+// since it was last read or written. The previous save RAM is backed up.
+func saveMemory(id uint32, path string) error {
+	len := state.Core.GetMemorySize(id)
+	ptr := state.Core.GetMemoryData(id)
 	if ptr == nil || len == 0 {
 		txtI18n := l10n.T9(&i18n.Message{ID: "Unable2GetSRAMAddress", Other: "unable to get SRAM address"})
 		return errors.New(txtI18n)
@@ -47,28 +124,27 @@ func SaveSRAM() error {
 
 	// convert the C array to a go slice
 	bytes := C.GoBytes(ptr, C.int(len))
-	err := os.MkdirAll(settings.Current.SavefilesDirectory, os.ModePerm)
-	if err != nil {
-		return err
+	sum := sha1.Sum(bytes)
+	if h, ok := hashes[path]; ok && h == sum {
+		return nil
 	}
 
-	fd, err := os.Create(path())
+	err := os.MkdirAll(settings.Current.SavefilesDirectory, os.ModePerm)
 	if err != nil {
 		return err
 	}
 
-	_, err = fd.Write(bytes)
-	if err != nil {
-		fd.Close()
-		return err
+	if id != libretro.MemoryRTC {
+		if err := backup(path, false); err != nil {
+			return err
+		}
 	}
 
-	err = fd.Close()
-	if err != nil {
+	if err := utils.WriteFileAtomic(path, bytes, 0644); err != nil {
 		return err
 	}
-
-	return fd.Sync()
+	hashes[path] = sum
+	return nil
 }
 
 // LoadSRAM load the game SRAM from the filesystem
@@ -81,8 +157,31 @@ func LoadSRAM() error {
 		return errors.New(txtI18n)
 	}
 
-	len := state.Core.GetMemorySize(libretro.MemorySaveRAM)
-	ptr := state.Core.GetMemoryData(libretro.MemorySaveRAM)
+	if subsystem != nil {
+		var err error
+		for _, rom := range subsystem {
+			for _, mem := range rom.memory {
+				if e := loadMemory(mem.Type, subsystemPath(rom.path, mem)); e != nil {
+					err = e
+				}
+			}
+		}
+		return err
+	}
+
+	err := loadMemory(libretro.MemorySaveRAM, path())
+	if hasRTC() {
+		if e := loadMemory(libretro.MemoryRTC, rtcPath()); e != nil && !os.IsNotExist(e) {
+			err = e
+		}
+	}
+	return err
+}
+
+// loadMemory overwrites a memory region of the core with the content of a file
+func loadMemory(id uint32, path string) error {
+	len := state.Core.GetMemorySize(id)
+	ptr := state.Core.GetMemoryData(id)
 	if ptr == nil || len == 0 {
 		txtI18n := l10n.T9(&i18n.Message{ID: "Unable2GetSRAMAddress", Other: "unable to get SRAM address"})
 		return errors.New(txtI18n)
@@ -91,11 +190,12 @@ func LoadSRAM() error {
 	// this *[1 << 30]byte points to the same memory as ptr, allowing to
 	// overwrite this memory
 	destination := (*[1 << 30]byte)(unsafe.Pointer(ptr))[:len:len]
-	source, err := ioutil.ReadFile(path())
+	source, err := ioutil.ReadFile(path)
 	if err != nil {
 		return err
 	}
 	copy(destination, source)
+	hashes[path] = sha1.Sum(destination)
 
 	return nil
 }
@@ -0,0 +1,66 @@
+package savefiles
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/libretro/ludo/settings"
+)
+
+func Test_backup(t *testing.T) {
+	saved := settings.Current
+	defer func() { settings.Current = saved }()
+	settings.Current.SavefilesDirectory = t.TempDir()
+	settings.Current.SaveRAMBackups = 2
+
+	path := filepath.Join(settings.Current.SavefilesDirectory, "Pokemon Gold [!].srm")
+	if err := ioutil.WriteFile(path, []byte("save"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(backupDir(), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{
+		"Pokemon Gold [!]@2020-01-01-00-00-00.srm",
+		"Pokemon Gold [!]@2021-01-01-00-00-00.srm",
+		"Pokemon Silver@2022-01-01-00-00-00.srm",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(backupDir(), name), []byte("save"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("Lists the backups of a save, newest first", func(t *testing.T) {
+		list := backups(path)
+		if len(list) != 2 || list[0].Date.Year() != 2021 {
+			t.Errorf("got = %v, want the 2021 and 2020 backups", list)
+		}
+	})
+
+	t.Run("Backs up and removes the oldest backups", func(t *testing.T) {
+		if err := backup(path, false); err != nil {
+			t.Fatal(err)
+		}
+		list := backups(path)
+		if len(list) != 2 {
+			t.Fatalf("got = %v, want %v", len(list), 2)
+		}
+		if time.Since(list[0].Date) > time.Minute || list[1].Date.Year() != 2021 {
+			t.Errorf("got = %v, want a new backup and the 2021 one", list)
+		}
+	})
+
+	t.Run("Skips the backup when the last one is recent", func(t *testing.T) {
+		before := backups(path)
+		if err := backup(path, false); err != nil {
+			t.Fatal(err)
+		}
+		after := backups(path)
+		if len(after) != len(before) || after[0] != before[0] || after[1] != before[1] {
+			t.Errorf("got = %v, want %v", after, before)
+		}
+	})
+}
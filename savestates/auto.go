@@ -0,0 +1,38 @@
+package savestates
+
+import (
+	"path/filepath"
+
+	"github.com/libretro/ludo/settings"
+	"github.com/libretro/ludo/state"
+	"github.com/libretro/ludo/utils"
+)
+
+// Resume modes of the auto savestate, as set in the settings
+const (
+	ResumeNever  = "Never"
+	ResumeAsk    = "Ask"
+	ResumeAlways = "Always"
+)
+
+// Resumes lists the resume modes, in the order of the settings
+var Resumes = []string{ResumeNever, ResumeAsk, ResumeAlways}
+
+// AutoName returns the name of the auto savestate of a game, without
+// extension. It sits next to the dated savestates of the game.
+func AutoName(gamePath string) string {
+	return utils.FileName(gamePath) + "@auto"
+}
+
+// AutoPath returns the path of the auto savestate of a game
+func AutoPath(gamePath string) string {
+	return filepath.Join(settings.Current.SavestatesDirectory, AutoName(gamePath)+".state")
+}
+
+// SaveAuto saves the auto savestate of the running game, and returns its path
+func SaveAuto() (string, error) {
+	if err := Save(AutoName(state.GamePath)); err != nil {
+		return "", err
+	}
+	return AutoPath(state.GamePath), nil
+}
@@ -0,0 +1,167 @@
+package savestates
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/libretro/ludo/settings"
+	"github.com/libretro/ludo/utils"
+)
+
+// Compression methods of the savestates, as set in the settings
+const (
+	CompressionNone = "None"
+	CompressionGzip = "Gzip"
+	CompressionZstd = "Zstd"
+)
+
+// Compressions lists the compression methods, in the order of the settings
+var Compressions = []string{CompressionNone, CompressionGzip, CompressionZstd}
+
+// maxSizeFactor bounds the size of a decompressed state, as a multiple of the
+// size the core expects, so a corrupted or hostile file can't exhaust memory
+const maxSizeFactor = 4
+
+// Magic numbers of the compressed formats, used to tell them from raw states
+// that have no sidecar
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// compress compresses a state with one of the compression methods
+func compress(data []byte, method string) ([]byte, error) {
+	switch method {
+	case CompressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case CompressionZstd:
+		enc, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer enc.Close()
+		return enc.EncodeAll(data, nil), nil
+	}
+	return data, nil
+}
+
+// detectCompression guesses the compression method of a state from its magic
+// number
+func detectCompression(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, gzipMagic):
+		return CompressionGzip
+	case bytes.HasPrefix(data, zstdMagic):
+		return CompressionZstd
+	}
+	return CompressionNone
+}
+
+// maxStateSize returns the biggest decompressed state accepted for a core
+// expecting states of the given size
+func maxStateSize(serializeSize uint) int64 {
+	return int64(serializeSize) * maxSizeFactor
+}
+
+// decompress returns the raw state of a state compressed with a method. Raw
+// states are returned as is. States decompressing to more than limit bytes
+// are rejected.
+func decompress(data []byte, method string, limit int64) ([]byte, error) {
+	if method != CompressionGzip && method != CompressionZstd {
+		return data, nil
+	}
+	if limit <= 0 {
+		return nil, errSize()
+	}
+	switch method {
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		raw, err := ioutil.ReadAll(io.LimitReader(r, limit+1))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(raw)) > limit {
+			return nil, errSize()
+		}
+		return raw, nil
+	default:
+		dec, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(uint64(limit)))
+		if err != nil {
+			return nil, err
+		}
+		defer dec.Close()
+		raw, err := dec.DecodeAll(data, nil)
+		if err == zstd.ErrDecoderSizeExceeded {
+			return nil, errSize()
+		}
+		return raw, err
+	}
+}
+
+// CompressAll compresses the raw savestates of the savestates directory, with
+// the compression method of the settings, or zstd if compression is off. The
+// savestates that wouldn't get smaller are left raw. It returns the number of
+// compressed savestates and the number of bytes saved.
+func CompressAll() (int, int64, error) {
+	method := settings.Current.SavestateCompression
+	if method != CompressionGzip && method != CompressionZstd {
+		method = CompressionZstd
+	}
+
+	paths, err := filepath.Glob(filepath.Join(settings.Current.SavestatesDirectory, "*.state"))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	count := 0
+	var saved int64
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return count, saved, err
+		}
+		current := detectCompression(data)
+		m, merr := ReadMetadata(path)
+		if merr == nil {
+			current = m.Compression
+		}
+		if current == CompressionGzip || current == CompressionZstd {
+			continue
+		}
+		compressed, err := compress(data, method)
+		if err != nil {
+			return count, saved, err
+		}
+		if len(compressed) >= len(data) {
+			continue
+		}
+		if err := utils.WriteFileAtomic(path, compressed, 0644); err != nil {
+			return count, saved, err
+		}
+		if merr == nil {
+			m.Compression = method
+			if err := writeMetadata(path, m); err != nil {
+				return count, saved, err
+			}
+		}
+		count++
+		saved += int64(len(data) - len(compressed))
+	}
+	return count, saved, nil
+}
@@ -3,16 +3,152 @@
 package savestates
 
 import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/libretro/ludo/libretro"
 	"github.com/libretro/ludo/settings"
 	"github.com/libretro/ludo/state"
+	"github.com/libretro/ludo/utils"
+	"github.com/pelletier/go-toml"
+
+	"github.com/libretro/ludo/l10n"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
 )
 
-// Save the current state to the filesystem. name is the name of the
-// savestate file to save to, without extension.
+// Metadata describes the context a savestate was made in. It is stored in a
+// sidecar file, so the savestates stay raw dumps readable by other frontends.
+type Metadata struct {
+	CoreName      string    `toml:"core_name"`
+	CoreVersion   string    `toml:"core_version"`
+	ContentCRC    string    `toml:"content_crc"`
+	SerializeSize int64     `toml:"serialize_size"`
+	Date          time.Time `toml:"date"`
+	PlayTime      int64     `toml:"play_time"` // in seconds
+	Thumbnail     string    `toml:"thumbnail"`
+	Compression   string    `toml:"compression"` // empty for raw states
+}
+
+// contentCRC caches the checksum of the current game, as games can be big
+var contentCRC struct {
+	path string
+	crc  string
+}
+
+// metadataPath returns the path of the sidecar file of a savestate
+func metadataPath(path string) string {
+	return path + ".toml"
+}
+
+// gameCRC returns the CRC32 of the current game file, empty if there is none
+func gameCRC() string {
+	if state.GamePath == "" {
+		return ""
+	}
+	if contentCRC.path == state.GamePath {
+		return contentCRC.crc
+	}
+	f, err := os.Open(state.GamePath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	h := crc32.NewIEEE()
+	if _, err := io.Copy(h, f); err != nil {
+		return ""
+	}
+	contentCRC.path = state.GamePath
+	contentCRC.crc = fmt.Sprintf("%08x", h.Sum32())
+	return contentCRC.crc
+}
+
+// newMetadata describes a savestate of the running game
+func newMetadata(name string, size int) Metadata {
+	si := state.Core.GetSystemInfo()
+	m := Metadata{
+		CoreName:      si.LibraryName,
+		CoreVersion:   si.LibraryVersion,
+		ContentCRC:    gameCRC(),
+		SerializeSize: int64(size),
+		Date:          time.Now(),
+		PlayTime:      int64(state.PlayTime / time.Second),
+	}
+	thumbnail := filepath.Join(settings.Current.ScreenshotsDirectory, name+".png")
+	if _, err := os.Stat(thumbnail); err == nil {
+		m.Thumbnail = thumbnail
+	}
+	return m
+}
+
+// ReadMetadata reads the sidecar file of a savestate. Savestates made before
+// sidecars existed return an os.ErrNotExist error.
+func ReadMetadata(path string) (Metadata, error) {
+	var m Metadata
+	b, err := ioutil.ReadFile(metadataPath(path))
+	if err != nil {
+		return m, err
+	}
+	err = toml.Unmarshal(b, &m)
+	return m, err
+}
+
+// writeMetadata writes the sidecar file of a savestate
+func writeMetadata(path string, m Metadata) error {
+	b, err := toml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return utils.WriteFileAtomic(metadataPath(path), b, 0644)
+}
+
+// errSize is returned for the savestates that don't have the size the core
+// expects
+func errSize() error {
+	txtI18n := l10n.T9(&i18n.Message{ID: "StateSizeMismatch", Other: "this savestate doesn't have the size the core expects"})
+	return errors.New(txtI18n)
+}
+
+// variableSize tells if the core said its states can have different sizes
+func variableSize() bool {
+	return state.Core.SerializationQuirks&libretro.SerializationQuirkCoreVariableSize != 0
+}
+
+// check tells if a savestate of a given size can be loaded safely by a core.
+// Unserializing a state made by another core or for another game can crash
+// the core, so does a state of a different size than the core expects, unless
+// the core has variable size states.
+func check(m Metadata, size int, si libretro.SystemInfo, serializeSize uint, variable bool) error {
+	if m.CoreName != "" && m.CoreName != si.LibraryName {
+		txtI18n := l10n.T9(&i18n.Message{ID: "StateOtherCore", Other: "this savestate was made by %s"})
+		return fmt.Errorf(txtI18n, m.CoreName)
+	}
+	if crc := gameCRC(); m.ContentCRC != "" && crc != "" && m.ContentCRC != crc {
+		txtI18n := l10n.T9(&i18n.Message{ID: "StateOtherGame", Other: "this savestate was made for another version of the game"})
+		return errors.New(txtI18n)
+	}
+	if m.SerializeSize != int64(size) {
+		txtI18n := l10n.T9(&i18n.Message{ID: "StateTruncated", Other: "this savestate is truncated"})
+		return errors.New(txtI18n)
+	}
+	if uint(size) != serializeSize && !variable {
+		if m.CoreVersion != si.LibraryVersion {
+			txtI18n := l10n.T9(&i18n.Message{ID: "StateOtherVersion", Other: "this savestate was made by %s %s and is incompatible with this version"})
+			return fmt.Errorf(txtI18n, m.CoreName, m.CoreVersion)
+		}
+		return errSize()
+	}
+	return nil
+}
+
+// Save the current state to the filesystem, compressed with the method of the
+// settings. name is the name of the savestate file to save to, without
+// extension.
 func Save(name string) error {
 	s := state.Core.SerializeSize()
 	bytes, err := state.Core.Serialize(s)
@@ -24,16 +160,70 @@ func Save(name string) error {
 	if err != nil {
 		return err
 	}
-	return ioutil.WriteFile(path, bytes, 0644)
+	method := settings.Current.SavestateCompression
+	if method != CompressionGzip && method != CompressionZstd {
+		method = CompressionNone
+	}
+	data, err := compress(bytes, method)
+	if err != nil {
+		return err
+	}
+	err = utils.WriteFileAtomic(path, data, 0644)
+	if err != nil {
+		return err
+	}
+	m := newMetadata(name, len(bytes))
+	m.Compression = method
+	return writeMetadata(path, m)
 }
 
-// Load the state from the filesystem
+// Load the state from the filesystem, decompressing it if needed. The metadata
+// of the savestate, when present, tells how the state is compressed and is
+// checked first.
 func Load(path string) error {
 	s := state.Core.SerializeSize()
-	bytes, err := ioutil.ReadFile(path)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	// Only the states without sidecar are sniffed, a raw state can start
+	// like a compressed one
+	method := detectCompression(data)
+	m, merr := ReadMetadata(path)
+	if merr == nil {
+		method = m.Compression
+	}
+	bytes, err := decompress(data, method, maxStateSize(s))
+	if err != nil {
+		return err
+	}
+	variable := variableSize()
+	if merr == nil {
+		if err := check(m, len(bytes), state.Core.GetSystemInfo(), s, variable); err != nil {
+			return err
+		}
+	}
+	// States with or without sidecar must have the size the core expects
+	if uint(len(bytes)) != s && !variable {
+		return errSize()
+	}
+	err = state.Core.Unserialize(bytes, uint(len(bytes)))
 	if err != nil {
 		return err
 	}
-	err = state.Core.Unserialize(bytes, s)
-	return err
+	if merr == nil {
+		state.PlayTime = time.Duration(m.PlayTime) * time.Second
+	}
+	return nil
+}
+
+// Delete removes a savestate and its sidecar file
+func Delete(path string) error {
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	if err := os.Remove(metadataPath(path)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,225 @@
+package savestates
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/libretro/ludo/libretro"
+	"github.com/libretro/ludo/settings"
+	"github.com/libretro/ludo/state"
+	"github.com/pelletier/go-toml"
+)
+
+func Test_check(t *testing.T) {
+	dir := t.TempDir()
+	game := filepath.Join(dir, "Super Metroid.sfc")
+	if err := ioutil.WriteFile(game, []byte("rom"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	state.GamePath = game
+	defer func() { state.GamePath = "" }()
+
+	si := libretro.SystemInfo{LibraryName: "Snes9x", LibraryVersion: "1.62"}
+	m := Metadata{
+		CoreName:      "Snes9x",
+		CoreVersion:   "1.61",
+		ContentCRC:    gameCRC(),
+		SerializeSize: 1024,
+	}
+
+	tests := []struct {
+		name     string
+		m        Metadata
+		size     int
+		coreNew  uint
+		variable bool
+		wantErr  bool
+	}{
+		{"Accepts a state of another version with the same size", m, 1024, 1024, false, false},
+		{"Rejects a state of another version with another size", m, 1024, 2048, false, true},
+		{"Rejects a state of the same version with another size", Metadata{CoreName: "Snes9x", CoreVersion: "1.62", SerializeSize: 1024}, 1024, 2048, false, true},
+		{"Accepts a state of another size from a variable size core", m, 1024, 2048, true, false},
+		{"Rejects a truncated state", m, 512, 1024, false, true},
+		{"Rejects a state made by another core", Metadata{CoreName: "bsnes", SerializeSize: 1024}, 1024, 1024, false, true},
+		{"Rejects a state made for another game", Metadata{CoreName: "Snes9x", ContentCRC: "deadbeef", SerializeSize: 1024}, 1024, 1024, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := check(tt.m, tt.size, si, tt.coreNew, tt.variable)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_ReadMetadata(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Super Metroid@2021-01-01-00-00-00.state")
+	if err := ioutil.WriteFile(path, []byte("state"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Savestates without sidecar have no metadata", func(t *testing.T) {
+		if _, err := ReadMetadata(path); !os.IsNotExist(err) {
+			t.Errorf("got = %v, want %v", err, os.ErrNotExist)
+		}
+	})
+
+	want := Metadata{
+		CoreName:      "Snes9x",
+		CoreVersion:   "1.62",
+		ContentCRC:    "0a1b2c3d",
+		SerializeSize: 5,
+		Date:          time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		PlayTime:      3723,
+	}
+	b, err := toml.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(metadataPath(path), b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Reads the sidecar", func(t *testing.T) {
+		got, err := ReadMetadata(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("got = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("Delete removes the sidecar", func(t *testing.T) {
+		if err := Delete(path); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := os.Stat(metadataPath(path)); !os.IsNotExist(err) {
+			t.Errorf("got = %v, want %v", err, os.ErrNotExist)
+		}
+	})
+}
+
+func Test_compress(t *testing.T) {
+	raw := bytes.Repeat([]byte("PlayStation RAM "), 4096)
+
+	for _, method := range Compressions {
+		t.Run(method, func(t *testing.T) {
+			data, err := compress(raw, method)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if method != CompressionNone && len(data) >= len(raw) {
+				t.Errorf("got = %v bytes, want less than %v", len(data), len(raw))
+			}
+			got, err := decompress(data, method, int64(len(raw)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, raw) {
+				t.Errorf("decompress(compress(raw)) != raw")
+			}
+			if method == CompressionNone {
+				return
+			}
+			if _, err := decompress(data, method, int64(len(raw))-1); err == nil {
+				t.Errorf("got = %v, want an error past the limit", err)
+			}
+		})
+	}
+
+	t.Run("Raw states looking compressed are returned as is", func(t *testing.T) {
+		raw := append([]byte{0x1f, 0x8b}, bytes.Repeat([]byte{0}, 64)...)
+		got, err := decompress(raw, CompressionNone, int64(len(raw)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, raw) {
+			t.Errorf("decompress(raw) != raw")
+		}
+	})
+}
+
+func Test_CompressAll(t *testing.T) {
+	saved := settings.Current
+	defer func() { settings.Current = saved }()
+	settings.Current.SavestatesDirectory = t.TempDir()
+	settings.Current.SavestateCompression = CompressionNone
+
+	dir := settings.Current.SavestatesDirectory
+	raw := bytes.Repeat([]byte("Saturn RAM "), 4096)
+	gzipMagicRaw := append([]byte{0x1f, 0x8b}, raw...)
+	zstd, _ := compress(raw, CompressionZstd)
+	files := map[string][]byte{
+		"Panzer Dragoon@2021-01-01-00-00-00.state": raw,
+		"Panzer Dragoon@2021-01-02-00-00-00.state": zstd,
+		"Panzer Dragoon@2021-01-03-00-00-00.state": gzipMagicRaw,
+		"Panzer Dragoon@2021-01-04-00-00-00.state": []byte("tiny"),
+	}
+	for name, data := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The sidecar tells this state is raw, even if it starts like gzip
+	magicPath := filepath.Join(dir, "Panzer Dragoon@2021-01-03-00-00-00.state")
+	if err := writeMetadata(magicPath, Metadata{SerializeSize: int64(len(gzipMagicRaw))}); err != nil {
+		t.Fatal(err)
+	}
+
+	count, gain, err := CompressAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("got = %v, want %v", count, 2)
+	}
+	if gain <= 0 {
+		t.Errorf("got = %v bytes saved, want more than 0", gain)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		want   []byte
+	}{
+		{"Panzer Dragoon@2021-01-01-00-00-00.state", CompressionZstd, raw},
+		{"Panzer Dragoon@2021-01-02-00-00-00.state", CompressionZstd, raw},
+		{"Panzer Dragoon@2021-01-03-00-00-00.state", CompressionZstd, gzipMagicRaw},
+		{"Panzer Dragoon@2021-01-04-00-00-00.state", CompressionNone, []byte("tiny")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			data, _ := ioutil.ReadFile(path)
+			method := detectCompression(data)
+			if m, err := ReadMetadata(path); err == nil {
+				method = m.Compression
+			}
+			if method != tt.method {
+				t.Errorf("got = %v, want %v", method, tt.method)
+			}
+			if got, _ := decompress(data, method, int64(len(tt.want))); !bytes.Equal(got, tt.want) {
+				t.Errorf("%s doesn't decompress to the raw state", tt.name)
+			}
+		})
+	}
+}
+
+func Test_AutoPath(t *testing.T) {
+	saved := settings.Current
+	defer func() { settings.Current = saved }()
+	settings.Current.SavestatesDirectory = "/states"
+
+	got := AutoPath("/roms/Super Metroid (USA).sfc")
+	want := filepath.Join("/states", "Super Metroid (USA)@auto.state")
+	if got != want {
+		t.Errorf("got = %v, want %v", got, want)
+	}
+}
@@ -10,13 +10,24 @@ import (
 func defaultSettings() Settings {
 	usr, _ := user.Current()
 	return Settings{
-		VideoFullscreen:   false,
-		VideoMonitorIndex: 0,
-		VideoFilter:       "Pixel Perfect",
-		MapAxisToDPad:     false,
-		AudioVolume:       0.5,
-		MenuAudioVolume:   0.25,
-		ShowHiddenFiles:   false,
+		VideoFullscreen:      false,
+		VideoMonitorIndex:    0,
+		VideoFilter:          "Pixel Perfect",
+		MapAxisToDPad:        false,
+		RumbleEnabled:        true,
+		RumbleStrength:       []float32{1, 1, 1, 1, 1},
+		AudioVolume:          0.5,
+		MenuAudioVolume:      0.25,
+		ShowHiddenFiles:      false,
+		RewindEnabled:        false,
+		RewindBufferSize:     64,
+		RewindGranularity:    1,
+		RunAheadFrames:       0,
+		SaveRAMBackups:       5,
+		SavestateCompression: "None",
+		SavestateAuto:        false,
+		SavestateResume:      "Ask",
+		NetplayPort:          55435,
 		CoreForPlaylist: map[string]string{
 			"Atari - 2600":                                   "stella2014_libretro",
 			"Atari - 5200":                                   "atari800_libretro",
@@ -60,17 +71,21 @@ func defaultSettings() Settings {
 			"SNK - Neo Geo Pocket":                           "mednafen_ngp_libretro",
 			"Sony - PlayStation":                             playstationCore,
 		},
-		Language:             "en",
-		FileDirectory:        usr.HomeDir,
-		CoresDirectory:       "./cores",
-		AssetsDirectory:      "./assets",
-		DatabaseDirectory:    "./database",
-		SavestatesDirectory:  filepath.Join(xdg.DataHome, "ludo", "savestates"),
-		SavefilesDirectory:   filepath.Join(xdg.DataHome, "ludo", "savefiles"),
-		ScreenshotsDirectory: filepath.Join(xdg.DataHome, "ludo", "screenshots"),
-		SystemDirectory:      filepath.Join(xdg.DataHome, "ludo", "system"),
-		PlaylistsDirectory:   filepath.Join(xdg.DataHome, "ludo", "playlists"),
-		ThumbnailsDirectory:  filepath.Join(xdg.DataHome, "ludo", "thumbnails"),
-		LanguagesDirectory:   "./i18n",
+		Language:              "en",
+		FileDirectory:         usr.HomeDir,
+		CoresDirectory:        "./cores",
+		InfoDirectory:         "./info",
+		AssetsDirectory:       "./assets",
+		DatabaseDirectory:     "./database",
+		SavestatesDirectory:   filepath.Join(xdg.DataHome, "ludo", "savestates"),
+		SavefilesDirectory:    filepath.Join(xdg.DataHome, "ludo", "savefiles"),
+		ScreenshotsDirectory:  filepath.Join(xdg.DataHome, "ludo", "screenshots"),
+		SystemDirectory:       filepath.Join(xdg.DataHome, "ludo", "system"),
+		PlaylistsDirectory:    filepath.Join(xdg.DataHome, "ludo", "playlists"),
+		ThumbnailsDirectory:   filepath.Join(xdg.DataHome, "ludo", "thumbnails"),
+		LanguagesDirectory:    "./i18n",
+		MoviesDirectory:       filepath.Join(xdg.DataHome, "ludo", "movies"),
+		CheatsDirectory:       filepath.Join(xdg.DataHome, "ludo", "cheats"),
+		AchievementsDirectory: filepath.Join(xdg.DataHome, "ludo", "achievements"),
 	}
 }
@@ -35,23 +35,43 @@ type Settings struct {
 	MenuAudioVolume float32 `toml:"menu_audio_volume" label:"Menu Audio Volume" fmt:"%.1f" widget:"range"`
 	ShowHiddenFiles bool    `toml:"menu_showhiddenfiles" label:"Show Hidden Files" fmt:"%t" widget:"switch"`
 
-	MapAxisToDPad bool `toml:"input_map_axis_to_dpad" label:"Map Sticks To DPad" fmt:"%t" widget:"switch"`
+	MapAxisToDPad  bool      `toml:"input_map_axis_to_dpad" label:"Map Sticks To DPad" fmt:"%t" widget:"switch"`
+	RumbleEnabled  bool      `toml:"input_rumble_enabled" label:"Rumble" fmt:"%t" widget:"switch"`
+	RumbleStrength []float32 `hide:"always" toml:"input_rumble_strength"`
+
+	RewindEnabled     bool `toml:"rewind_enabled" label:"Rewind" fmt:"%t" widget:"switch"`
+	RewindBufferSize  int  `toml:"rewind_buffer_size" label:"Rewind Buffer Size" fmt:"%d MB"`
+	RewindGranularity int  `toml:"rewind_granularity" label:"Rewind Granularity" fmt:"%d"`
+
+	RunAheadFrames int `toml:"run_ahead_frames" label:"Run-Ahead Frames" fmt:"%d"`
+
+	SaveRAMBackups       int    `toml:"savefiles_backups" label:"Save RAM Backups" fmt:"%d"`
+	SavestateCompression string `toml:"savestate_compression" label:"Savestate Compression" fmt:"<%s>"`
+	SavestateAuto        bool   `toml:"savestate_auto" label:"Auto Savestate" fmt:"%t" widget:"switch"`
+	SavestateResume      string `toml:"savestate_resume" label:"Resume From Auto Savestate" fmt:"<%s>"`
+
+	NetplayPort int    `hide:"always" toml:"netplay_port"`
+	NetplayHost string `hide:"always" toml:"netplay_host"`
 
 	CoreForPlaylist map[string]string `hide:"always" toml:"core_for_playlist"`
 
 	Language string `toml:"language" fmt:"<%s>"`
 
-	FileDirectory        string `hide:"ludos" toml:"files_dir" label:"Files Directory" fmt:"%s" widget:"dir"`
-	CoresDirectory       string `hide:"ludos" toml:"cores_dir" label:"Cores Directory" fmt:"%s" widget:"dir"`
-	AssetsDirectory      string `hide:"ludos" toml:"assets_dir" label:"Assets Directory" fmt:"%s" widget:"dir"`
-	DatabaseDirectory    string `hide:"ludos" toml:"database_dir" label:"Database Directory" fmt:"%s" widget:"dir"`
-	SavestatesDirectory  string `hide:"ludos" toml:"savestates_dir" label:"Savestates Directory" fmt:"%s" widget:"dir"`
-	SavefilesDirectory   string `hide:"ludos" toml:"savefiles_dir" label:"Savefiles Directory" fmt:"%s" widget:"dir"`
-	ScreenshotsDirectory string `hide:"ludos" toml:"screenshots_dir" label:"Screenshots Directory" fmt:"%s" widget:"dir"`
-	SystemDirectory      string `hide:"ludos" toml:"system_dir" label:"System Directory" fmt:"%s" widget:"dir"`
-	PlaylistsDirectory   string `hide:"ludos" toml:"playlists_dir" label:"Playlists Directory" fmt:"%s" widget:"dir"`
-	ThumbnailsDirectory  string `hide:"ludos" toml:"thumbnail_dir" label:"Thumbnails Directory" fmt:"%s" widget:"dir"`
-	LanguagesDirectory   string `hide:"ludos" toml:"languages_dir" label:"Languages Directory" fmt:"%s" widget:"dir"`
+	FileDirectory         string `hide:"ludos" toml:"files_dir" label:"Files Directory" fmt:"%s" widget:"dir"`
+	CoresDirectory        string `hide:"ludos" toml:"cores_dir" label:"Cores Directory" fmt:"%s" widget:"dir"`
+	InfoDirectory         string `hide:"ludos" toml:"info_dir" label:"Core Info Directory" fmt:"%s" widget:"dir"`
+	AssetsDirectory       string `hide:"ludos" toml:"assets_dir" label:"Assets Directory" fmt:"%s" widget:"dir"`
+	DatabaseDirectory     string `hide:"ludos" toml:"database_dir" label:"Database Directory" fmt:"%s" widget:"dir"`
+	SavestatesDirectory   string `hide:"ludos" toml:"savestates_dir" label:"Savestates Directory" fmt:"%s" widget:"dir"`
+	SavefilesDirectory    string `hide:"ludos" toml:"savefiles_dir" label:"Savefiles Directory" fmt:"%s" widget:"dir"`
+	ScreenshotsDirectory  string `hide:"ludos" toml:"screenshots_dir" label:"Screenshots Directory" fmt:"%s" widget:"dir"`
+	SystemDirectory       string `hide:"ludos" toml:"system_dir" label:"System Directory" fmt:"%s" widget:"dir"`
+	PlaylistsDirectory    string `hide:"ludos" toml:"playlists_dir" label:"Playlists Directory" fmt:"%s" widget:"dir"`
+	ThumbnailsDirectory   string `hide:"ludos" toml:"thumbnail_dir" label:"Thumbnails Directory" fmt:"%s" widget:"dir"`
+	LanguagesDirectory    string `hide:"ludos" toml:"languages_dir" label:"Languages Directory" fmt:"%s" widget:"dir"`
+	MoviesDirectory       string `hide:"ludos" toml:"movies_dir" label:"Movies Directory" fmt:"%s" widget:"dir"`
+	CheatsDirectory       string `hide:"ludos" toml:"cheats_dir" label:"Cheats Directory" fmt:"%s" widget:"dir"`
+	AchievementsDirectory string `hide:"ludos" toml:"achievements_dir" label:"Achievements Directory" fmt:"%s" widget:"dir"`
 
 	SSHService       bool `hide:"app" toml:"ssh_service" label:"SSH" widget:"switch" service:"sshd.service" path:"/storage/.cache/services/sshd.conf"`
 	SambaService     bool `hide:"app" toml:"samba_service" label:"Samba" widget:"switch" service:"smbd.service" path:"/storage/.cache/services/samba.conf"`
@@ -177,6 +197,26 @@ func SettingLabel(toml_name string) string {
 		return l10n.T9(&i18n.Message{ID: "ShowHiddenFiles", Other: "Show Hidden Files"})
 	case "input_map_axis_to_dpad":
 		return l10n.T9(&i18n.Message{ID: "MapSticksToDPad", Other: "Map Sticks To DPad"})
+	case "input_rumble_enabled":
+		return l10n.T9(&i18n.Message{ID: "RumbleEnabled", Other: "Rumble"})
+	case "rewind_enabled":
+		return l10n.T9(&i18n.Message{ID: "Rewind", Other: "Rewind"})
+	case "rewind_buffer_size":
+		return l10n.T9(&i18n.Message{ID: "RewindBufferSize", Other: "Rewind Buffer Size"})
+	case "rewind_granularity":
+		return l10n.T9(&i18n.Message{ID: "RewindGranularity", Other: "Rewind Granularity"})
+	case "run_ahead_frames":
+		return l10n.T9(&i18n.Message{ID: "RunAheadFrames", Other: "Run-Ahead Frames"})
+	case "savefiles_backups":
+		return l10n.T9(&i18n.Message{ID: "SaveRAMBackups", Other: "Save RAM Backups"})
+	case "savestate_compression":
+		return l10n.T9(&i18n.Message{ID: "SavestateCompression", Other: "Savestate Compression"})
+	case "savestate_auto":
+		return l10n.T9(&i18n.Message{ID: "AutoSavestate", Other: "Auto Savestate"})
+	case "savestate_resume":
+		return l10n.T9(&i18n.Message{ID: "SavestateResume", Other: "Resume From Auto Savestate"})
+	case "netplay_port", "netplay_host":
+		return ""
 	case "core_for_playlist":
 		return ""
 	case "language":
@@ -185,6 +225,8 @@ func SettingLabel(toml_name string) string {
 		return l10n.T9(&i18n.Message{ID: "FilesDirectory", Other: "Files Directory"})
 	case "cores_dir":
 		return l10n.T9(&i18n.Message{ID: "CoresDirectory", Other: "Cores Directory"})
+	case "info_dir":
+		return l10n.T9(&i18n.Message{ID: "InfoDirectory", Other: "Core Info Directory"})
 	case "assets_dir":
 		return l10n.T9(&i18n.Message{ID: "AssetsDirectory", Other: "Assets Directory"})
 	case "database_dir":
@@ -203,6 +245,12 @@ func SettingLabel(toml_name string) string {
 		return l10n.T9(&i18n.Message{ID: "ThumbnailsDirectory", Other: "Thumbnails Directory"})
 	case "languages_dir":
 		return l10n.T9(&i18n.Message{ID: "LanguagesDirectory", Other: "Languages Directory"})
+	case "movies_dir":
+		return l10n.T9(&i18n.Message{ID: "MoviesDirectory", Other: "Movies Directory"})
+	case "cheats_dir":
+		return l10n.T9(&i18n.Message{ID: "CheatsDirectory", Other: "Cheats Directory"})
+	case "achievements_dir":
+		return l10n.T9(&i18n.Message{ID: "AchievementsDirectory", Other: "Achievements Directory"})
 	case "ssh_service":
 		return l10n.T9(&i18n.Message{ID: "SSHService", Other: "SSH"})
 	case "samba_service":
@@ -3,6 +3,8 @@
 package state
 
 import (
+	"time"
+
 	"github.com/libretro/ludo/dat"
 	"github.com/libretro/ludo/libretro"
 )
@@ -31,8 +33,22 @@ var DB dat.DB
 // LudOS is whether run Ludo as a unix desktop environment
 var LudOS bool
 
+// Headless runs the core without a window, video, audio or input
+var Headless bool
+
 // FastForward will run the core as fast as possible
 var FastForward bool
 
+// GameFocus sends the whole keyboard to the core and disables the keyboard
+// hotkeys, for computer cores
+var GameFocus bool
+
+// Rewinding is true while the core is stepping back in time
+var Rewinding bool
+
 // SystemName (playlist name) is the name of the current system(platform)
 var SystemName string
+
+// PlayTime is the time spent playing the current game, outside of the menu.
+// Loading a savestate restores the play time it was saved with.
+var PlayTime time.Duration
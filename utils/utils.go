@@ -4,7 +4,9 @@ package utils
 import (
 	"bytes"
 	"fmt"
+	"hash/crc32"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
@@ -110,3 +112,53 @@ func LinesInFile(r io.Reader) (int, error) {
 		}
 	}
 }
+
+// CRC32File computes the CRC32 checksum of a file
+func CRC32File(path string) (uint32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	h := crc32.NewIEEE()
+	if _, err := io.Copy(h, f); err != nil {
+		return 0, err
+	}
+	return h.Sum32(), nil
+}
+
+// WriteFileAtomic writes a file through a temporary file synced and renamed
+// over it, so a crash in the middle of the write can't leave a truncated file
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	fd, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := fd.Write(data); err != nil {
+		fd.Close()
+		os.Remove(fd.Name())
+		return err
+	}
+
+	// TempFile creates the file with the 0600 mode
+	if err := fd.Chmod(perm); err != nil {
+		fd.Close()
+		os.Remove(fd.Name())
+		return err
+	}
+
+	if err := fd.Sync(); err != nil {
+		fd.Close()
+		os.Remove(fd.Name())
+		return err
+	}
+
+	if err := fd.Close(); err != nil {
+		os.Remove(fd.Name())
+		return err
+	}
+
+	return os.Rename(fd.Name(), path)
+}
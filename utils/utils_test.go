@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func Test_WriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Super Metroid.srm")
+
+	if err := ioutil.WriteFile(path, []byte("old save"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(path, []byte("new save"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := ioutil.ReadFile(path)
+	if string(got) != "new save" {
+		t.Errorf("got = %s, want %s", got, "new save")
+	}
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("got = %v files, want %v", len(files), 1)
+	}
+	if fi, _ := os.Stat(path); runtime.GOOS != "windows" && fi.Mode().Perm() != 0644 {
+		t.Errorf("got = %v, want %v", fi.Mode().Perm(), os.FileMode(0644))
+	}
+}
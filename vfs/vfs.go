@@ -0,0 +1,444 @@
+// Package vfs serves the files opened by the cores through the libretro VFS
+// interface. Paths like game.zip#track01.bin point to a file inside an
+// archive, which is read in memory instead of being extracted to the disk.
+// Big files, like the tracks of CD games, are extracted to a temporary file
+// instead. 7z archives are not supported, archiver can't read them.
+package vfs
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zip"
+	"github.com/libretro/ludo/libretro"
+	"github.com/mholt/archiver/v3"
+	"github.com/nwaples/rardecode"
+)
+
+// Separator separates the path of an archive from the file inside it
+const Separator = "#"
+
+// errReadOnly is returned when trying to modify a file inside an archive
+var errReadOnly = errors.New("files inside archives are read only")
+
+// maxInMemory is the size above which the members opened by the cores are
+// extracted to the disk instead of being read in memory
+var maxInMemory int64 = 64 << 20
+
+// Member is a file stored in an archive
+type Member struct {
+	Name string
+	Size int64
+}
+
+// archiveMembers is the member list of an archive, and the state of the archive
+// when it was listed
+type archiveMembers struct {
+	modTime time.Time
+	size    int64
+	members []Member
+}
+
+// cache keeps the member lists of the archives, so the archives are only
+// walked again when they change, and the members extracted to the disk
+var cache = struct {
+	sync.Mutex
+	members   map[string]archiveMembers
+	dir       string            // temporary directory of the extracted members
+	extracted map[string]string // extracted members, by path in the archive
+}{
+	members:   map[string]archiveMembers{},
+	extracted: map[string]string{},
+}
+
+// ArchiveExtensions lists the extensions of the archives we can read members
+// from
+var ArchiveExtensions = []string{
+	".zip", ".rar", ".tar",
+	".tar.gz", ".tgz", ".tar.bz2", ".tbz2", ".tar.xz", ".txz",
+	".tar.zst", ".tar.lz4", ".tlz4", ".tar.sz", ".tsz", ".tar.br", ".tbr",
+}
+
+// walker returns the walker of an archive, chosen by extension regardless of
+// its case
+func walker(archive string) (archiver.Walker, error) {
+	w, err := archiver.ByExtension(strings.ToLower(archive))
+	if err != nil {
+		return nil, err
+	}
+	wk, ok := w.(archiver.Walker)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an archive", archive)
+	}
+	return wk, nil
+}
+
+// IsArchive tells if a file is an archive we can read members from. 7z
+// archives are not, they have to be opened by the cores themselves.
+func IsArchive(path string) bool {
+	_, err := walker(path)
+	return err == nil
+}
+
+// Unarchive extracts all the files of an archive to a directory. Compressed
+// single files like game.sfc.zst are decompressed to dst/game.sfc.
+func Unarchive(archive, dst string) error {
+	u, err := archiver.ByExtension(strings.ToLower(archive))
+	if err != nil {
+		return err
+	}
+	switch a := u.(type) {
+	case archiver.Unarchiver:
+		return a.Unarchive(archive, dst)
+	case archiver.Decompressor:
+		return decompress(a, archive, dst)
+	}
+	return fmt.Errorf("%s is not an archive", archive)
+}
+
+// decompress decompresses a single compressed file to a directory, under its
+// name without the compression extension
+func decompress(d archiver.Decompressor, path, dst string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	name := filepath.Base(path)
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	out, err := os.Create(filepath.Join(dst, name))
+	if err != nil {
+		return err
+	}
+	if err := d.Decompress(in, out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// Split splits a path pointing inside an archive into the path of the archive
+// and the name of the member. ok is false for regular paths.
+func Split(path string) (archive, member string, ok bool) {
+	i := strings.LastIndex(path, Separator)
+	if i < 0 {
+		return "", "", false
+	}
+	archive, member = path[:i], path[i+1:]
+	if member == "" || !IsArchive(archive) {
+		return "", "", false
+	}
+	if fi, err := os.Stat(archive); err != nil || fi.IsDir() {
+		return "", "", false
+	}
+	return archive, member, true
+}
+
+// Join returns the path of a member inside an archive
+func Join(archive, member string) string {
+	return archive + Separator + member
+}
+
+// memberName returns the full name of a file in an archive, including its
+// directories, as archiver.File only gives the base name
+func memberName(f archiver.File) string {
+	switch h := f.Header.(type) {
+	case zip.FileHeader:
+		return h.Name
+	case *tar.Header:
+		return h.Name
+	case *rardecode.FileHeader:
+		return h.Name
+	}
+	return f.Name()
+}
+
+// Members lists the files stored in an archive, in the archive order
+func Members(archive string) ([]Member, error) {
+	fi, err := os.Stat(archive)
+	if err != nil {
+		return nil, err
+	}
+	cache.Lock()
+	c, ok := cache.members[archive]
+	cache.Unlock()
+	if ok && c.modTime.Equal(fi.ModTime()) && c.size == fi.Size() {
+		return c.members, nil
+	}
+
+	w, err := walker(archive)
+	if err != nil {
+		return nil, err
+	}
+	var members []Member
+	err = w.Walk(archive, func(f archiver.File) error {
+		if !f.IsDir() {
+			members = append(members, Member{Name: memberName(f), Size: f.Size()})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cache.Lock()
+	cache.members[archive] = archiveMembers{fi.ModTime(), fi.Size(), members}
+	cache.Unlock()
+	return members, nil
+}
+
+// findMember returns a file stored in an archive, op names the operation in
+// the error returned when the file doesn't exist
+func findMember(archive, member, op string) (Member, error) {
+	members, err := Members(archive)
+	if err != nil {
+		return Member{}, err
+	}
+	for _, m := range members {
+		if m.Name == member {
+			return m, nil
+		}
+	}
+	return Member{}, &os.PathError{Op: op, Path: Join(archive, member), Err: os.ErrNotExist}
+}
+
+// walkMember calls fn with a file stored in an archive
+func walkMember(archive, member string, fn func(f archiver.File) error) error {
+	w, err := walker(archive)
+	if err != nil {
+		return err
+	}
+	found := false
+	err = w.Walk(archive, func(f archiver.File) error {
+		if f.IsDir() || memberName(f) != member {
+			return nil
+		}
+		found = true
+		if err := fn(f); err != nil {
+			return err
+		}
+		return archiver.ErrStopWalk
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return &os.PathError{Op: "open", Path: Join(archive, member), Err: os.ErrNotExist}
+	}
+	return nil
+}
+
+// readMember reads a file stored in an archive
+func readMember(archive, member string) ([]byte, error) {
+	if _, err := findMember(archive, member, "open"); err != nil {
+		return nil, err
+	}
+	var data []byte
+	err := walkMember(archive, member, func(f archiver.File) error {
+		b, err := ioutil.ReadAll(f)
+		data = b
+		return err
+	})
+	return data, err
+}
+
+// extractMember extracts a file stored in an archive to a temporary file, once,
+// and returns its path
+func extractMember(archive, member string) (string, error) {
+	cache.Lock()
+	defer cache.Unlock()
+
+	path := Join(archive, member)
+	if p, ok := cache.extracted[path]; ok {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	if cache.dir == "" {
+		dir, err := ioutil.TempDir("", "ludo-vfs-")
+		if err != nil {
+			return "", err
+		}
+		cache.dir = dir
+	}
+	dst, err := ioutil.TempFile(cache.dir, "*"+filepath.Ext(member))
+	if err != nil {
+		return "", err
+	}
+	err = walkMember(archive, member, func(f archiver.File) error {
+		_, err := io.Copy(dst, f)
+		return err
+	})
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(dst.Name())
+		return "", err
+	}
+	cache.extracted[path] = dst.Name()
+	return dst.Name(), nil
+}
+
+// Cleanup removes the members extracted to the disk
+func Cleanup() {
+	cache.Lock()
+	defer cache.Unlock()
+	if cache.dir != "" {
+		os.RemoveAll(cache.dir)
+	}
+	cache.dir = ""
+	cache.extracted = map[string]string{}
+}
+
+// ReadFile reads a whole file, which can be inside an archive
+func ReadFile(path string) ([]byte, error) {
+	if archive, member, ok := Split(path); ok {
+		return readMember(archive, member)
+	}
+	return ioutil.ReadFile(path)
+}
+
+// memberFile is a file inside an archive, read in memory
+type memberFile struct {
+	*bytes.Reader
+}
+
+func (f memberFile) Write([]byte) (int, error) { return 0, errReadOnly }
+func (f memberFile) Close() error              { return nil }
+func (f memberFile) Size() (int64, error)      { return f.Reader.Size(), nil }
+func (f memberFile) Truncate(int64) error      { return errReadOnly }
+func (f memberFile) Sync() error               { return nil }
+
+// diskFile is a regular file
+type diskFile struct {
+	*os.File
+}
+
+func (f diskFile) Size() (int64, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+// FS is the file system handed to the cores through the VFS interface
+type FS struct{}
+
+// Open opens a file with a libretro access mode
+func (FS) Open(path string, mode uint32) (libretro.VFSFile, error) {
+	if archive, member, ok := Split(path); ok {
+		if mode&libretro.VFSFileAccessWrite != 0 {
+			return nil, errReadOnly
+		}
+		m, err := findMember(archive, member, "open")
+		if err != nil {
+			return nil, err
+		}
+		if m.Size > maxInMemory {
+			path, err := extractMember(archive, member)
+			if err != nil {
+				return nil, err
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			return diskFile{f}, nil
+		}
+		data, err := readMember(archive, member)
+		if err != nil {
+			return nil, err
+		}
+		return memberFile{bytes.NewReader(data)}, nil
+	}
+
+	flag := os.O_RDONLY
+	switch {
+	case mode&libretro.VFSFileAccessReadWrite == libretro.VFSFileAccessReadWrite:
+		flag = os.O_RDWR | os.O_CREATE
+	case mode&libretro.VFSFileAccessWrite != 0:
+		flag = os.O_WRONLY | os.O_CREATE
+	}
+	if mode&libretro.VFSFileAccessWrite != 0 && mode&libretro.VFSFileAccessUpdateExisting == 0 {
+		flag |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flag, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return diskFile{f}, nil
+}
+
+// Remove deletes a file, archives members can't be removed
+func (FS) Remove(path string) error {
+	if _, _, ok := Split(path); ok {
+		return errReadOnly
+	}
+	return os.Remove(path)
+}
+
+// Rename moves a file, archives members can't be moved
+func (FS) Rename(oldPath, newPath string) error {
+	_, _, ok1 := Split(oldPath)
+	_, _, ok2 := Split(newPath)
+	if ok1 || ok2 {
+		return errReadOnly
+	}
+	return os.Rename(oldPath, newPath)
+}
+
+// Stat returns the size of a file and tells if it is a directory
+func (FS) Stat(path string) (int64, bool, error) {
+	if archive, member, ok := Split(path); ok {
+		m, err := findMember(archive, member, "stat")
+		if err != nil {
+			return 0, false, err
+		}
+		return m.Size, false, nil
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, false, err
+	}
+	return fi.Size(), fi.IsDir(), nil
+}
+
+// Mkdir creates a directory, and returns an os.ErrExist error if it exists
+func (FS) Mkdir(dir string) error {
+	return os.Mkdir(dir, os.ModePerm)
+}
+
+// ReadDir lists a directory
+func (FS) ReadDir(dir string, includeHidden bool) ([]libretro.VFSDirEntry, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var entries []libretro.VFSDirEntry
+	for _, f := range files {
+		if !includeHidden && strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		entries = append(entries, libretro.VFSDirEntry{Name: f.Name(), IsDir: f.IsDir()})
+	}
+	return entries, nil
+}
+
+// Ensure FS and the files satisfy the libretro interfaces
+var (
+	_ libretro.FileSystem = FS{}
+	_ libretro.VFSFile    = memberFile{}
+	_ libretro.VFSFile    = diskFile{}
+)
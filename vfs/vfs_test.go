@@ -0,0 +1,154 @@
+package vfs
+
+import (
+	"archive/zip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libretro/ludo/libretro"
+)
+
+func makeZip(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "game.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := zip.NewWriter(f)
+	for name, content := range map[string]string{
+		"game.cue":       "FILE \"track01.bin\" BINARY",
+		"cd/track01.bin": "0123456789",
+	} {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func Test_Split(t *testing.T) {
+	archive := makeZip(t)
+
+	if a, m, ok := Split(Join(archive, "cd/track01.bin")); !ok || a != archive || m != "cd/track01.bin" {
+		t.Errorf("got = %v, %v, %v", a, m, ok)
+	}
+	if _, _, ok := Split(archive); ok {
+		t.Errorf("an archive path should not be split")
+	}
+	if _, _, ok := Split(filepath.Join(t.TempDir(), "Sonic #1.md")); ok {
+		t.Errorf("a regular path with a # should not be split")
+	}
+}
+
+func Test_Members(t *testing.T) {
+	archive := makeZip(t)
+	if members, err := Members(archive); err != nil || len(members) != 2 {
+		t.Fatalf("got = %v, %v", members, err)
+	}
+
+	t.Run("Lists the archive again when it changes", func(t *testing.T) {
+		f, _ := os.Create(archive)
+		w := zip.NewWriter(f)
+		w.Create("game.sfc")
+		w.Close()
+		f.Close()
+		os.Chtimes(archive, time.Now().Add(time.Hour), time.Now().Add(time.Hour))
+
+		members, err := Members(archive)
+		if err != nil || len(members) != 1 || members[0].Name != "game.sfc" {
+			t.Errorf("got = %v, %v", members, err)
+		}
+	})
+}
+
+func Test_Open(t *testing.T) {
+	archive := makeZip(t)
+	var fs FS
+
+	t.Run("Reads and seeks a file inside an archive", func(t *testing.T) {
+		f, err := fs.Open(Join(archive, "cd/track01.bin"), libretro.VFSFileAccessRead)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if size, _ := f.Size(); size != 10 {
+			t.Errorf("got = %v, want %v", size, 10)
+		}
+		f.Seek(-4, io.SeekEnd)
+		b, _ := ioutil.ReadAll(f)
+		if string(b) != "6789" {
+			t.Errorf("got = %v, want %v", string(b), "6789")
+		}
+	})
+
+	t.Run("Refuses to write inside an archive", func(t *testing.T) {
+		if _, err := fs.Open(Join(archive, "game.cue"), libretro.VFSFileAccessReadWrite); err == nil {
+			t.Errorf("expected an error")
+		}
+	})
+
+	t.Run("Returns an error for a missing member", func(t *testing.T) {
+		if _, err := fs.Open(Join(archive, "track02.bin"), libretro.VFSFileAccessRead); !os.IsNotExist(err) {
+			t.Errorf("got = %v, want a not exist error", err)
+		}
+	})
+
+	t.Run("Stats a file inside an archive", func(t *testing.T) {
+		size, isDir, err := fs.Stat(Join(archive, "cd/track01.bin"))
+		if err != nil || size != 10 || isDir {
+			t.Errorf("got = %v, %v, %v", size, isDir, err)
+		}
+	})
+
+	t.Run("Extracts big files to the disk", func(t *testing.T) {
+		saved := maxInMemory
+		defer func() { maxInMemory = saved; Cleanup() }()
+		maxInMemory = 4
+
+		f, err := fs.Open(Join(archive, "cd/track01.bin"), libretro.VFSFileAccessRead)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, ok := f.(diskFile); !ok {
+			t.Errorf("got = %T, want a file on the disk", f)
+		}
+		b, _ := ioutil.ReadAll(f)
+		if string(b) != "0123456789" {
+			t.Errorf("got = %v, want %v", string(b), "0123456789")
+		}
+	})
+
+	t.Run("Writes regular files", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "game.srm")
+		f, err := fs.Open(path, libretro.VFSFileAccessWrite)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte("save"))
+		f.Close()
+		b, _ := ReadFile(path)
+		if string(b) != "save" {
+			t.Errorf("got = %v, want %v", string(b), "save")
+		}
+	})
+}
+
+func Test_ArchiveExtensions(t *testing.T) {
+	for _, ext := range ArchiveExtensions {
+		if !IsArchive("game"+ext) || !IsArchive("GAME"+strings.ToUpper(ext)) {
+			t.Errorf("%s is not an archive", ext)
+		}
+	}
+}
@@ -4,6 +4,7 @@
 package video
 
 import (
+	"hash/crc32"
 	"log"
 	"path/filepath"
 	"unsafe"
@@ -302,6 +303,19 @@ func (video *Video) ResetRot() {
 // coreRatioViewport configures the vertex array to display the game at the center of the window
 // while preserving the original ascpect ratio of the game or core
 func (video *Video) coreRatioViewport(fbWidth int, fbHeight int) (x, y, w, h float32) {
+	x, y, w, h = video.Viewport(fbWidth, fbHeight)
+
+	va := video.vertexArray(x, y, w, h, 1.0)
+	va = rotateUV(va, video.rot)
+	gl.BindBuffer(gl.ARRAY_BUFFER, video.vbo)
+	gl.BufferData(gl.ARRAY_BUFFER, len(va)*4, gl.Ptr(va), gl.STATIC_DRAW)
+
+	return
+}
+
+// Viewport returns the area of the framebuffer where the game is displayed,
+// centered and preserving the aspect ratio of the game or core
+func (video *Video) Viewport(fbWidth int, fbHeight int) (x, y, w, h float32) {
 	// Scale the content to fit in the viewport.
 	fbw := float32(fbWidth)
 	fbh := float32(fbHeight)
@@ -323,11 +337,6 @@ func (video *Video) coreRatioViewport(fbWidth int, fbHeight int) (x, y, w, h flo
 	x = (fbw - w) / 2
 	y = (fbh - h) / 2
 
-	va := video.vertexArray(x, y, w, h, 1.0)
-	va = rotateUV(va, video.rot)
-	gl.BindBuffer(gl.ARRAY_BUFFER, video.vbo)
-	gl.BufferData(gl.ARRAY_BUFFER, len(va)*4, gl.Ptr(va), gl.STATIC_DRAW)
-
 	return
 }
 
@@ -378,6 +387,16 @@ func (video *Video) Refresh(data unsafe.Pointer, width int32, height int32, pitc
 	video.data = data // maybe need a full copy
 }
 
+// FrameCRC returns a checksum of the last frame sent by the core, or 0 if the
+// core didn't send any. It allows to compare the output of two runs without
+// rendering them.
+func (video *Video) FrameCRC() uint32 {
+	if video.data == nil || video.pitch <= 0 || video.height <= 0 {
+		return 0
+	}
+	return crc32.ChecksumIEEE(unsafe.Slice((*byte)(video.data), int(video.pitch)*int(video.height)))
+}
+
 func (video *Video) uploadTexture() {
 	if !video.needUpload || video.data == nil {
 		return